@@ -45,6 +45,9 @@ func runServer(ctx context.Context) {
 	}
 
 	logger.Info("Start server", zap.Any("Config", conf))
+	if err = conf.JWT.LoadKeys(); err != nil {
+		logger.Fatal("cannot load jwt keys", zap.Error(err))
+	}
 
 	var (
 		db            *sqlx.DB
@@ -63,11 +66,12 @@ func runServer(ctx context.Context) {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://" + conf.ServerAddress,
 		AllowCredentials: true,
+		ExposeHeaders:    constant.HeaderRefreshToken + "," + constant.HeaderRefreshExpiresAt,
 		//MaxAge:           defaultCorsMaxAge,
 	}))
 
 	r := repository.NewRepository(db)
-	s := service.NewService(r, &conf.JWT, logger)
+	s := service.NewService(r, conf, logger)
 	handler.NewHandler(app, s, conf, logger).Handler()
 
 	graceShutdown.Add("APP", func(ctx context.Context) (err error) {
@@ -77,6 +81,12 @@ func runServer(ctx context.Context) {
 		return
 	})
 
+	graceShutdown.Add("STREAM", s.Stream.Close)
+
+	go s.Webhook.Run(ctx)
+	go s.Monitor.RunWatchdog(ctx)
+	go s.Monitor.RunScheduler(ctx)
+
 	graceShutdown.Add("DB", func(ctx context.Context) (err error) {
 		if err = db.Close(); err == nil {
 			logger.Info("Db Closed")
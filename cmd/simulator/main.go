@@ -29,6 +29,9 @@ func main() {
 		panic(err)
 	}
 	logger.Info("Start server", zap.Any("Config", conf))
+	if err = conf.JWT.LoadKeys(); err != nil {
+		logger.Fatal("cannot load jwt keys", zap.Error(err))
+	}
 
 	if conf.SleepBeforeRun != 0 {
 		logger.Info("Sleep ", zap.Any("sleep seconds", conf.SleepBeforeRun))
@@ -71,10 +74,10 @@ func main() {
 			time.Sleep(100 * time.Millisecond)
 			logger.Info("Start simulation for", zap.Any("vessel", vessel.String()))
 
-			vesselClaims := appDomain.NewClaimVessels(&conf.JWT, vessel.ID, vessel.Name)
-			jwtStr, er := vesselClaims.Token()
+			jwtStr, er := s.IssueVesselToken(ctx, vessel.ID)
 			if er != nil {
-				logger.Fatal("Build vessel jwt", zap.Error(err))
+				logger.Error("Issue vessel jwt", zap.Error(er), zap.Any("vessel", vessel.String()))
+				return
 			}
 			vesselCtx := context.WithValue(ctx, constant.CtxValueKeyJWTVessel, jwtStr)
 			s.SimulateVessel(vesselCtx, vessel)
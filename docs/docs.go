@@ -15,68 +15,34 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/chart/vessels": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "которые пересекали указанные морские карты в заданный временной промежуток.",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "JSON Web Key Set для проверки токенов: ключ выбирается по kid из заголовка токена. Пустой, если токены подписываются общим секретом (HS512)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chart"
-                ],
-                "summary": "список судов",
-                "parameters": [
-                    {
-                        "description": "Входные параметры: идентификаторы карт, стартовая дата, конечная дата.",
-                        "name": "InputZones",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.InputZones"
-                        }
-                    }
+                    "User"
                 ],
+                "summary": "Публичные ключи токенов",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/config.JWKS"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "403": {
-                        "description": "Forbidden"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/chart/zones": {
-            "post": {
+        "/admin/vessels/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "которые пересекались заданными в запросе судами в заданный временной промежуток.",
+                "description": "удаляет судно и все зависимые данные (треки, журнал контроля, состояние мониторинга и т.д.) в одной транзакции.\nС export=true в ответе - архив данных, выгруженный перед удалением в той же транзакции.\nПравило оповещений только для этого судна удаляется. Сообщения подписок о судне удаляются,\nиз записей журнала изменений удаляются строки судна",
                 "consumes": [
                     "application/json"
                 ],
@@ -84,28 +50,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Chart"
+                    "Admin"
                 ],
-                "summary": "список морских карт",
+                "summary": "Полное удаление судна",
                 "parameters": [
                     {
-                        "description": "Входные параметры: идентификаторы судов, стартовая дата, конечная дата.",
-                        "name": "InputVesselsInterval",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.InputVesselsInterval"
-                        }
+                        "type": "integer",
+                        "description": "ID Судна",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "export",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "архив, если export=true, иначе Ok",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain.VesselArchive"
                         }
                     },
                     "400": {
@@ -117,20 +83,23 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
+        "/admin/vessels/{id}/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получение токена",
+                "description": "архив всего, что хранится о судне (в т.ч. удаленном): строки судна и зависимых таблиц - треки, журнал контроля,\nсостояние мониторинга, окна, оповещения, правила, списки, группы, токены, история названий,\nсообщения подписок с попытками доставки, записи журнала изменений",
                 "consumes": [
                     "application/json"
                 ],
@@ -138,25 +107,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Идентификация",
+                "summary": "Выгрузка данных судна",
                 "parameters": [
                     {
-                        "description": "Логин, пароль",
-                        "name": "UserAuth",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.LoginForm"
-                        }
+                        "type": "integer",
+                        "description": "ID Судна",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT token",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/domain.VesselArchive"
                         }
                     },
                     "400": {
@@ -168,20 +135,23 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/monitor": {
+        "/alerts": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "поставленных на мониторинг",
+                "description": "о входе, выходе судов из карт или нахождении в карте дольше заданного в правиле времени.\nПользователю с ограничением - только по судам его флота в его зонах",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,16 +159,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitor"
+                    "Alert"
+                ],
+                "summary": "Список оповещений",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "finish",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "groupIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "vesselIDs",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Список судов",
                 "responses": {
                     "200": {
-                        "description": "Ok",
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Vessel"
+                                "$ref": "#/definitions/domain.Alert"
                             }
                         }
                     },
@@ -216,12 +221,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "оператором из токена",
                 "consumes": [
                     "application/json"
                 ],
@@ -229,13 +235,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitor"
+                    "Alert"
                 ],
-                "summary": "Поставить судно на контроль",
+                "summary": "Подтверждение оповещений",
                 "parameters": [
                     {
-                        "description": "список ID Судов",
-                        "name": "VesselIDs",
+                        "description": "список ID оповещений",
+                        "name": "AlertIDs",
                         "in": "body",
                         "required": true,
                         "schema": {
@@ -266,8 +272,10 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/alerts/rules": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -280,32 +288,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitor"
+                    "Alert"
                 ],
-                "summary": "Снять судно с контроля",
-                "parameters": [
-                    {
-                        "description": "список ID Судов",
-                        "name": "VesselIDs",
-                        "in": "body",
-                        "required": true,
+                "summary": "Правила оповещений",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "integer"
+                                "$ref": "#/definitions/domain.AlertRule"
                             }
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Ok",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -317,16 +311,13 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/monitor/state": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "для выбранных судов, стоящих на мониторинге",
                 "consumes": [
                     "application/json"
                 ],
@@ -334,20 +325,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Monitor"
+                    "Alert"
                 ],
-                "summary": "Текущие данные",
+                "summary": "Изменение правила оповещения",
                 "parameters": [
                     {
-                        "description": "список ID Судов",
-                        "name": "VesselIDs",
+                        "description": "правило",
+                        "name": "AlertRule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/domain.AlertRule"
                         }
                     }
                 ],
@@ -355,10 +343,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Ok",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.VesselState"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -371,21 +356,20 @@ const docTemplate = `{
                         "description": "Forbidden"
                     },
                     "404": {
-                        "description": "no data yet"
+                        "description": "Not Found"
                     },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/track": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Событие event: enter - вход в карту, exit - выход, dwell - нахождение в карте дольше dwellMinutes.\nПустые vesselIDs, zoneNames - любое судно на мониторинге, любая карта",
                 "consumes": [
                     "application/json"
                 ],
@@ -393,35 +377,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Track"
+                    "Alert"
                 ],
-                "summary": "Запись трека судна",
+                "summary": "Добавление правила оповещения",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bearer: JWT claims must have: id key used as vesselID and role: 1",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "description": "[lon, lat]",
-                        "name": "Point",
+                        "description": "правило",
+                        "name": "AlertRule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "number"
-                            }
+                            "$ref": "#/definitions/domain.AlertRule"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Ok",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "type": "integer"
                         }
                     },
                     "400": {
@@ -437,10 +411,8 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/track/{id}": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -453,36 +425,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Track"
+                    "Alert"
                 ],
-                "summary": "Маршрут судна за указанный период",
+                "summary": "Удаление правил оповещения",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID Судна ",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "name": "finish",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "start",
-                        "in": "query"
+                        "description": "список ID правил",
+                        "name": "AlertRuleIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Ok",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/charts_analyser_internal_app_domain.Track"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -500,14 +464,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user": {
-            "put": {
+        "/audit": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Смена названия оператора, для не удаленных",
+                "description": "успешные POST/PUT/PATCH/DELETE запросы к /api, новые первыми: кто (userID, apiKeyID), запрос (method, path),\nтаблица target, ID ее строк targetIDs и строки до (before) и после (after) изменения, секреты не сохраняются.\nЗапрос, изменивший несколько таблиц, - запись на каждую, не изменивший ничего - запись без target.\nВход, обновление токена и выход записываются без target от имени пользователя, треки судов не записываются.\nФильтры: userIDs, apiKeyID, method, path - начало пути, target, targetID, период.\nСледующая страница - cursor=nextCursor с теми же фильтрами",
                 "consumes": [
                     "application/json"
                 ],
@@ -515,83 +479,87 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Audit"
                 ],
-                "summary": "Изменение оператора",
+                "summary": "Журнал изменений",
                 "parameters": [
                     {
-                        "description": "данные пользователя",
-                        "name": "UserData",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UserChange"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Ok",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "type": "integer",
+                        "name": "apiKeyID",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Unauthorized"
+                    {
+                        "type": "string",
+                        "name": "finish",
+                        "in": "query"
                     },
-                    "403": {
-                        "description": "Forbidden"
+                    {
+                        "maximum": 500,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found"
+                    {
+                        "enum": [
+                            "POST",
+                            "PUT",
+                            "PATCH",
+                            "DELETE"
+                        ],
+                        "type": "string",
+                        "name": "method",
+                        "in": "query"
                     },
-                    "409": {
-                        "description": "Conflict"
+                    {
+                        "maxLength": 250,
+                        "type": "string",
+                        "name": "path",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            },
-            "post": {
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "User"
-                ],
-                "summary": "Добавление оператора",
-                "parameters": [
+                        "type": "string",
+                        "name": "start",
+                        "in": "query"
+                    },
                     {
-                        "description": "данные пользователя",
-                        "name": "UserData",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UserChange"
-                        }
+                        "maxLength": 50,
+                        "type": "string",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 100,
+                        "type": "string",
+                        "name": "targetID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "userIDs",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "$ref": "#/definitions/domain.AuditPage"
                         }
                     },
                     "400": {
-                        "description": "Bad Request"
+                        "description": "ошибка валидации",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -599,20 +567,20 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "409": {
-                        "description": "Conflict"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/chart/vessels": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "которые пересекали указанные морские карты в заданный временной промежуток.",
                 "consumes": [
                     "application/json"
                 ],
@@ -620,28 +588,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Chart"
                 ],
-                "summary": "Удаление операторов",
+                "summary": "список судов",
                 "parameters": [
                     {
-                        "description": "список ID операторов",
-                        "name": "UserNames",
+                        "description": "Входные параметры: идентификаторы карт, стартовая дата, конечная дата.",
+                        "name": "InputZones",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/domain.InputZones"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ok",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
                         }
                     },
                     "400": {
@@ -657,13 +625,16 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/chart/zones": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "которые пересекались заданными в запросе судами (vesselIDs и суда групп groupIDs) в заданный временной промежуток.",
                 "consumes": [
                     "application/json"
                 ],
@@ -671,28 +642,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Chart"
                 ],
-                "summary": "Восстановление оператора",
+                "summary": "список морских карт",
                 "parameters": [
                     {
-                        "description": "список ID операторов",
-                        "name": "UserNames",
+                        "description": "Входные параметры: идентификаторы судов и групп, стартовая дата, конечная дата.",
+                        "name": "InputVesselsInterval",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/domain.InputVesselsInterval"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Ok",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
@@ -710,13 +681,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/vessels": {
+        "/credentials": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "выданные операторами, не отозванные и не истекшие. Фильтр по судам vesselIDs, groupIDs",
                 "consumes": [
                     "application/json"
                 ],
@@ -724,10 +696,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vessel"
+                    "Credential"
                 ],
-                "summary": "Информация о судах",
+                "summary": "Действующие токены судов",
                 "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "groupIDs",
+                        "in": "query"
+                    },
                     {
                         "type": "array",
                         "items": {
@@ -744,7 +725,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Vessel"
+                                "$ref": "#/definitions/domain.VesselCredential"
                             }
                         }
                     },
@@ -757,18 +738,21 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Смена названия судна, для не удаленных",
+                "description": "токен с идентификатором jti, по которому его можно отозвать. Выдал - оператор из токена,\ncomment - например, устройство. Токен возвращается только в ответе",
                 "consumes": [
                     "application/json"
                 ],
@@ -776,31 +760,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vessel"
+                    "Credential"
                 ],
-                "summary": "Изменение судна",
+                "summary": "Выдача токена судна",
                 "parameters": [
                     {
-                        "description": "список названий судов",
-                        "name": "VesselNames",
+                        "description": "vesselID и комментарий",
+                        "name": "VesselCredential",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Vessel"
-                            }
+                            "$ref": "#/definitions/domain.VesselCredential"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "успешно обновлённые суда",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Vessel"
-                            }
+                            "$ref": "#/definitions/domain.VesselToken"
                         }
                     },
                     "400": {
@@ -812,18 +790,21 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Добавляет новые, возвращает все: и добавленные и существующие, кроме удаленных",
+                "description": "токены с указанными jti отклоняются сразу после отзыва",
                 "consumes": [
                     "application/json"
                 ],
@@ -831,13 +812,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vessel"
+                    "Credential"
                 ],
-                "summary": "Добавление судна",
+                "summary": "Отзыв токенов судов",
                 "parameters": [
                     {
-                        "description": "список названий Судов",
-                        "name": "VesselNames",
+                        "description": "список jti токенов",
+                        "name": "JTIs",
                         "in": "body",
                         "required": true,
                         "schema": {
@@ -849,13 +830,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Ok",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Vessel"
-                            }
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -867,17 +845,23 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "нет действующих токенов с такими jti"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/groups": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "с текущими не удаленными судами",
                 "consumes": [
                     "application/json"
                 ],
@@ -885,32 +869,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vessel"
+                    "VesselGroup"
                 ],
-                "summary": "Удаление судна",
-                "parameters": [
-                    {
-                        "description": "список ID Судов",
-                        "name": "VesselNames",
-                        "in": "body",
-                        "required": true,
+                "summary": "Группы судов (флоты)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "integer"
+                                "$ref": "#/definitions/domain.VesselGroup"
                             }
                         }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Ok",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
                     },
                     "401": {
                         "description": "Unauthorized"
@@ -923,12 +893,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "название и суда, список судов заменяется целиком",
                 "consumes": [
                     "application/json"
                 ],
@@ -936,20 +907,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Vessel"
+                    "VesselGroup"
                 ],
-                "summary": "Восстановление судна",
+                "summary": "Изменение группы судов",
                 "parameters": [
                     {
-                        "description": "список ID Судов",
-                        "name": "VesselNames",
+                        "description": "группа",
+                        "name": "VesselGroup",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "integer"
-                            }
+                            "$ref": "#/definitions/domain.VesselGroup"
                         }
                     }
                 ],
@@ -969,208 +937,4300 @@ const docTemplate = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "charts_analyser_internal_app_domain.Track": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "location": {
-                    "type": "array",
-                    "items": {
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "название уникально, несуществующие ID судов пропускаются.\nID группы можно передавать в groupIDs вместе с vesselIDs - группа заменяется ее текущими судами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VesselGroup"
+                ],
+                "summary": "Добавление группы судов",
+                "parameters": [
+                    {
+                        "description": "группа",
+                        "name": "VesselGroup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VesselGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "суда не удаляются и остаются на мониторинге",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "VesselGroup"
+                ],
+                "summary": "Удаление групп судов",
+                "parameters": [
+                    {
+                        "description": "список ID групп",
+                        "name": "GroupIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "не отозванные, без самих ключей: prefix - начало ключа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIKey"
+                ],
+                "summary": "Действующие API ключи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ключ для интеграций, передается в заголовке X-API-Key вместо токена. Запросы выполняются от имени\nпользователя userID с ролью role и ограничением zoneNames, vesselIDs, groupIDs ключа.\nРоль ключа должна входить в роли пользователя, при запросе учитываются текущие роли и ограничение пользователя.\nОграничение ключа сужается ограничением пользователя: зоны - общие, без флота ключ наследует флот пользователя.\nexpiresAt - необязательный срок действия. Ключ возвращается только в ответе, хранится хэш",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIKey"
+                ],
+                "summary": "Создание API ключа",
+                "parameters": [
+                    {
+                        "description": "ключ",
+                        "name": "APIKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIKey"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIKeySecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "пользователь не найден",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "отозванный ключ (и прежний ключ после замены) отклоняется сразу",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIKey"
+                ],
+                "summary": "Отзыв API ключей",
+                "parameters": [
+                    {
+                        "description": "список ID ключей",
+                        "name": "KeyIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "нет действующих ключей"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "новый ключ с теми же ролью и ограничением, прежний ключ действует еще сутки",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "APIKey"
+                ],
+                "summary": "Замена API ключа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.APIKeySecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получение короткоживущего токена доступа и refresh токена для его обновления (POST /refresh).\nКак и раньше, тело ответа - токен доступа (text/plain), refresh токен и срок его действия - в заголовках\nX-Refresh-Token, X-Refresh-Expires-At. С Accept: application/json - все в теле (domain.AuthTokens)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain",
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Идентификация",
+                "parameters": [
+                    {
+                        "description": "Логин, пароль",
+                        "name": "UserAuth",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        },
+                        "headers": {
+                            "X-Refresh-Expires-At": {
+                                "type": "string",
+                                "description": "срок действия refresh токена"
+                            },
+                            "X-Refresh-Token": {
+                                "type": "string",
+                                "description": "refresh токен"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Отзывает refresh токен и все токены этого входа. Выданный токен доступа действует до истечения срока",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Выход",
+                "parameters": [
+                    {
+                        "description": "refresh токен",
+                        "name": "InputRefresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InputRefresh"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "токен недействителен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "поставленных на мониторинг, со статусом связи contact: ok, stale - нет треков дольше CONTACT_STALE_AFTER,\nlost - дольше CONTACT_LOST_AFTER. lastSeen - время последнего трека.\nСуда из списка наблюдения watchlist или, если не задан, из всех списков оператора",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Список судов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "watchlist",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MonitoredVessel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "судно ставится на мониторинг в start (сейчас, если не задан) и снимается в end (не снимается, если не задан).\nВ end судно убирается из списка, только если его добавило окно, а не оператор\nСуда добавляются в список наблюдения watchlist или в личный список оператора,\nна мониторинге судно остается, пока оно есть хотя бы в одном списке\nДействие записывается в журнал контроля от имени оператора из токена с комментарием comment\nСуда групп groupIDs, которых нет в теле запроса, ставятся на мониторинг сразу",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Поставить судно на контроль",
+                "parameters": [
+                    {
+                        "description": "список ID Судов или окон мониторинга",
+                        "name": "ControlItems",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ControlItem"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "name": "comment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "watchlist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "ID групп судов",
+                        "name": "groupIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Суда удаляются из списка наблюдения watchlist или из личного списка оператора,\nмониторинг прекращается, если судна нет в других списках. Запланированные окна мониторинга судов в списке отменяются\nДействие записывается в журнал контроля от имени оператора из токена с комментарием comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Снять судно с контроля",
+                "parameters": [
+                    {
+                        "description": "список ID Судов",
+                        "name": "VesselIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "name": "comment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "watchlist",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "ID групп судов, снимаются вместе с судами из тела запроса",
+                        "name": "groupIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/log": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "постановки на мониторинг и снятия с него: оператор (userID, пусто - по расписанию), список наблюдения, комментарий.\nФильтр по судам (vesselIDs и суда групп groupIDs), операторам и периоду.\nПользователю с ограничением - только суда его флота, находящиеся сейчас в его зонах",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Журнал контроля",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "finish",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "groupIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "userIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "vesselIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ControlLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/predict": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "судов на мониторинге: путь продлевается от последней точки трека с курсом (course, град.) и скоростью (speed, м/с)\nпо точкам трека за последние 10 минут, eta - время пересечения границы зоны в пределах horizon (сек, по умолчанию PREDICTION_HORIZON).\nСуда из списка наблюдения watchlist или, если не задан, из всех списков оператора, кроме судов с потерянной связью.\nПользователю с ограничением - только суда его флота, находящиеся в его зонах, и вход в его зоны",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Прогноз входа в зоны",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "horizon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "watchlist",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ZoneEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "не завершенные и не отмененные, в списках наблюдения оператора",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Запланированные окна мониторинга",
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ControlWindow"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/state": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "для выбранных судов, стоящих на мониторинге: из тела запроса и групп groupIDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Текущие данные",
+                "parameters": [
+                    {
+                        "description": "список ID Судов",
+                        "name": "VesselIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "ID групп судов",
+                        "name": "groupIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.VesselState"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "no data yet"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events (event: state) при обновлении состояния судов на мониторинге, в т.ч. смене зоны.\nБез vesselIDs и groupIDs - по всем судам, суда групп - на момент подключения.\nПользователю с ограничением - только суда его флота, находящиеся в его зонах. Для догрузки пропущенных событий после переподключения\nпередать id последнего полученного события в заголовке Last-Event-ID или параметре lastEventID.\n410 - события уже недоступны, нужно перечитать состояния через /monitor/state",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Поток изменений состояния судов",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "groupIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "lastEventID",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "vesselIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "токен возобновления",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "data события",
+                        "schema": {
+                            "$ref": "#/definitions/domain.StateEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/monitor/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "судов на мониторинге: всего (monitored), по текущим зонам (zones), вне зон (noZone),\nбез треков дольше CONTACT_STALE_AFTER (stale) и CONTACT_LOST_AFTER (lost),\nсуда с наибольшим временем нахождения в текущей зоне (longestDwell).\nСуда из списка наблюдения watchlist или, если не задан, из всех списков оператора.\nПользователю с ограничением - только суда его флота, находящиеся в его зонах, и счетчики его зон",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Monitor"
+                ],
+                "summary": "Сводка по мониторингу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "watchlist",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MonitorSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "Новая пара токенов по refresh токену. Refresh токен одноразовый: повторное использование\nотзывает все токены этого входа (401)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Обновление токена",
+                "parameters": [
+                    {
+                        "description": "refresh токен",
+                        "name": "InputRefresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InputRefresh"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthTokens"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "токен недействителен",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ID роли - бит маски role пользователя, права пользователя - объединение разрешений его ролей",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Роли с разрешениями",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "название и разрешения, список разрешений заменяется целиком. Роль admin сохраняет role:write",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Изменение роли",
+                "parameters": [
+                    {
+                        "description": "роль",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "название уникально, ID - свободный бит маски ролей. Разрешения: chart:read, track:read, track:write,\nmonitor:read, monitor:write, alert:read, alert:write, watchlist:read, watchlist:write, group:read, group:write,\nvessel:read, vessel:write, vessel:export, vessel:purge, credential:read, credential:write,\nwebhook:read, webhook:write, user:read, user:write, role:read, role:write",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Добавление роли",
+                "parameters": [
+                    {
+                        "description": "роль",
+                        "name": "Role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "встроенные роли и роли, назначенные пользователям (в т.ч. удаленным) или не отозванным API ключам, не удаляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Удаление ролей",
+                "parameters": [
+                    {
+                        "description": "список ID ролей",
+                        "name": "RoleIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "роль используется",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/track": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Точка - [lon, lat] или {\"location\": [lon, lat], \"timestamp\"} со временем точки (для отложенной отправки, по умолчанию - время приема).\nСостояние мониторинга считается по времени точек: точка старше текущего состояния сохраняется только в треке",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Track"
+                ],
+                "summary": "Запись трека судна",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer: JWT claims must have: id key used as vesselID and role: 1",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "[lon, lat] или точка со временем",
+                        "name": "Point",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.InputTrack"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/track/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "вместе с маршрутами судов групп groupIDs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Track"
+                ],
+                "summary": "Маршрут судна за указанный период",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Судна ",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "finish",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "ID групп судов",
+                        "name": "groupIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/charts_analyser_internal_app_domain.Track"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "постранично, по логину. Фильтры: role - пользователи с любым из битов роли, deleted - только удаленные,\nlogin - часть логина. Следующая страница - cursor=nextCursor с теми же фильтрами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "deleted",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 250,
+                        "type": "string",
+                        "name": "login",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            1,
+                            2,
+                            4,
+                            8,
+                            16
+                        ],
+                        "type": "integer",
+                        "x-enum-varnames": [
+                            "RoleVessel",
+                            "RoleOperator",
+                            "RoleAdmin",
+                            "RoleAnalyst",
+                            "RoleFleetManager"
+                        ],
+                        "name": "role",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserPage"
+                        }
+                    },
+                    "400": {
+                        "description": "ошибка валидации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Смена названия оператора, для не удаленных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Изменение оператора",
+                "parameters": [
+                    {
+                        "description": "данные пользователя",
+                        "name": "UserData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "role - сумма ID ролей (GET /roles), роль судна недопустима",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Добавление оператора",
+                "parameters": [
+                    {
+                        "description": "данные пользователя",
+                        "name": "UserData",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserChange"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Удаление операторов",
+                "parameters": [
+                    {
+                        "description": "список ID операторов",
+                        "name": "UserNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Восстановление оператора",
+                "parameters": [
+                    {
+                        "description": "список ID операторов",
+                        "name": "UserNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "в т.ч. удаленный",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Пользователь",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserDB"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/user/{id}/scope": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "зоны (юрисдикция) и суда или группы судов (флот), пустой список - без ограничения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Ограничение доступа пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserScope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пользователь видит в картах, треках, текущих данных и списке мониторинга только суда из vesselIDs\nи текущих судов групп groupIDs, только в зонах zoneNames: треки - точки в этих зонах, текущие\nданные и мониторинг - суда, находящиеся в них сейчас. Все списки пустые - ограничение снимается",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Изменение ограничения доступа пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ограничение",
+                        "name": "UserScope",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserScope"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vessels": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Информация о судах",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "groupIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "vesselIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Vessel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Смена названия и атрибутов (IMO, MMSI, позывной, флаг, тип, длина, ширина) судна, для не удаленных.\nАтрибуты заменяются целиком: не переданный атрибут очищается.\nСмена названия записывается в историю названий с момента renamedAt (по умолчанию - сейчас)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Изменение судна",
+                "parameters": [
+                    {
+                        "description": "список судов",
+                        "name": "VesselNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Vessel"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "name": "renamedAt",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "успешно обновлённые суда",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Vessel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "ошибка валидации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "description": "IMO или MMSI уже заданы другому судну"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет новые, возвращает все: и добавленные и существующие, кроме удаленных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Добавление судна",
+                "parameters": [
+                    {
+                        "description": "список названий Судов",
+                        "name": "VesselNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Vessel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Удаление судна",
+                "parameters": [
+                    {
+                        "description": "список ID Судов",
+                        "name": "VesselNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Восстановление судна",
+                "parameters": [
+                    {
+                        "description": "список ID Судов",
+                        "name": "VesselNames",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vessels/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "CSV с заголовком, колонки id (необязательна) и name, остальные колонки пропускаются.\nСудно с id создается с этим id или переименовывается, без id - создается по названию (существующее возвращается).\nРезультат по строкам: created, updated, existing, invalid (ошибка в строке), conflict (название другого судна).\nС dryRun=true строки проверяются без сохранения",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Импорт судов из CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VesselImport"
+                        }
+                    },
+                    "400": {
+                        "description": "ошибка файла",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vessels/list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сортировка ` + "`" + `sort` + "`" + `: ` + "`" + `name` + "`" + ` (по умолчанию), ` + "`" + `createdAt` + "`" + `, ` + "`" + `lastSeen` + "`" + ` (время последнего трека), ` + "`" + `desc` + "`" + ` - по убыванию.\nФильтры: ` + "`" + `deleted` + "`" + ` - только удаленные, ` + "`" + `monitored` + "`" + ` - на мониторинге или нет, ` + "`" + `name` + "`" + ` - часть названия\n(` + "`" + `match=prefix` + "`" + ` - начало). Следующая страница - ` + "`" + `cursor` + "`" + ` из ` + "`" + `nextCursor` + "`" + ` с теми же сортировкой и фильтрами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Список судов постранично",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "desc",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "contains",
+                            "prefix"
+                        ],
+                        "type": "string",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "monitored",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 250,
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "createdAt",
+                            "lastSeen"
+                        ],
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.VesselPage"
+                        }
+                    },
+                    "400": {
+                        "description": "ошибка валидации",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vessels/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Полнотекстовый поиск ` + "`" + `q` + "`" + ` по началу слов названия, IMO, MMSI, позывного, флага и типа (все слова),\nфильтры по атрибутам. Не более 100 судов, наиболее релевантные первыми, кроме удаленных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "Поиск судов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "callSign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "flag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "imo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "name": "lengthMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "name": "lengthMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "mmsi",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Vessel"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/vessels/{id}/names": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "названия с временем начала действия validFrom (пусто - до первого переименования), по возрастанию.\nТреки, журнал контроля и оповещения показывают название, действовавшее на время записи",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vessel"
+                ],
+                "summary": "История названий судна",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Судна",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.VesselNameRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/watchlists": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "личный и командные, в которых оператор владелец или участник",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Списки наблюдения оператора",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Watchlist"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "название и участники, только владельцем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Изменение командного списка наблюдения",
+                "parameters": [
+                    {
+                        "description": "список",
+                        "name": "Watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Watchlist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "владелец - оператор из токена, members - ID операторов-участников.\nСуда добавляются через POST /monitor?watchlist=ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Добавление командного списка наблюдения",
+                "parameters": [
+                    {
+                        "description": "список",
+                        "name": "Watchlist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Watchlist"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "только владельцем. Суда снимаются с мониторинга, если их нет в других списках",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Watchlist"
+                ],
+                "summary": "Удаление командных списков наблюдения",
+                "parameters": [
+                    {
+                        "description": "список ID списков",
+                        "name": "WatchlistIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Подписки на события",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пустой secret - не изменяется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Изменение подписки на события",
+                "parameters": [
+                    {
+                        "description": "подписка",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "События events: controlSet, controlUnset - постановка, снятие с мониторинга, zoneEnter, zoneExit - вход, выход из карты,\nvesselLost - потеря связи с судном. Пустой events - все события.\nНа url отправляется POST с телом события, заголовок X-Webhook-Signature: sha256=HMAC-SHA256(secret, X-Webhook-Timestamp + \".\" + тело).\nЕсли secret не задан, он генерируется. Secret возвращается только при создании",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Добавление подписки на события",
+                "parameters": [
+                    {
+                        "description": "подписка",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "недоставленные события удаляемых подписок не отправляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Удаление подписок на события",
+                "parameters": [
+                    {
+                        "description": "список ID подписок",
+                        "name": "WebhookIDs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "последние, код ответа, ошибка, длительность",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Попытки доставки событий подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "charts_analyser_internal_app_domain.Track": {
+            "type": "object",
+            "properties": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imo": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "location": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "mmsi": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "config.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/config.JWK"
+                    }
+                }
+            }
+        },
+        "constant.Permission": {
+            "type": "string",
+            "enum": [
+                "chart:read",
+                "track:read",
+                "track:write",
+                "monitor:read",
+                "monitor:write",
+                "alert:read",
+                "alert:write",
+                "watchlist:read",
+                "watchlist:write",
+                "group:read",
+                "group:write",
+                "vessel:read",
+                "vessel:write",
+                "vessel:export",
+                "vessel:purge",
+                "credential:read",
+                "credential:write",
+                "webhook:read",
+                "webhook:write",
+                "user:read",
+                "user:write",
+                "role:read",
+                "role:write",
+                "apikey:read",
+                "apikey:write",
+                "audit:read"
+            ],
+            "x-enum-varnames": [
+                "PermChartRead",
+                "PermTrackRead",
+                "PermTrackWrite",
+                "PermMonitorRead",
+                "PermMonitorWrite",
+                "PermAlertRead",
+                "PermAlertWrite",
+                "PermWatchlistRead",
+                "PermWatchlistWrite",
+                "PermGroupRead",
+                "PermGroupWrite",
+                "PermVesselRead",
+                "PermVesselWrite",
+                "PermVesselExport",
+                "PermVesselPurge",
+                "PermCredentialRead",
+                "PermCredentialWrite",
+                "PermWebhookRead",
+                "PermWebhookWrite",
+                "PermUserRead",
+                "PermUserWrite",
+                "PermRoleRead",
+                "PermRoleWrite",
+                "PermAPIKeyRead",
+                "PermAPIKeyWrite",
+                "PermAuditRead"
+            ]
+        },
+        "constant.Role": {
+            "type": "integer",
+            "enum": [
+                1,
+                2,
+                4,
+                8,
+                16
+            ],
+            "x-enum-varnames": [
+                "RoleVessel",
+                "RoleOperator",
+                "RoleAdmin",
+                "RoleAnalyst",
+                "RoleFleetManager"
+            ]
+        },
+        "domain.APIKey": {
+            "type": "object",
+            "required": [
+                "name",
+                "role",
+                "zoneNames"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "groupIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "prefix": {
+                    "description": "Prefix start of key to recognize it",
+                    "type": "string"
+                },
+                "previousExpiresAt": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/constant.Role"
+                },
+                "rotatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "zoneNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.APIKeySecret": {
+            "type": "object",
+            "required": [
+                "name",
+                "role",
+                "zoneNames"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "groupIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "prefix": {
+                    "description": "Prefix start of key to recognize it",
+                    "type": "string"
+                },
+                "previousExpiresAt": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/constant.Role"
+                },
+                "rotatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "zoneNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Alert": {
+            "type": "object",
+            "properties": {
+                "acknowledgedAt": {
+                    "type": "string"
+                },
+                "acknowledgedBy": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.AlertEvent"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ruleID": {
+                    "type": "integer"
+                },
+                "ruleName": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "vessel": {
+                    "$ref": "#/definitions/domain.Vessel"
+                },
+                "zoneName": {
+                    "type": "string"
+                },
+                "zoneTimeIn": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AlertEvent": {
+            "type": "string",
+            "enum": [
+                "enter",
+                "exit",
+                "dwell"
+            ],
+            "x-enum-varnames": [
+                "AlertEventEnter",
+                "AlertEventExit",
+                "AlertEventDwell"
+            ]
+        },
+        "domain.AlertRule": {
+            "type": "object",
+            "required": [
+                "event",
+                "name"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "dwellMinutes": {
+                    "type": "integer"
+                },
+                "event": {
+                    "enum": [
+                        "enter",
+                        "exit",
+                        "dwell"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AlertEvent"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 250
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "zoneNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Audit": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "apiKeyID": {
+                    "type": "integer"
+                },
+                "before": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "string"
+                },
+                "targetIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AuditPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Audit"
+                    }
+                }
+            }
+        },
+        "domain.AuthTokens": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "refreshExpiresAt": {
+                    "type": "string"
+                },
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ContactStatus": {
+            "type": "string",
+            "enum": [
+                "ok",
+                "stale",
+                "lost"
+            ],
+            "x-enum-varnames": [
+                "ContactOK",
+                "ContactStale",
+                "ContactLost"
+            ]
+        },
+        "domain.ControlItem": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "vesselID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ControlLog": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "control": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "vessel": {
+                    "$ref": "#/definitions/domain.Vessel"
+                },
+                "watchlistID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ControlWindow": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "vessel": {
+                    "$ref": "#/definitions/domain.Vessel"
+                },
+                "watchlistID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CurrentZone": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "timeIn": {
+                    "type": "string"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Duration": {
+            "type": "integer",
+            "enum": [
+                -9223372036854775808,
+                9223372036854775807,
+                1,
+                1000,
+                1000000,
+                1000000000,
+                60000000000,
+                3600000000000
+            ],
+            "x-enum-varnames": [
+                "minDuration",
+                "maxDuration",
+                "Nanosecond",
+                "Microsecond",
+                "Millisecond",
+                "Second",
+                "Minute",
+                "Hour"
+            ]
+        },
+        "domain.InputRefresh": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InputTrack": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "array",
+                    "items": {
                         "type": "number"
                     }
                 },
-                "name": {
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InputVesselsInterval": {
+            "type": "object",
+            "properties": {
+                "finish": {
+                    "type": "string"
+                },
+                "groupIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start": {
+                    "type": "string"
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "domain.InputZones": {
+            "type": "object",
+            "properties": {
+                "finish": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "zoneNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.LoginForm": {
+            "type": "object",
+            "required": [
+                "login",
+                "password"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MonitorSummary": {
+            "type": "object",
+            "properties": {
+                "longestDwell": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ZoneDwell"
+                    }
+                },
+                "lost": {
+                    "type": "integer"
+                },
+                "monitored": {
+                    "type": "integer"
+                },
+                "noZone": {
+                    "type": "integer"
+                },
+                "stale": {
+                    "type": "integer"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ZoneCount"
+                    }
+                }
+            }
+        },
+        "domain.MonitoredVessel": {
+            "type": "object",
+            "properties": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "contact": {
+                    "$ref": "#/definitions/domain.ContactStatus"
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imo": {
+                    "type": "string"
+                },
+                "lastSeen": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "mmsi": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "$ref": "#/definitions/constant.Role"
+                },
+                "isBuiltin": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/constant.Permission"
+                    }
+                }
+            }
+        },
+        "domain.StateEvent": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.VesselState"
+                },
+                "zoneChanged": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.UserChange": {
+            "type": "object",
+            "required": [
+                "login",
+                "role"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/constant.Role"
+                }
+            }
+        },
+        "domain.UserDB": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isDeleted": {
+                    "type": "boolean"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "modifiedAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/constant.Role"
+                }
+            }
+        },
+        "domain.UserPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UserDB"
+                    }
+                }
+            }
+        },
+        "domain.UserScope": {
+            "type": "object",
+            "required": [
+                "zoneNames"
+            ],
+            "properties": {
+                "groupIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "userID": {
+                    "type": "integer"
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "zoneNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.Vessel": {
+            "type": "object",
+            "properties": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imo": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "mmsi": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "domain.VesselArchive": {
+            "type": "object",
+            "properties": {
+                "exportedAt": {
+                    "type": "string"
+                },
+                "tables": {
+                    "type": "object"
+                },
+                "vesselID": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.VesselCredential": {
+            "type": "object",
+            "required": [
+                "vesselID"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "issuedAt": {
+                    "type": "string"
+                },
+                "issuedBy": {
+                    "type": "integer"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "revokedBy": {
+                    "type": "integer"
+                },
+                "vesselID": {
+                    "type": "integer"
+                },
+                "vesselName": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.VesselGroup": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "domain.VesselImport": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "existing": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.VesselImportRow"
+                    }
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.VesselImportRow": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.VesselImportStatus"
+                }
+            }
+        },
+        "domain.VesselImportStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "existing",
+                "invalid",
+                "conflict"
+            ],
+            "x-enum-varnames": [
+                "VesselImportCreated",
+                "VesselImportUpdated",
+                "VesselImportExisting",
+                "VesselImportInvalid",
+                "VesselImportConflict"
+            ]
+        },
+        "domain.VesselInfo": {
+            "type": "object",
+            "properties": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "createdAt": {
                     "type": "string"
                 },
-                "timestamp": {
+                "flag": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imo": {
+                    "type": "string"
+                },
+                "isDeleted": {
+                    "type": "boolean"
+                },
+                "lastSeen": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "mmsi": {
+                    "type": "string"
+                },
+                "monitored": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
                 }
             }
         },
-        "constant.Role": {
-            "type": "integer",
-            "enum": [
-                1,
-                2,
-                4
-            ],
-            "x-enum-varnames": [
-                "RoleVessel",
-                "RoleOperator",
-                "RoleAdmin"
-            ]
+        "domain.VesselNameRecord": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "validFrom": {
+                    "type": "string"
+                }
+            }
         },
-        "domain.CurrentZone": {
+        "domain.VesselPage": {
             "type": "object",
             "properties": {
-                "timeIn": {
+                "nextCursor": {
                     "type": "string"
                 },
-                "zones": {
+                "vessels": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/domain.VesselInfo"
                     }
                 }
             }
         },
-        "domain.Duration": {
-            "type": "integer",
-            "enum": [
-                -9223372036854775808,
-                9223372036854775807,
-                1,
-                1000,
-                1000000,
-                1000000000,
-                60000000000,
-                3600000000000
-            ],
-            "x-enum-varnames": [
-                "minDuration",
-                "maxDuration",
-                "Nanosecond",
-                "Microsecond",
-                "Millisecond",
-                "Second",
-                "Minute",
-                "Hour"
-            ]
-        },
-        "domain.InputVesselsInterval": {
+        "domain.VesselState": {
             "type": "object",
             "properties": {
-                "finish": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "contact": {
+                    "$ref": "#/definitions/domain.ContactStatus"
+                },
+                "control": {
+                    "type": "boolean"
+                },
+                "controlEnd": {
                     "type": "string"
                 },
-                "start": {
+                "controlStart": {
                     "type": "string"
                 },
-                "vesselIDs": {
+                "currentZone": {
+                    "$ref": "#/definitions/domain.CurrentZone"
+                },
+                "flag": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imo": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "location": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "number"
                     }
+                },
+                "mmsi": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "zoneDuration": {
+                    "$ref": "#/definitions/domain.Duration"
+                },
+                "zoneDurations": {
+                    "$ref": "#/definitions/domain.ZoneDurations"
                 }
             }
         },
-        "domain.InputZones": {
+        "domain.VesselToken": {
             "type": "object",
+            "required": [
+                "vesselID"
+            ],
             "properties": {
-                "finish": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "expiresAt": {
                     "type": "string"
                 },
-                "start": {
+                "issuedAt": {
                     "type": "string"
                 },
-                "zoneNames": {
+                "issuedBy": {
+                    "type": "integer"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "revokedBy": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "vesselID": {
+                    "type": "integer"
+                },
+                "vesselName": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Watchlist": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "ownerID": {
+                    "type": "integer"
+                },
+                "personal": {
+                    "type": "boolean"
+                },
+                "vesselIDs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
                     }
                 }
             }
         },
-        "domain.LoginForm": {
+        "domain.Webhook": {
             "type": "object",
             "required": [
-                "login",
-                "password"
+                "url"
             ],
             "properties": {
-                "login": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebhookEvent"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
                     "type": "string",
-                    "minLength": 6
+                    "maxLength": 128,
+                    "minLength": 16
                 },
-                "password": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "domain.UserChange": {
+        "domain.WebhookDelivery": {
             "type": "object",
-            "required": [
-                "login",
-                "password",
-                "role"
-            ],
             "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.WebhookEvent"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "login": {
-                    "type": "string",
-                    "minLength": 6
+                "outboxID": {
+                    "type": "integer"
                 },
-                "password": {
+                "statusCode": {
+                    "type": "integer"
+                },
+                "timestamp": {
                     "type": "string"
                 },
-                "role": {
-                    "enum": [
-                        2,
-                        4
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/constant.Role"
-                        }
-                    ]
+                "webhookID": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.Vessel": {
+        "domain.WebhookEvent": {
+            "type": "string",
+            "enum": [
+                "controlSet",
+                "controlUnset",
+                "zoneEnter",
+                "zoneExit",
+                "vesselLost"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventControlSet",
+                "WebhookEventControlUnset",
+                "WebhookEventZoneEnter",
+                "WebhookEventZoneExit",
+                "WebhookEventVesselLost"
+            ]
+        },
+        "domain.ZoneCount": {
+            "type": "object",
+            "properties": {
+                "vessels": {
+                    "type": "integer"
+                },
+                "zoneName": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ZoneDurations": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/domain.Duration"
+            }
+        },
+        "domain.ZoneDwell": {
             "type": "object",
             "properties": {
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
+                },
+                "currentZone": {
+                    "$ref": "#/definitions/domain.CurrentZone"
+                },
+                "flag": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "imo": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
+                "mmsi": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "zoneDuration": {
+                    "$ref": "#/definitions/domain.Duration"
                 }
             }
         },
-        "domain.VesselState": {
+        "domain.ZoneEntry": {
             "type": "object",
             "properties": {
-                "control": {
-                    "type": "boolean"
+                "beam": {
+                    "type": "number",
+                    "maximum": 100
                 },
-                "controlEnd": {
-                    "type": "string"
+                "callSign": {
+                    "type": "string",
+                    "maxLength": 7
                 },
-                "controlStart": {
+                "course": {
+                    "type": "number"
+                },
+                "eta": {
                     "type": "string"
                 },
-                "currentZone": {
-                    "$ref": "#/definitions/domain.CurrentZone"
+                "flag": {
+                    "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "imo": {
+                    "type": "string"
+                },
+                "length": {
+                    "type": "number",
+                    "maximum": 500
+                },
                 "location": {
                     "type": "array",
                     "items": {
                         "type": "number"
                     }
                 },
+                "mmsi": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "speed": {
+                    "type": "number"
+                },
                 "timestamp": {
                     "type": "string"
                 },
-                "zoneDuration": {
-                    "$ref": "#/definitions/domain.Duration"
+                "type": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "zoneName": {
+                    "type": "string"
                 }
             }
         }
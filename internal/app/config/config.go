@@ -3,30 +3,70 @@ package config
 import (
 	"charts_analyser/internal/app/constant"
 	"flag"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	ServerAddress string
 	DatabaseDSN   string
 	JWT
+	Contact
+	Prediction
 }
 
 type JWT struct {
 	JWTSigningKey       string
 	TokenLifeTime       uint64
 	TokenVesselLifeTime uint64
+	// TokenRefreshLifeTime of operator refresh token, sec
+	TokenRefreshLifeTime uint64
+	// JWTKeysDir of PEM key pairs, tokens are signed with JWTSigningKey (HS512) if empty
+	JWTKeysDir string
+	// JWTSigningKID key id of new tokens, the last private key by name if empty
+	JWTSigningKID string
+	// JWTAcceptHS512 verify HS512 tokens with JWTSigningKey along with key pairs, for transition to key pairs
+	JWTAcceptHS512 bool
+	// TokenVesselLegacyUntil vessel tokens without jti are accepted until this time, unix sec, 0 - not accepted
+	TokenVesselLegacyUntil uint64
+	Keys                   *KeySet
+}
+
+// Contact silence thresholds of monitored vessel, sec
+type Contact struct {
+	ContactStaleAfter uint64
+	ContactLostAfter  uint64
+}
+
+// Since times of last point at now, before which contact is stale and lost
+func (c *Contact) Since(now time.Time) (staleSince, lostSince time.Time) {
+	return now.Add(-time.Duration(c.ContactStaleAfter) * time.Second), now.Add(-time.Duration(c.ContactLostAfter) * time.Second)
+}
+
+// Prediction default horizon of zone entry prediction, sec
+type Prediction struct {
+	PredictionHorizon uint64
 }
 
 func NewConfig() *Config {
 	return &Config{
 		ServerAddress: constant.ServerAddress,
 		JWT: JWT{
-			JWTSigningKey:       constant.JWTSigningKey,
-			TokenLifeTime:       constant.TokenLifeTime,
-			TokenVesselLifeTime: constant.TokenVesselLifeTime,
+			JWTSigningKey:        constant.JWTSigningKey,
+			TokenLifeTime:        constant.TokenLifeTime,
+			TokenVesselLifeTime:  constant.TokenVesselLifeTime,
+			TokenRefreshLifeTime: constant.TokenRefreshLifeTime,
+		},
+		Contact: Contact{
+			ContactStaleAfter: constant.ContactStaleAfter,
+			ContactLostAfter:  constant.ContactLostAfter,
+		},
+		Prediction: Prediction{
+			PredictionHorizon: constant.PredictionHorizon,
 		},
 	}
 }
@@ -55,6 +95,42 @@ func (c *Config) WithEnv() *Config {
 			c.TokenVesselLifeTime = v
 		}
 	}
+	if keysDir, ok := os.LookupEnv(constant.EnvNameJWTKeysDir); ok && keysDir != "" {
+		c.JWTKeysDir = keysDir
+	}
+	if kid, ok := os.LookupEnv(constant.EnvNameJWTSigningKID); ok && kid != "" {
+		c.JWTSigningKID = kid
+	}
+	if acceptHS, ok := os.LookupEnv(constant.EnvNameJWTAcceptHS512); ok && acceptHS != "" {
+		if v, err := strconv.ParseBool(acceptHS); err == nil {
+			c.JWTAcceptHS512 = v
+		}
+	}
+	if legacyUntil, ok := os.LookupEnv(constant.EnvNameJWTVesselLegacyUntil); ok && legacyUntil != "" {
+		if v, err := strconv.ParseUint(legacyUntil, 10, 64); err == nil {
+			c.TokenVesselLegacyUntil = v
+		}
+	}
+	if jwtRLt, ok := os.LookupEnv(constant.EnvNameJWTRefreshLifeTime); ok && jwtRLt != "" {
+		if v, err := strconv.ParseUint(jwtRLt, 10, 64); err == nil {
+			c.TokenRefreshLifeTime = v
+		}
+	}
+	if stale, ok := os.LookupEnv(constant.EnvNameContactStaleAfter); ok && stale != "" {
+		if v, err := strconv.ParseUint(stale, 10, 64); err == nil {
+			c.ContactStaleAfter = v
+		}
+	}
+	if lost, ok := os.LookupEnv(constant.EnvNameContactLostAfter); ok && lost != "" {
+		if v, err := strconv.ParseUint(lost, 10, 64); err == nil {
+			c.ContactLostAfter = v
+		}
+	}
+	if horizon, ok := os.LookupEnv(constant.EnvNamePredictionHorizon); ok && horizon != "" {
+		if v, err := strconv.ParseUint(horizon, 10, 64); err == nil {
+			c.PredictionHorizon = v
+		}
+	}
 	return c
 }
 
@@ -64,6 +140,14 @@ func (c *Config) withFlags() *Config {
 	flag.StringVar(&c.JWTSigningKey, "j", c.JWTSigningKey, "Provide the jwt secret key "+constant.EnvNameJWTSecretKey)
 	flag.Uint64Var(&c.TokenLifeTime, "jlt", c.TokenLifeTime, "Provide the jwt token lifetime, sec "+constant.EnvNameJWTLifeTime)
 	flag.Uint64Var(&c.TokenVesselLifeTime, "jltv", c.TokenVesselLifeTime, "Provide the vessel jwt token lifetime, sec "+constant.EnvNameJWTVesselLifeTime)
+	flag.StringVar(&c.JWTKeysDir, "jk", c.JWTKeysDir, "Provide the directory of jwt PEM key pairs "+constant.EnvNameJWTKeysDir)
+	flag.StringVar(&c.JWTSigningKID, "jkid", c.JWTSigningKID, "Provide the jwt signing key id "+constant.EnvNameJWTSigningKID)
+	flag.BoolVar(&c.JWTAcceptHS512, "jhs", c.JWTAcceptHS512, "Provide accept HS512 jwt along with key pairs "+constant.EnvNameJWTAcceptHS512)
+	flag.Uint64Var(&c.TokenVesselLegacyUntil, "jvlu", c.TokenVesselLegacyUntil, "Provide the time until vessel jwt without jti is accepted, unix sec "+constant.EnvNameJWTVesselLegacyUntil)
+	flag.Uint64Var(&c.TokenRefreshLifeTime, "jltr", c.TokenRefreshLifeTime, "Provide the refresh token lifetime, sec "+constant.EnvNameJWTRefreshLifeTime)
+	flag.Uint64Var(&c.ContactStaleAfter, "cs", c.ContactStaleAfter, "Provide the monitored vessel silence before stale contact, sec "+constant.EnvNameContactStaleAfter)
+	flag.Uint64Var(&c.ContactLostAfter, "cl", c.ContactLostAfter, "Provide the monitored vessel silence before lost contact, sec "+constant.EnvNameContactLostAfter)
+	flag.Uint64Var(&c.PredictionHorizon, "ph", c.PredictionHorizon, "Provide the default horizon of zone entry prediction, sec "+constant.EnvNamePredictionHorizon)
 	flag.Parse()
 	return c
 }
@@ -75,3 +159,36 @@ func (c *Config) CleanSchemes() *Config {
 	c.DatabaseDSN = strings.Trim(c.DatabaseDSN, "'")
 	return c
 }
+
+// LoadKeys key pairs of JWTKeysDir
+func (c *JWT) LoadKeys() (err error) {
+	if c.JWTKeysDir == "" {
+		return
+	}
+	c.Keys, err = LoadKeySet(c.JWTKeysDir, c.JWTSigningKID)
+	return
+}
+
+// VesselLegacyAccepted vessel token without jti is accepted at now
+func (c *JWT) VesselLegacyAccepted(now time.Time) bool {
+	return c.TokenVesselLegacyUntil > 0 && now.Unix() < int64(c.TokenVesselLegacyUntil)
+}
+
+// Sign claims by key pair if loaded, otherwise HS512 by JWTSigningKey
+func (c *JWT) Sign(claims jwt.Claims) (string, error) {
+	if c.Keys != nil {
+		return c.Keys.Sign(claims)
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString([]byte(c.JWTSigningKey))
+}
+
+// KeyFunc verification key of token: public key by key id, JWTSigningKey for HS512 without key pairs or if accepted
+func (c *JWT) KeyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS512.Alg() && (c.Keys == nil || c.JWTAcceptHS512) {
+		return []byte(c.JWTSigningKey), nil
+	}
+	if c.Keys == nil {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", token.Header["alg"])
+	}
+	return c.Keys.Key(token)
+}
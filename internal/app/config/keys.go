@@ -0,0 +1,172 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	keyFileExt     = ".pem"
+	keyRSAMinBits  = 2048
+	keyHeaderKeyID = "kid"
+)
+
+// KeySet key pairs of tokens by key id - file name: RSA keys sign RS256, Ed25519 - EdDSA.
+// Signing key signs new tokens, every key (also public only, retired from signing) verifies
+type KeySet struct {
+	signingKID string
+	keys       map[string]keyPair
+}
+
+type keyPair struct {
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// LoadKeySet PEM keys of dir, signingKID - the last private key by name if empty
+func LoadKeySet(dir, signingKID string) (set *KeySet, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		return
+	}
+	set = &KeySet{keys: make(map[string]keyPair)}
+	lastPrivate := ""
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != keyFileExt {
+			continue
+		}
+		var data []byte
+		if data, err = os.ReadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(entry.Name(), keyFileExt)
+		var key keyPair
+		if key, err = parseKey(data); err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		set.keys[kid] = key
+		if key.private != nil {
+			lastPrivate = kid
+		}
+	}
+	if len(set.keys) == 0 {
+		return nil, fmt.Errorf("no %s keys in %s", keyFileExt, dir)
+	}
+	if signingKID == "" {
+		signingKID = lastPrivate
+	}
+	if key, ok := set.keys[signingKID]; !ok || key.private == nil {
+		return nil, fmt.Errorf("no private signing key '%s'", signingKID)
+	}
+	set.signingKID = signingKID
+	return
+}
+
+func parseKey(data []byte) (key keyPair, err error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return key, errors.New("no PEM block")
+	}
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return
+	}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key = keyPair{method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}
+	case *rsa.PublicKey:
+		key = keyPair{method: jwt.SigningMethodRS256, public: k}
+	case ed25519.PrivateKey:
+		key = keyPair{method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}
+	case ed25519.PublicKey:
+		key = keyPair{method: jwt.SigningMethodEdDSA, public: k}
+	default:
+		return key, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if k, ok := key.public.(*rsa.PublicKey); ok && k.N.BitLen() < keyRSAMinBits {
+		return key, fmt.Errorf("RSA key must be at least %d bits", keyRSAMinBits)
+	}
+	return
+}
+
+// Sign claims by signing key, key id is set in header
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := s.keys[s.signingKID]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header[keyHeaderKeyID] = s.signingKID
+	return token.SignedString(key.private)
+}
+
+// Key public key of token by key id, signing method must match the key
+func (s *KeySet) Key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header[keyHeaderKeyID].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unexpected jwt key id=%v", token.Header[keyHeaderKeyID])
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// JWK public key in JSON Web Key format (RFC 7517, RFC 8037)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS public keys by key id
+func (s *KeySet) JWKS() (set JWKS) {
+	set.Keys = make([]JWK, 0, len(s.keys))
+	for kid, key := range s.keys {
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.method.Alg()}
+		switch k := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return
+}
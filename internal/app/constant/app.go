@@ -11,6 +11,45 @@ const (
 	LogFormat = "[${time}] ${status} - ${latency} ${method} ${path}\n"
 
 	MonitorLastPeriod = 30 * time.Second
+	VesselSearchLimit = 100
+	VesselListLimit   = 50
+	VesselImportLimit = 5000
+	UserListLimit     = 50
+	AuditListLimit    = 100
+	VesselImportFile  = "file"
+	VesselNameMaxLen  = 250
+	TrackClockSkew    = time.Minute
+	SummaryDwellTop   = 10
+
+	ContactStaleAfter    = 60 * 5
+	ContactLostAfter     = 60 * 30
+	ContactCheckInterval = 30 * time.Second
+
+	PredictionHorizon     = 60 * 60
+	PredictionHorizonMax  = 60 * 60 * 24
+	PredictionTrackWindow = 10 * time.Minute
+	PredictionMinSpeed    = 0.5
+
+	ControlScheduleInterval = 30 * time.Second
+	ControlCommentMaxLen    = 1000
+
+	StreamHistorySize      = 1000
+	StreamSubscriberBuffer = 64
+	StreamKeepAlive        = 15 * time.Second
+
+	WebhookPollInterval = 5 * time.Second
+	WebhookBatchSize    = 50
+	WebhookTimeout      = 10 * time.Second
+	WebhookLease        = 2 * WebhookTimeout // of one claimed message, messages are claimed one by one
+	WebhookMaxAttempts  = 10
+	WebhookBackoffBase  = 10 * time.Second
+	WebhookBackoffMax   = time.Hour
+	WebhookSecretLen    = 32
+
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderID        = "X-Webhook-Id"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
 )
 
 var GeoAllowedRange = [4]float64{-180, -75, 180, 75}
@@ -6,6 +6,8 @@ const (
 	RoleVessel   Role = 0x1
 	RoleOperator Role = 0x1 << iota
 	RoleAdmin
+	RoleAnalyst
+	RoleFleetManager
 
 	PasswordMinLen = 8
 	PasswordMaxLen = 30
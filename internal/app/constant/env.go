@@ -1,9 +1,17 @@
 package constant
 
 const (
-	EnvNameServerAddress     = "ADDRESS"
-	EnvNameDBDSN             = "DATABASE_DSN"
-	EnvNameJWTSecretKey      = "JWT_SECRET_KEY"
-	EnvNameJWTLifeTime       = "JWT_OPERATOR_LIFE_TIME"
-	EnvNameJWTVesselLifeTime = "JWT_VESSEL_LIFE_TIME"
+	EnvNameServerAddress        = "ADDRESS"
+	EnvNameDBDSN                = "DATABASE_DSN"
+	EnvNameJWTSecretKey         = "JWT_SECRET_KEY"
+	EnvNameJWTLifeTime          = "JWT_OPERATOR_LIFE_TIME"
+	EnvNameJWTVesselLifeTime    = "JWT_VESSEL_LIFE_TIME"
+	EnvNameJWTRefreshLifeTime   = "JWT_REFRESH_LIFE_TIME"
+	EnvNameJWTKeysDir           = "JWT_KEYS_DIR"
+	EnvNameJWTSigningKID        = "JWT_SIGNING_KID"
+	EnvNameJWTAcceptHS512       = "JWT_ACCEPT_HS512"
+	EnvNameJWTVesselLegacyUntil = "JWT_VESSEL_LEGACY_UNTIL"
+	EnvNameContactStaleAfter    = "CONTACT_STALE_AFTER"
+	EnvNameContactLostAfter     = "CONTACT_LOST_AFTER"
+	EnvNamePredictionHorizon    = "PREDICTION_HORIZON"
 )
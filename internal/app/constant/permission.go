@@ -0,0 +1,50 @@
+package constant
+
+import "time"
+
+// Permission named right checked per route, role is a set of permissions stored in database
+type Permission string
+
+const (
+	PermChartRead       Permission = "chart:read"
+	PermTrackRead       Permission = "track:read"
+	PermTrackWrite      Permission = "track:write"
+	PermMonitorRead     Permission = "monitor:read"
+	PermMonitorWrite    Permission = "monitor:write"
+	PermAlertRead       Permission = "alert:read"
+	PermAlertWrite      Permission = "alert:write"
+	PermWatchlistRead   Permission = "watchlist:read"
+	PermWatchlistWrite  Permission = "watchlist:write"
+	PermGroupRead       Permission = "group:read"
+	PermGroupWrite      Permission = "group:write"
+	PermVesselRead      Permission = "vessel:read"
+	PermVesselWrite     Permission = "vessel:write"
+	PermVesselExport    Permission = "vessel:export"
+	PermVesselPurge     Permission = "vessel:purge"
+	PermCredentialRead  Permission = "credential:read"
+	PermCredentialWrite Permission = "credential:write"
+	PermWebhookRead     Permission = "webhook:read"
+	PermWebhookWrite    Permission = "webhook:write"
+	PermUserRead        Permission = "user:read"
+	PermUserWrite       Permission = "user:write"
+	PermRoleRead        Permission = "role:read"
+	PermRoleWrite       Permission = "role:write"
+	PermAPIKeyRead      Permission = "apikey:read"
+	PermAPIKeyWrite     Permission = "apikey:write"
+	PermAuditRead       Permission = "audit:read"
+
+	// RoleCacheTTL of role permissions, changes by other instances are applied after it
+	RoleCacheTTL = 30 * time.Second
+	// RoleMaxBits of role bitmask, new role takes the lowest free bit
+	RoleMaxBits = 30
+)
+
+var Permissions = []Permission{
+	PermChartRead, PermTrackRead, PermTrackWrite,
+	PermMonitorRead, PermMonitorWrite, PermAlertRead, PermAlertWrite,
+	PermWatchlistRead, PermWatchlistWrite, PermGroupRead, PermGroupWrite,
+	PermVesselRead, PermVesselWrite, PermVesselExport, PermVesselPurge,
+	PermCredentialRead, PermCredentialWrite, PermWebhookRead, PermWebhookWrite,
+	PermUserRead, PermUserWrite, PermRoleRead, PermRoleWrite, PermAPIKeyRead, PermAPIKeyWrite,
+	PermAuditRead,
+}
@@ -3,17 +3,48 @@ package constant
 const (
 	RouteID = "/:id"
 
-	RouteAPI = "/api"
+	RouteAPI  = "/api"
+	RouteJWKS = "/.well-known/jwks.json"
 
-	RouteLogin = "/login"
-	RouteUser  = "/user"
+	RouteLogin   = "/login"
+	RouteRefresh = "/refresh"
+	RouteLogout  = "/logout"
+	RouteUser    = "/user"
+	RouteAdmin   = "/admin"
+	RouteRoles   = "/roles"
+	RouteScope   = "/scope"
+	RouteKeys    = "/keys"
+	RouteRotate  = "/rotate"
+	RouteAudit   = "/audit"
 
 	RouteChart   = "/chart"
 	RouteVessels = "/vessels"
 	RouteZones   = "/zones"
+	RouteSearch  = "/search"
+	RouteList    = "/list"
+	RouteNames   = "/names"
+	RouteExport  = "/export"
+	RouteImport  = "/import"
 
-	RouteMonitor = "/monitor"
-	RouteState   = "/state"
+	RouteMonitor  = "/monitor"
+	RouteState    = "/state"
+	RouteStream   = "/stream"
+	RouteSchedule = "/schedule"
+	RouteLog      = "/log"
+	RouteSummary  = "/summary"
+	RoutePredict  = "/predict"
 
 	RouteTrack = "/track"
+
+	RouteAlerts = "/alerts"
+	RouteRules  = "/rules"
+
+	RouteWatchlists = "/watchlists"
+
+	RouteGroups = "/groups"
+
+	RouteCredentials = "/credentials"
+
+	RouteWebhooks   = "/webhooks"
+	RouteDeliveries = "/deliveries"
 )
@@ -1,10 +1,28 @@
 package constant
 
 const (
-	DBZones            = "zones"
-	DBTracks           = "tracks"
-	DBVessels          = "vessels"
-	DBControlLog       = "control_log"
-	DBControlDashboard = "control_dashboard"
-	DBUsers            = "users"
+	DBZones             = "zones"
+	DBTracks            = "tracks"
+	DBVessels           = "vessels"
+	DBControlLog        = "control_log"
+	DBControlDashboard  = "control_dashboard"
+	DBControlSchedule   = "control_schedule"
+	DBUsers             = "users"
+	DBAlertRules        = "alert_rules"
+	DBAlerts            = "alerts"
+	DBWebhooks          = "webhooks"
+	DBWebhookOutbox     = "webhook_outbox"
+	DBWebhookDelivery   = "webhook_deliveries"
+	DBWatchlists        = "watchlists"
+	DBWatchlistMembers  = "watchlist_members"
+	DBWatchlistVessels  = "watchlist_vessels"
+	DBVesselGroups      = "vessel_groups"
+	DBGroupVessels      = "vessel_group_vessels"
+	DBVesselCredentials = "vessel_credentials"
+	DBVesselNames       = "vessel_names"
+	DBRefreshTokens     = "refresh_tokens"
+	DBRoles             = "roles"
+	DBUserScopes        = "user_scopes"
+	DBAPIKeys           = "api_keys"
+	DBAuditLog          = "audit_log"
 )
@@ -1,10 +1,33 @@
 package constant
 
 const (
-	JWTSigningKey       = "JWTSigningKeyDefaultString"
-	CtxStorageKey       = "auth"
-	TokenLifeTime       = 60 * 60 * 24
-	TokenVesselLifeTime = 60 * 60 * 24 * 365
-	TokenRoleKey        = "role"
-	TokenIDKey          = "sub"
+	JWTSigningKey        = "JWTSigningKeyDefaultString"
+	CtxStorageKey        = "auth"
+	AuthScheme           = "Bearer"
+	TokenLifeTime        = 60 * 15
+	TokenVesselLifeTime  = 60 * 60 * 24 * 365
+	TokenRefreshLifeTime = 60 * 60 * 24 * 30
+	TokenRefreshLen      = 32
+	TokenFamilyLen       = 16
+	TokenRoleKey         = "role"
+	TokenIDKey           = "sub"
+	TokenJTIKey          = "jti"
+	TokenJTILen          = 16
+
+	HeaderAPIKey = "X-API-Key"
+	// HeaderRefreshToken of login response with text body, HeaderRefreshExpiresAt - its expiry (RFC 3339)
+	HeaderRefreshToken     = "X-Refresh-Token"
+	HeaderRefreshExpiresAt = "X-Refresh-Expires-At"
+	CtxAPIKey              = "apiKey"
+	// CtxAudit of mutating request, read by repository through request context
+	CtxAudit = "audit"
+	// APIKeyPrefix of api key, tells it from other secrets in configs and logs
+	APIKeyPrefix = "cak_"
+	APIKeyLen    = 32
+	// APIKeyShownLen of key start kept to recognize key in list
+	APIKeyShownLen = 12
+	// APIKeyRotateGrace previous key is accepted after rotation, sec
+	APIKeyRotateGrace = 60 * 60 * 24
+	// APIKeyTouchInterval of last use update, sec
+	APIKeyTouchInterval = 60
 )
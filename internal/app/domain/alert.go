@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+)
+
+type AlertRuleID int64
+
+type AlertID int64
+
+type AlertEvent string
+
+const (
+	AlertEventEnter AlertEvent = "enter"
+	AlertEventExit  AlertEvent = "exit"
+	AlertEventDwell AlertEvent = "dwell"
+)
+
+// AlertRule empty VesselIDs or ZoneNames means any vessel on monitoring or any zone
+type AlertRule struct {
+	ID           AlertRuleID `json:"id" db:"id"`
+	Name         string      `json:"name" db:"name" validate:"required,max=250"`
+	VesselIDs    VesselIDs   `json:"vesselIDs" db:"vessel_ids"`
+	ZoneNames    ZoneNames   `json:"zoneNames" db:"zone_names"`
+	Event        AlertEvent  `json:"event" db:"event" validate:"required,oneof=enter exit dwell"`
+	DwellMinutes *int        `json:"dwellMinutes,omitempty" db:"dwell_minutes" validate:"required_if=Event dwell,omitempty,gt=0"`
+	CreatedAt    time.Time   `json:"createdAt" db:"created_at"`
+}
+
+func (r *AlertRule) Dwell() time.Duration {
+	if r.DwellMinutes == nil {
+		return 0
+	}
+	return time.Duration(*r.DwellMinutes) * time.Minute
+}
+
+func (r *AlertRule) MatchZone(zone ZoneName) bool {
+	return len(r.ZoneNames) == 0 || r.ZoneNames.Contains(zone)
+}
+
+type Alert struct {
+	ID             AlertID     `json:"id" db:"id"`
+	RuleID         AlertRuleID `json:"ruleID" db:"rule_id"`
+	RuleName       string      `json:"ruleName" db:"rule_name"`
+	Vessel         `json:"vessel"`
+	ZoneName       ZoneName   `json:"zoneName" db:"zone_name"`
+	Event          AlertEvent `json:"event" db:"event"`
+	ZoneTimeIn     *time.Time `json:"zoneTimeIn" db:"zone_time_in"`
+	Timestamp      time.Time  `json:"timestamp" db:"timestamp"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt" db:"acknowledged_at"`
+	AcknowledgedBy *UserID    `json:"acknowledgedBy" db:"acknowledged_by"`
+}
+
+type InputAlerts struct {
+	InputVessels
+	DateInterval
+	Acknowledged *bool `json:"acknowledged" query:"acknowledged"`
+}
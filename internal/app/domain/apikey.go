@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"charts_analyser/internal/app/constant"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+type APIKeyID int64
+
+func (v *APIKeyID) SetFromStr(s string) (err error) {
+	var f int64
+	if f, err = strconv.ParseInt(s, 10, 64); err == nil {
+		*v = APIKeyID(f)
+	}
+	return
+}
+
+// APIKey long-lived key of machine client, acts as user UserID with Role and scope of key instead of user's.
+// The key itself is stored only as hash, after rotation the previous key is accepted until PreviousExpiresAt
+type APIKey struct {
+	ID   APIKeyID `json:"id" db:"id"`
+	Name string   `json:"name" db:"name" validate:"required,max=100"`
+	// Prefix start of key to recognize it
+	Prefix string        `json:"prefix" db:"prefix"`
+	Role   constant.Role `json:"role" db:"role" validate:"required,gt=0"`
+	UserScope
+	Hash              string     `json:"-" db:"key_hash"`
+	PreviousHash      *string    `json:"-" db:"previous_hash"`
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt,omitempty" db:"previous_expires_at"`
+	CreatedBy         UserID     `json:"createdBy" db:"created_by"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	RotatedAt         *time.Time `json:"rotatedAt,omitempty" db:"rotated_at"`
+	LastUsedAt        *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	RevokedAt         *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+}
+
+// APIKeySecret key with secret, secret is shown only once
+type APIKeySecret struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// NewAPIKeySecret random key of size bytes, its hash and prefix
+func NewAPIKeySecret(size int) (key, prefix, hash string, err error) {
+	b := make([]byte, size)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	key = constant.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:constant.APIKeyShownLen], HashAPIKey(key), nil
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
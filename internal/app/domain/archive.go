@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VesselArchive everything held about vessel: rows of vessel and dependent tables by table name, geometry as GeoJSON
+type VesselArchive struct {
+	VesselID   VesselID                   `json:"vesselID"`
+	ExportedAt time.Time                  `json:"exportedAt"`
+	Tables     map[string]json.RawMessage `json:"tables" swaggertype:"object"`
+}
+
+// InputPurge export archive before purge
+type InputPurge struct {
+	Export bool `json:"export" query:"export"`
+}
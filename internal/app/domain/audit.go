@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/lib/pq"
+	"time"
+)
+
+type AuditID int64
+
+// AuditRows json array of table rows, null if rows are not known
+type AuditRows []byte
+
+func (r AuditRows) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return []byte("null"), nil
+	}
+	return r, nil
+}
+
+func (r *AuditRows) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*r = nil
+		return nil
+	}
+	*r = bytes.Clone(b)
+	return nil
+}
+
+func (r AuditRows) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return string(r), nil
+}
+
+func (r *AuditRows) Scan(src interface{}) error {
+	switch srcV := src.(type) {
+	case nil:
+		*r = nil
+	case string:
+		*r = AuditRows(srcV)
+	case []byte:
+		*r = bytes.Clone(srcV)
+	}
+	return nil
+}
+
+// Audit record of mutating api call: who (user, api key of machine client), request and rows of Target table
+// with TargetIDs before and after the change. Record is written in transaction of the change, call changed
+// several tables has record per table, call without changed rows - record without target
+type Audit struct {
+	ID        AuditID        `json:"id" db:"id"`
+	Timestamp time.Time      `json:"timestamp" db:"timestamp"`
+	UserID    *UserID        `json:"userID" db:"user_id"`
+	APIKeyID  *APIKeyID      `json:"apiKeyID,omitempty" db:"api_key_id"`
+	Method    string         `json:"method" db:"method"`
+	Path      string         `json:"path" db:"path"`
+	Target    *string        `json:"target,omitempty" db:"target"`
+	TargetIDs pq.StringArray `json:"targetIDs" db:"target_ids" swaggertype:"array,string"`
+	Before    AuditRows      `json:"before" db:"before" swaggertype:"array,object"`
+	After     AuditRows      `json:"after" db:"after" swaggertype:"array,object"`
+}
+
+// AuditRequest mutating api call in progress. Recorded is set when change of any table is recorded
+type AuditRequest struct {
+	UserID   *UserID
+	APIKeyID *APIKeyID
+	Method   string
+	Path     string
+	Recorded bool
+}
+
+// AuditUser sets user of request in ctx if it is not known from token (login, refresh, logout)
+func AuditUser(ctx context.Context, userID UserID) {
+	if req := AuditRequestFrom(ctx); req != nil && req.UserID == nil {
+		req.UserID = &userID
+	}
+}
+
+// AuditRequestFrom context of request, nil out of mutating api call
+func AuditRequestFrom(ctx context.Context) *AuditRequest {
+	req, _ := ctx.Value(constant.CtxAudit).(*AuditRequest)
+	return req
+}
+
+// InputAudit page of records, the newest first: UserIDs - who made calls, Target - table, TargetID - id of its row,
+// Path - start of request path, Cursor - NextCursor of previous page
+type InputAudit struct {
+	DateInterval
+	UserIDs  UserIDs  `json:"userIDs" query:"userIDs"`
+	APIKeyID APIKeyID `json:"apiKeyID" query:"apiKeyID"`
+	Method   string   `json:"method" query:"method" validate:"omitempty,oneof=POST PUT PATCH DELETE"`
+	Path     string   `json:"path" query:"path" validate:"max=250"`
+	Target   string   `json:"target" query:"target" validate:"max=50"`
+	TargetID string   `json:"targetID" query:"targetID" validate:"max=100"`
+	Limit    uint64   `json:"limit" query:"limit" validate:"omitempty,max=500"`
+	Cursor   string   `json:"cursor" query:"cursor"`
+}
+
+// AuditCursor position after the last record of page
+type AuditCursor struct {
+	ID AuditID `json:"i"`
+}
+
+func (c *AuditCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c *AuditCursor) FromString(s string) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, c)
+}
+
+type AuditPage struct {
+	Records    []Audit `json:"records"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// VesselCredential vessel token issued by operator, identified by jti claim.
+// Token is rejected after revocation
+type VesselCredential struct {
+	JTI        string     `json:"jti" db:"jti"`
+	VesselID   VesselID   `json:"vesselID" db:"vessel_id" validate:"required,gt=0"`
+	VesselName VesselName `json:"vesselName" db:"vessel_name"`
+	Comment    string     `json:"comment" db:"comment" validate:"max=200"`
+	IssuedBy   UserID     `json:"issuedBy" db:"issued_by"`
+	IssuedAt   time.Time  `json:"issuedAt" db:"issued_at"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	RevokedBy  *UserID    `json:"revokedBy,omitempty" db:"revoked_by"`
+}
+
+// VesselToken issued credential with token, token is shown only once
+type VesselToken struct {
+	VesselCredential
+	Token string `json:"token"`
+}
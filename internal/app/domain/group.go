@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+type VesselGroupID int64
+
+func (v *VesselGroupID) SetFromStr(s string) (err error) {
+	var f int64
+	if f, err = strconv.ParseInt(s, 10, 64); err == nil {
+		*v = VesselGroupID(f)
+	}
+	return
+}
+
+type VesselGroupIDs []VesselGroupID
+
+func (v VesselGroupIDs) Value() (driver.Value, error) {
+	a := make(pq.Int64Array, 0, len(v))
+	for _, id := range v {
+		a = append(a, int64(id))
+	}
+	return a.Value()
+}
+
+func (v *VesselGroupIDs) Scan(src interface{}) error {
+	var a pq.Int64Array
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*v = make(VesselGroupIDs, 0, len(a))
+	for _, id := range a {
+		*v = append(*v, VesselGroupID(id))
+	}
+	return nil
+}
+
+// VesselGroup named fleet of vessels (by charterer, region...), shared by operators.
+// GroupIDs are accepted along with VesselIDs and expand to current not deleted members
+type VesselGroup struct {
+	ID        VesselGroupID `json:"id" db:"id"`
+	Name      string        `json:"name" db:"name" validate:"required,max=100"`
+	VesselIDs VesselIDs     `json:"vesselIDs" db:"vessel_ids" validate:"dive,gt=0"`
+	CreatedBy UserID        `json:"createdBy" db:"created_by"`
+	CreatedAt time.Time     `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,56 @@
+package domain
+
+// VesselImportStatus result of csv row import
+type VesselImportStatus string
+
+const (
+	VesselImportCreated  VesselImportStatus = "created"
+	VesselImportUpdated  VesselImportStatus = "updated"
+	VesselImportExisting VesselImportStatus = "existing"
+	VesselImportInvalid  VesselImportStatus = "invalid"
+	VesselImportConflict VesselImportStatus = "conflict"
+)
+
+// VesselImportRow row of csv: vessel with id is created or renamed, without id - created by name
+type VesselImportRow struct {
+	Line   int                `json:"line"`
+	ID     *VesselID          `json:"id,omitempty"`
+	Name   VesselName         `json:"name"`
+	Status VesselImportStatus `json:"status"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// VesselImport report of import by rows, nothing is saved on dry run
+type VesselImport struct {
+	DryRun   bool              `json:"dryRun"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Existing int               `json:"existing"`
+	Invalid  int               `json:"invalid"`
+	Conflict int               `json:"conflict"`
+	Rows     []VesselImportRow `json:"rows"`
+}
+
+// Count rows by status
+func (v *VesselImport) Count() {
+	v.Created, v.Updated, v.Existing, v.Invalid, v.Conflict = 0, 0, 0, 0, 0
+	for _, row := range v.Rows {
+		switch row.Status {
+		case VesselImportCreated:
+			v.Created++
+		case VesselImportUpdated:
+			v.Updated++
+		case VesselImportExisting:
+			v.Existing++
+		case VesselImportInvalid:
+			v.Invalid++
+		case VesselImportConflict:
+			v.Conflict++
+		}
+	}
+}
+
+// InputVesselImport check rows without saving
+type InputVesselImport struct {
+	DryRun bool `json:"dryRun" query:"dryRun"`
+}
@@ -1,7 +1,28 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// InputVessels vessels by ID and members of groups
 type InputVessels struct {
-	VesselIDs VesselIDs `json:"vesselIDs"`
+	VesselIDs VesselIDs      `json:"vesselIDs"`
+	GroupIDs  VesselGroupIDs `json:"groupIDs"`
+}
+
+// NoVessels groups are set, but they have no vessels, so no vessel matches
+func (in *InputVessels) NoVessels() bool {
+	return len(in.GroupIDs) > 0 && len(in.VesselIDs) == 0
+}
+
+// AddVessels not yet listed
+func (in *InputVessels) AddVessels(ids ...VesselID) {
+	for _, id := range ids {
+		if !in.VesselIDs.Contains(id) {
+			in.VesselIDs = append(in.VesselIDs, id)
+		}
+	}
 }
 
 type InputVesselsInterval struct {
@@ -19,3 +40,29 @@ type InputVessel struct {
 }
 
 type InputPoint []float64
+
+// InputTrack point of track at Timestamp (time of receipt if empty). In json it is [lon, lat] or object
+type InputTrack struct {
+	Location  InputPoint `json:"location"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+func (t *InputTrack) UnmarshalJSON(data []byte) error {
+	var loc InputPoint
+	if err := json.Unmarshal(data, &loc); err == nil {
+		*t = InputTrack{Location: loc}
+		return nil
+	}
+	type track InputTrack
+	var v track
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = InputTrack(v)
+	return nil
+}
+
+type InputStream struct {
+	InputVessels
+	LastEventID string `json:"lastEventID" query:"lastEventID"`
+}
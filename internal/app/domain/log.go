@@ -3,8 +3,62 @@ package domain
 import "time"
 
 type ControlLog struct {
-	*Vessel
-	Timestamp time.Time `db:"Timestamp"`
-	Control   bool      `db:"control"`
-	Comment   *string   `db:"comment"`
+	ID          int64 `json:"id" db:"id"`
+	*Vessel     `json:"vessel"`
+	Timestamp   time.Time    `json:"timestamp" db:"timestamp"`
+	Control     bool         `json:"control" db:"control"`
+	UserID      *UserID      `json:"userID" db:"user_id"`
+	WatchlistID *WatchlistID `json:"watchlistID" db:"watchlist_id"`
+	Comment     *string      `json:"comment" db:"comment"`
+}
+
+// ControlAction who and why sets (unsets) vessels on monitoring in watchlist, without user - scheduler
+type ControlAction struct {
+	WatchlistID WatchlistID
+	UserID      *UserID
+	Comment     *string
+}
+
+// Events of action: log entry on every vessel and webhook payload for vessels with changed control state
+func (a ControlAction) Events(control bool, vessels Vessels, changed VesselIDs, now time.Time) (logs []ControlLog, payloads []WebhookPayload) {
+	event := WebhookEventControlUnset
+	if control {
+		event = WebhookEventControlSet
+	}
+	logs = make([]ControlLog, 0, len(vessels))
+	for _, v := range vessels {
+		logs = append(logs, ControlLog{
+			Vessel:      v,
+			Timestamp:   now,
+			Control:     control,
+			UserID:      a.UserID,
+			WatchlistID: &a.WatchlistID,
+			Comment:     a.Comment,
+		})
+		if changed.Contains(v.ID) {
+			payloads = append(payloads, WebhookPayload{Event: event, Timestamp: now, Vessel: *v})
+		}
+	}
+	return
+}
+
+// InputControl watchlist and comment of set (unset) monitoring
+type InputControl struct {
+	InputWatchlist
+	Comment string `json:"comment" query:"comment"`
+}
+
+// Action of user, empty comment is not saved
+func (in *InputControl) Action(userID UserID, watchlistID WatchlistID) ControlAction {
+	action := ControlAction{WatchlistID: watchlistID, UserID: &userID}
+	if in.Comment != "" {
+		action.Comment = &in.Comment
+	}
+	return action
+}
+
+type InputControlLog struct {
+	InputVessels
+	DateInterval
+	UserIDs UserIDs `json:"userIDs" query:"userIDs"`
 }
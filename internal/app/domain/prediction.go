@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ZoneEntry vessel predicted to enter zone at ETA, by course and speed of recent track
+type ZoneEntry struct {
+	Vessel
+	ZoneName  ZoneName  `json:"zoneName" db:"zone_name"`
+	ETA       time.Time `json:"eta" db:"eta"`
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+	Location  Point     `json:"location" db:"location"`
+	Speed     float64   `json:"speed" db:"speed"`
+	Course    float64   `json:"course" db:"course"`
+}
+
+// InputPrediction vessels of watchlist (all lists of operator if empty), Horizon of prediction, sec
+type InputPrediction struct {
+	InputWatchlist
+	Horizon uint64 `json:"horizon" query:"horizon"`
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"charts_analyser/internal/app/constant"
+	"database/sql/driver"
+	"github.com/lib/pq"
+)
+
+type Permissions []constant.Permission
+
+func (p Permissions) Value() (driver.Value, error) {
+	a := make(pq.StringArray, 0, len(p))
+	for _, perm := range p {
+		a = append(a, string(perm))
+	}
+	return a.Value()
+}
+
+func (p *Permissions) Scan(src interface{}) error {
+	var a pq.StringArray
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*p = make(Permissions, 0, len(a))
+	for _, perm := range a {
+		*p = append(*p, constant.Permission(perm))
+	}
+	return nil
+}
+
+// Role named set of permissions, ID is a bit of users role mask, user has permissions of all his roles.
+// Builtin roles (vessel, operator, admin) can not be deleted
+type Role struct {
+	ID          constant.Role `json:"id" db:"id"`
+	Name        string        `json:"name" db:"name" validate:"required,max=50"`
+	Permissions Permissions   `json:"permissions" db:"permissions" validate:"dive,permission"`
+	IsBuiltin   bool          `json:"isBuiltin" db:"is_builtin"`
+}
+
+// RolePermissions permissions of role bits
+type RolePermissions map[constant.Role]map[constant.Permission]struct{}
+
+func NewRolePermissions(roles []Role) RolePermissions {
+	rp := make(RolePermissions, len(roles))
+	for _, role := range roles {
+		perms := make(map[constant.Permission]struct{}, len(role.Permissions))
+		for _, perm := range role.Permissions {
+			perms[perm] = struct{}{}
+		}
+		rp[role.ID] = perms
+	}
+	return rp
+}
+
+// Can any role of mask grant permission
+func (rp RolePermissions) Can(mask constant.Role, perm constant.Permission) bool {
+	for id, perms := range rp {
+		if !mask.CheckIsRole(id) {
+			continue
+		}
+		if _, ok := perms[perm]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKnown every bit of mask is an existing role
+func (rp RolePermissions) IsKnown(mask constant.Role) bool {
+	for id := range rp {
+		mask &^= id
+	}
+	return mask == 0
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ControlItem vessel to set on monitoring, optionally for a window from Start (now if empty)
+// till End (until unset if empty). In json it is vessel ID or object
+type ControlItem struct {
+	VesselID VesselID   `json:"vesselID"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+}
+
+func (c *ControlItem) UnmarshalJSON(data []byte) error {
+	var id VesselID
+	if err := json.Unmarshal(data, &id); err == nil {
+		*c = ControlItem{VesselID: id}
+		return nil
+	}
+	type item ControlItem
+	var v item
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = ControlItem(v)
+	return nil
+}
+
+// IsWindow start or end time is set
+func (c *ControlItem) IsWindow() bool {
+	return c.Start != nil || c.End != nil
+}
+
+// ControlWindow scheduled monitoring of vessel
+type ControlWindow struct {
+	ID          int64       `json:"id" db:"id"`
+	WatchlistID WatchlistID `json:"watchlistID" db:"watchlist_id"`
+	Vessel      `json:"vessel"`
+	Start       *time.Time `json:"start" db:"start_at"`
+	End         *time.Time `json:"end" db:"end_at"`
+	StartedAt   *time.Time `json:"startedAt" db:"started_at"`
+	UserID      *UserID    `json:"userID" db:"user_id"`
+	Comment     *string    `json:"comment" db:"comment"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// Action on monitoring by window, user and comment are of who scheduled it
+func (w *ControlWindow) Action() ControlAction {
+	return ControlAction{WatchlistID: w.WatchlistID, UserID: w.UserID, Comment: w.Comment}
+}
@@ -0,0 +1,108 @@
+package domain
+
+// UserScope restriction of user to zones (jurisdiction) and vessels or vessel groups (fleet).
+// Empty ZoneNames - any zone, empty VesselIDs and GroupIDs - any vessel
+type UserScope struct {
+	UserID    UserID         `json:"userID" db:"user_id"`
+	ZoneNames ZoneNames      `json:"zoneNames" db:"zone_names" validate:"dive,required,max=20"`
+	VesselIDs VesselIDs      `json:"vesselIDs" db:"vessel_ids" validate:"dive,gt=0"`
+	GroupIDs  VesselGroupIDs `json:"groupIDs" db:"group_ids" validate:"dive,gt=0"`
+}
+
+func (u *UserScope) IsEmpty() bool {
+	return len(u.ZoneNames) == 0 && len(u.VesselIDs) == 0 && len(u.GroupIDs) == 0
+}
+
+// Within limits restriction by restriction of user: zones are common ones, fleet of user is inherited if not restricted.
+// False if no zone of user is left
+func (u *UserScope) Within(user *UserScope) bool {
+	if user == nil {
+		return true
+	}
+	if len(u.VesselIDs) == 0 && len(u.GroupIDs) == 0 {
+		u.VesselIDs, u.GroupIDs = user.VesselIDs, user.GroupIDs
+	}
+	if len(user.ZoneNames) == 0 {
+		return true
+	}
+	if len(u.ZoneNames) == 0 {
+		u.ZoneNames = user.ZoneNames
+		return true
+	}
+	zones := make(ZoneNames, 0, len(u.ZoneNames))
+	for _, zone := range u.ZoneNames {
+		if user.ZoneNames.Contains(zone) {
+			zones = append(zones, zone)
+		}
+	}
+	u.ZoneNames = zones
+	return len(zones) > 0
+}
+
+// Scope restriction of caller, nil scope allows everything.
+// ZoneNames nil - any zone, VesselIDs nil - any vessel, otherwise vessels of UserScope with current vessels of its groups
+type Scope struct {
+	ZoneNames ZoneNames
+	VesselIDs VesselIDs
+}
+
+func (s *Scope) HasVessel(id VesselID) bool {
+	return s == nil || s.VesselIDs == nil || s.VesselIDs.Contains(id)
+}
+
+// Vessels of ids in scope
+func (s *Scope) Vessels(ids []VesselID) VesselIDs {
+	if s == nil || s.VesselIDs == nil {
+		return ids
+	}
+	in := make(VesselIDs, 0, len(ids))
+	for _, id := range ids {
+		if s.VesselIDs.Contains(id) {
+			in = append(in, id)
+		}
+	}
+	return in
+}
+
+// Zones of zones in scope
+func (s *Scope) Zones(zones []ZoneName) []ZoneName {
+	if s == nil || s.ZoneNames == nil {
+		return zones
+	}
+	in := make([]ZoneName, 0, len(zones))
+	for _, zone := range zones {
+		if s.ZoneNames.Contains(zone) {
+			in = append(in, zone)
+		}
+	}
+	return in
+}
+
+// Intersect restriction of both scopes
+func (s *Scope) Intersect(o *Scope) *Scope {
+	if s == nil {
+		return o
+	}
+	if o == nil {
+		return s
+	}
+	in := &Scope{ZoneNames: s.ZoneNames, VesselIDs: s.VesselIDs}
+	if o.ZoneNames != nil {
+		in.ZoneNames = s.Zones(o.ZoneNames)
+	}
+	if o.VesselIDs != nil {
+		in.VesselIDs = s.Vessels(o.VesselIDs)
+	}
+	return in
+}
+
+// HasState vessel of state is in scope and, if restricted by zones, is now in one of them
+func (s *Scope) HasState(state *VesselState) bool {
+	if !s.HasVessel(state.ID) {
+		return false
+	}
+	if s == nil || s.ZoneNames == nil {
+		return true
+	}
+	return state.CurrentZone != nil && len(s.Zones(state.CurrentZone.Zones)) > 0
+}
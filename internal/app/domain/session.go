@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// RefreshToken server side state of operator refresh token, the token itself is stored only as hash.
+// Tokens rotated from one login are a family: reuse of rotated token revokes the family
+type RefreshToken struct {
+	Hash      string     `db:"token_hash"`
+	FamilyID  string     `db:"family_id"`
+	UserID    UserID     `db:"user_id"`
+	IssuedAt  time.Time  `db:"issued_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// IsActive not used, not revoked and not expired
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && t.ExpiresAt.After(now)
+}
+
+// NewRefreshToken random token and its state valid for lifeTime
+func NewRefreshToken(userID UserID, familyID string, lifeTime time.Duration, size int) (token string, state *RefreshToken, err error) {
+	b := make([]byte, size)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	now := time.Now()
+	state = &RefreshToken{
+		Hash:      HashRefreshToken(token),
+		FamilyID:  familyID,
+		UserID:    userID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(lifeTime),
+	}
+	return
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthTokens short-lived access token and refresh token to get the next pair
+type AuthTokens struct {
+	AccessToken      string    `json:"accessToken"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	RefreshToken     string    `json:"refreshToken"`
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt"`
+}
+
+type InputRefresh struct {
+	RefreshToken string `json:"refreshToken"`
+}
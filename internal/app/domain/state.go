@@ -21,9 +21,49 @@ type Track struct {
 	Vessel
 }
 
+// CurrentZone zones of vessel, TimeIn - time of last change of zones, Entries - entry time of each zone
 type CurrentZone struct {
-	Zones  []ZoneName `json:"zones" db:"zones"`
-	TimeIn time.Time  `json:"timeIn" db:"time_in"`
+	Zones   []ZoneName             `json:"zones" db:"zones"`
+	TimeIn  time.Time              `json:"timeIn" db:"time_in"`
+	Entries map[ZoneName]time.Time `json:"entries" db:"entries"`
+}
+
+// NewCurrentZone zones entered at time, zones staying from prev keep their entry time
+func NewCurrentZone(prev *CurrentZone, zones []ZoneName, at time.Time) *CurrentZone {
+	cur := &CurrentZone{Zones: zones, TimeIn: at, Entries: make(map[ZoneName]time.Time, len(zones))}
+	for _, zone := range zones {
+		cur.Entries[zone] = at
+		if prev != nil && ZoneNames(prev.Zones).Contains(zone) {
+			cur.Entries[zone] = prev.EnteredAt(zone)
+		}
+	}
+	return cur
+}
+
+// EnteredAt entry time of zone, TimeIn if unknown
+func (v *CurrentZone) EnteredAt(zone ZoneName) time.Time {
+	if t, ok := v.Entries[zone]; ok {
+		return t
+	}
+	return v.TimeIn
+}
+
+// ZoneChanges zones entered and exited from prev to cur, without prev - no changes known
+func ZoneChanges(prev, cur *CurrentZone) (entered, exited []ZoneName) {
+	if prev == nil || cur == nil {
+		return
+	}
+	for _, zone := range cur.Zones {
+		if !ZoneNames(prev.Zones).Contains(zone) {
+			entered = append(entered, zone)
+		}
+	}
+	for _, zone := range prev.Zones {
+		if !ZoneNames(cur.Zones).Contains(zone) {
+			exited = append(exited, zone)
+		}
+	}
+	return
 }
 
 func (v CurrentZone) Value() (driver.Value, error) {
@@ -50,13 +90,68 @@ func (v *CurrentZone) Scan(src interface{}) error {
 	return nil
 }
 
+// ContactStatus how long monitored vessel is silent: ok, stale or lost
+type ContactStatus string
+
+const (
+	ContactOK    ContactStatus = "ok"
+	ContactStale ContactStatus = "stale"
+	ContactLost  ContactStatus = "lost"
+)
+
+// ContactOf vessel silent since, by times before which contact is stale and lost
+func ContactOf(since, staleSince, lostSince time.Time) ContactStatus {
+	switch {
+	case since.Before(lostSince):
+		return ContactLost
+	case since.Before(staleSince):
+		return ContactStale
+	}
+	return ContactOK
+}
+
+// MonitoredVessel vessel on monitoring with its contact status and last track time
+type MonitoredVessel struct {
+	Vessel
+	Contact  ContactStatus `json:"contact" db:"contact"`
+	LastSeen *time.Time    `json:"lastSeen" db:"timestamp"`
+}
+
 type VesselState struct {
 	Control
 	Vessel
-	Timestamp    *time.Time   `json:"timestamp" db:"timestamp"`
-	Location     *Point       `json:"location" db:"location"`
-	CurrentZone  *CurrentZone `json:"currentZone" db:"current_zone"`
-	ZoneDuration *Duration    `json:"zoneDuration" db:"zone_duration"`
+	Timestamp     *time.Time    `json:"timestamp" db:"timestamp"`
+	Location      *Point        `json:"location" db:"location"`
+	CurrentZone   *CurrentZone  `json:"currentZone" db:"current_zone"`
+	ZoneDuration  *Duration     `json:"zoneDuration" db:"zone_duration"`
+	ZoneDurations ZoneDurations `json:"zoneDurations" db:"zone_durations"`
+	Contact       ContactStatus `json:"contact" db:"contact"`
+}
+
+// ZoneDurations time in each current zone since its entry
+type ZoneDurations map[ZoneName]Duration
+
+// Scan json object of zone durations in seconds
+func (v *ZoneDurations) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	var source []byte
+	switch srcV := src.(type) {
+	case string:
+		source = []byte(srcV)
+	default:
+		source = src.([]byte)
+	}
+	var seconds map[ZoneName]float64
+	if err := json.Unmarshal(source, &seconds); err != nil {
+		return err
+	}
+	*v = make(ZoneDurations, len(seconds))
+	for zone, sec := range seconds {
+		(*v)[zone] = Duration(time.Duration(sec) * time.Second)
+	}
+	return nil
 }
 
 type Duration time.Duration
@@ -130,3 +225,11 @@ func (v *Point) Scan(src interface{}) error {
 	}
 	return nil
 }
+
+// StateEvent state change pushed to monitoring subscribers
+type StateEvent struct {
+	ID          string      `json:"id"`
+	ZoneChanged bool        `json:"zoneChanged"`
+	State       VesselState `json:"state"`
+	Seq         uint64      `json:"-"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// MonitorSummary of monitoring board: vessels on monitoring by current zone and contact status
+type MonitorSummary struct {
+	Monitored    int         `json:"monitored" db:"monitored"`
+	NoZone       int         `json:"noZone" db:"no_zone"`
+	Stale        int         `json:"stale" db:"stale"`
+	Lost         int         `json:"lost" db:"lost"`
+	Zones        []ZoneCount `json:"zones"`
+	LongestDwell []ZoneDwell `json:"longestDwell"`
+}
+
+// ZoneCount vessels on monitoring currently in zone
+type ZoneCount struct {
+	ZoneName ZoneName `json:"zoneName" db:"zone_name"`
+	Vessels  int      `json:"vessels" db:"vessels"`
+}
+
+// ZoneDwell vessel in current zones, ZoneDuration - time in the earliest entered of them
+type ZoneDwell struct {
+	Vessel
+	CurrentZone  *CurrentZone `json:"currentZone" db:"current_zone"`
+	ZoneDuration *Duration    `json:"zoneDuration" db:"zone_duration"`
+}
@@ -15,7 +15,7 @@ func NewClaimVessels(conf *config.JWT, id VesselID, name VesselName) *ClaimsAuth
 		},
 		Name: name.String(),
 		Role: constant.RoleVessel,
-		key:  conf.JWTSigningKey,
+		conf: conf,
 	}
 }
 
@@ -35,24 +35,23 @@ func NewClaimUser(conf *config.JWT, id UserID, login UserLogin, role constant.Ro
 		},
 		Name: login.String(),
 		Role: role,
-		key:  conf.JWTSigningKey,
+		conf: conf,
 	}
 }
 
+// WithID sets jti claim, by which issued token can be revoked
+func (c *ClaimsAuth) WithID(jti string) *ClaimsAuth {
+	c.ID = jti
+	return c
+}
+
 type ClaimsAuth struct {
 	jwt.RegisteredClaims
 	Name string        `json:"name"`
 	Role constant.Role `json:"role"`
-	key  string
+	conf *config.JWT
 }
 
 func (c *ClaimsAuth) Token() (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, c)
-
-	tokenString, err := token.SignedString([]byte(c.key))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	return c.conf.Sign(c)
 }
@@ -3,6 +3,8 @@ package domain
 import (
 	"charts_analyser/internal/app/constant"
 	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
 	"golang.org/x/crypto/bcrypt"
 	"strconv"
 	"time"
@@ -14,6 +16,14 @@ func (v *UserID) String() string {
 	return strconv.FormatInt(int64(*v), 10)
 }
 
+func (v *UserID) SetFromStr(s string) (err error) {
+	var f int64
+	if f, err = strconv.ParseInt(s, 10, 64); err == nil {
+		*v = UserID(f)
+	}
+	return
+}
+
 type UserLogin string
 
 func (v *UserLogin) String() string {
@@ -27,7 +37,7 @@ type UserDB struct {
 	ModifiedAt time.Time     `db:"modified_at" json:"modifiedAt"`
 	Hash       Hash          `db:"hash" json:"-"`
 	Role       constant.Role `db:"role" json:"role"`
-	IsDeleted  bool          `db:"is_deleted" json:"-"`
+	IsDeleted  bool          `db:"is_deleted" json:"isDeleted"`
 }
 
 func NewUserDB(id UserID, login UserLogin, passwd *Password, role constant.Role) (u *UserDB, err error) {
@@ -51,6 +61,39 @@ func NewUserDB(id UserID, login UserLogin, passwd *Password, role constant.Role)
 	return
 }
 
+// InputUserList page of users ordered by login: Deleted - only deleted (not deleted by default),
+// Role - users having any of role bits, Login - part of login, Cursor - NextCursor of previous page
+type InputUserList struct {
+	Login   string        `json:"login" query:"login" validate:"max=250"`
+	Role    constant.Role `json:"role" query:"role" validate:"omitempty,gt=0"`
+	Deleted bool          `json:"deleted" query:"deleted"`
+	Limit   uint64        `json:"limit" query:"limit" validate:"omitempty,max=500"`
+	Cursor  string        `json:"cursor" query:"cursor"`
+}
+
+// UserCursor position after the last user of page
+type UserCursor struct {
+	Login UserLogin `json:"l"`
+}
+
+func (c *UserCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c *UserCursor) FromString(s string) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, c)
+}
+
+type UserPage struct {
+	Users      []UserDB `json:"users"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
 type Hash []byte
 
 func (h Hash) IsValidPassword(p Password) bool {
@@ -104,6 +147,6 @@ type UserNewForm struct {
 type UserChange struct {
 	Login    UserLogin     `json:"login" validate:"required,min=6"`
 	Password *Password     `json:"password,omitempty" validate:"omitempty,password"`
-	Role     constant.Role `json:"role" validate:"required,oneof=2 4"`
+	Role     constant.Role `json:"role" validate:"required,gt=0"`
 	ID       *UserID       `json:"id,omitempty" validate:"omitempty"`
 }
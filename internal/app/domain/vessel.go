@@ -1,10 +1,15 @@
 package domain
 
 import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"github.com/lib/pq"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 type VesselID int64
@@ -23,6 +28,35 @@ func (v *VesselID) SetFromStr(s string) (err error) {
 
 type VesselIDs []VesselID
 
+func (v VesselIDs) Value() (driver.Value, error) {
+	a := make(pq.Int64Array, 0, len(v))
+	for _, id := range v {
+		a = append(a, int64(id))
+	}
+	return a.Value()
+}
+
+func (v *VesselIDs) Scan(src interface{}) error {
+	var a pq.Int64Array
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*v = make(VesselIDs, 0, len(a))
+	for _, id := range a {
+		*v = append(*v, VesselID(id))
+	}
+	return nil
+}
+
+func (v VesselIDs) Contains(id VesselID) bool {
+	for _, i := range v {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
 type VesselName string
 
 func (v *VesselName) String() string {
@@ -32,6 +66,35 @@ func (v *VesselName) String() string {
 type Vessel struct {
 	ID   VesselID   `json:"id" db:"vessel_id"`
 	Name VesselName `json:"name" db:"vessel_name"`
+	VesselAttributes
+}
+
+// VesselAttributes optional registry attributes: flag - ISO 3166-1 alpha-2 code, length and beam in meters
+type VesselAttributes struct {
+	IMO      *string  `json:"imo,omitempty" db:"imo" validate:"omitempty,imo"`
+	MMSI     *string  `json:"mmsi,omitempty" db:"mmsi" validate:"omitempty,numeric,len=9"`
+	CallSign *string  `json:"callSign,omitempty" db:"call_sign" validate:"omitempty,alphanum,max=7"`
+	Flag     *string  `json:"flag,omitempty" db:"flag" validate:"omitempty,iso3166_1_alpha2"`
+	Type     *string  `json:"type,omitempty" db:"vessel_type" validate:"omitempty,max=50"`
+	Length   *float64 `json:"length,omitempty" db:"length" validate:"omitempty,gt=0,lte=500"`
+	Beam     *float64 `json:"beam,omitempty" db:"beam" validate:"omitempty,gt=0,lte=100"`
+}
+
+// IsIMO number of 7 digits, the last is check digit: sum of first six multiplied by 7..2, modulo 10
+func IsIMO(s string) bool {
+	if len(s) != 7 {
+		return false
+	}
+	sum := 0
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+		if i < 6 {
+			sum += int(r-'0') * (7 - i)
+		}
+	}
+	return sum%10 == int(s[6]-'0')
 }
 
 func (v *Vessel) String() string {
@@ -105,7 +168,106 @@ func (v *Vessels) IDs() (ids []VesselID) {
 	return
 }
 
+// InputVesselSearch full-text query by name and attributes (word prefixes), filters by attributes
+type InputVesselSearch struct {
+	Query     string   `json:"q" query:"q"`
+	IMO       string   `json:"imo" query:"imo"`
+	MMSI      string   `json:"mmsi" query:"mmsi"`
+	CallSign  string   `json:"callSign" query:"callSign"`
+	Flag      string   `json:"flag" query:"flag"`
+	Type      string   `json:"type" query:"type"`
+	LengthMin *float64 `json:"lengthMin" query:"lengthMin"`
+	LengthMax *float64 `json:"lengthMax" query:"lengthMax"`
+}
+
+// TSQuery prefix match of all words of query, empty if no words
+func (in *InputVesselSearch) TSQuery() string {
+	words := strings.FieldsFunc(strings.ToLower(in.Query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i := range words {
+		words[i] += ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// VesselNameRecord name of vessel valid from ValidFrom till next record, ValidFrom is empty for the first name
+type VesselNameRecord struct {
+	Name      VesselName `json:"name" db:"name"`
+	ValidFrom *time.Time `json:"validFrom" db:"valid_from"`
+}
+
+// InputRename effective time of renaming, now if empty
+type InputRename struct {
+	RenamedAt *time.Time `json:"renamedAt" query:"renamedAt"`
+}
+
 type VesselInfo struct {
 	Vessel
 	CreatedAt *time.Time `db:"created_at" json:"createdAt"`
+	LastSeen  *time.Time `db:"last_seen" json:"lastSeen"`
+	IsDeleted bool       `db:"is_deleted" json:"isDeleted"`
+	Monitored bool       `db:"monitored" json:"monitored"`
+}
+
+const (
+	VesselSortName     = "name"
+	VesselSortCreated  = "createdAt"
+	VesselSortLastSeen = "lastSeen"
+
+	VesselMatchContains = "contains"
+	VesselMatchPrefix   = "prefix"
+)
+
+// InputVesselList page of vessels: Deleted - only deleted (not deleted by default), Monitored - filter by monitoring,
+// Name matched by Match (substring by default), Cursor - NextCursor of previous page with the same sort
+type InputVesselList struct {
+	Name      string `json:"name" query:"name" validate:"max=250"`
+	Match     string `json:"match" query:"match" validate:"omitempty,oneof=contains prefix"`
+	Deleted   bool   `json:"deleted" query:"deleted"`
+	Monitored *bool  `json:"monitored" query:"monitored"`
+	Sort      string `json:"sort" query:"sort" validate:"omitempty,oneof=name createdAt lastSeen"`
+	Desc      bool   `json:"desc" query:"desc"`
+	Limit     uint64 `json:"limit" query:"limit" validate:"omitempty,max=500"`
+	Cursor    string `json:"cursor" query:"cursor"`
+}
+
+// VesselCursor position after the last vessel of page: sort key and ID
+type VesselCursor struct {
+	Sort string     `json:"s"`
+	Desc bool       `json:"d,omitempty"`
+	Name string     `json:"n,omitempty"`
+	Time *time.Time `json:"t,omitempty"`
+	ID   VesselID   `json:"id"`
+}
+
+func NewVesselCursor(sort string, desc bool, last *VesselInfo) *VesselCursor {
+	cursor := &VesselCursor{Sort: sort, Desc: desc, ID: last.ID}
+	switch sort {
+	case VesselSortCreated:
+		cursor.Time = last.CreatedAt
+	case VesselSortLastSeen:
+		cursor.Time = last.LastSeen
+	default:
+		cursor.Name = string(last.Name)
+	}
+	return cursor
+}
+
+func (c *VesselCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (c *VesselCursor) FromString(s string) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, c)
+}
+
+type VesselPage struct {
+	Vessels    []VesselInfo `json:"vessels"`
+	NextCursor string       `json:"nextCursor,omitempty"`
 }
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+type WatchlistID int64
+
+func (v *WatchlistID) SetFromStr(s string) (err error) {
+	var f int64
+	if f, err = strconv.ParseInt(s, 10, 64); err == nil {
+		*v = WatchlistID(f)
+	}
+	return
+}
+
+type UserIDs []UserID
+
+func (v UserIDs) Value() (driver.Value, error) {
+	a := make(pq.Int64Array, 0, len(v))
+	for _, id := range v {
+		a = append(a, int64(id))
+	}
+	return a.Value()
+}
+
+func (v *UserIDs) Scan(src interface{}) error {
+	var a pq.Int64Array
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*v = make(UserIDs, 0, len(a))
+	for _, id := range a {
+		*v = append(*v, UserID(id))
+	}
+	return nil
+}
+
+// Watchlist vessels monitored for owner and members.
+// Personal list of operator is created on first use, vessel is monitored while any list has it
+type Watchlist struct {
+	ID        WatchlistID `json:"id" db:"id"`
+	Name      string      `json:"name" db:"name" validate:"required,max=100"`
+	OwnerID   UserID      `json:"ownerID" db:"owner_id"`
+	Personal  bool        `json:"personal" db:"personal"`
+	Members   UserIDs     `json:"members" db:"members" validate:"dive,gt=0"`
+	VesselIDs VesselIDs   `json:"vesselIDs" db:"vessel_ids"`
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// InputWatchlist watchlist of operator, personal if empty
+type InputWatchlist struct {
+	WatchlistID WatchlistID `json:"watchlist" query:"watchlist"`
+}
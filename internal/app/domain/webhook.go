@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"github.com/lib/pq"
+	"strconv"
+	"time"
+)
+
+type WebhookID int64
+
+func (v *WebhookID) SetFromStr(s string) (err error) {
+	var f int64
+	if f, err = strconv.ParseInt(s, 10, 64); err == nil {
+		*v = WebhookID(f)
+	}
+	return
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventControlSet   WebhookEvent = "controlSet"
+	WebhookEventControlUnset WebhookEvent = "controlUnset"
+	WebhookEventZoneEnter    WebhookEvent = "zoneEnter"
+	WebhookEventZoneExit     WebhookEvent = "zoneExit"
+	WebhookEventVesselLost   WebhookEvent = "vesselLost"
+)
+
+type WebhookEvents []WebhookEvent
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	a := make(pq.StringArray, 0, len(e))
+	for _, event := range e {
+		a = append(a, string(event))
+	}
+	return a.Value()
+}
+
+func (e *WebhookEvents) Scan(src interface{}) error {
+	var a pq.StringArray
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*e = make(WebhookEvents, 0, len(a))
+	for _, event := range a {
+		*e = append(*e, WebhookEvent(event))
+	}
+	return nil
+}
+
+// Webhook subscription, empty Events - all events. Secret is shown only on create
+type Webhook struct {
+	ID        WebhookID     `json:"id" db:"id"`
+	URL       string        `json:"url" db:"url" validate:"required,url,startswith=http"`
+	Secret    string        `json:"secret,omitempty" db:"secret" validate:"omitempty,min=16,max=128"`
+	Events    WebhookEvents `json:"events" db:"events" validate:"dive,oneof=controlSet controlUnset zoneEnter zoneExit vesselLost"`
+	CreatedAt time.Time     `json:"createdAt" db:"created_at"`
+}
+
+// WebhookPayload body of webhook request
+type WebhookPayload struct {
+	Event     WebhookEvent `json:"event"`
+	Timestamp time.Time    `json:"timestamp"`
+	Vessel    Vessel       `json:"vessel"`
+	Zone      *ZoneName    `json:"zone,omitempty"`
+	Location  *Point       `json:"location,omitempty"`
+}
+
+// WebhookMessage outbox item ready to delivery
+type WebhookMessage struct {
+	ID        int64        `db:"id"`
+	WebhookID WebhookID    `db:"webhook_id"`
+	URL       string       `db:"url"`
+	Secret    string       `db:"secret"`
+	Event     WebhookEvent `db:"event"`
+	Payload   []byte       `db:"payload"`
+	Attempts  int          `db:"attempts"`
+}
+
+type WebhookDelivery struct {
+	ID         int64        `json:"id" db:"id"`
+	OutboxID   int64        `json:"outboxID" db:"outbox_id"`
+	WebhookID  WebhookID    `json:"webhookID" db:"webhook_id"`
+	Event      WebhookEvent `json:"event" db:"event"`
+	Attempt    int          `json:"attempt" db:"attempt"`
+	Timestamp  time.Time    `json:"timestamp" db:"timestamp"`
+	StatusCode *int         `json:"statusCode" db:"status_code"`
+	Error      *string      `json:"error" db:"error"`
+	DurationMs int64        `json:"durationMs" db:"duration_ms"`
+}
+
+// WebhookSignature hex HMAC-SHA256 of "timestamp.body" with subscription secret
+func WebhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -1,3 +1,39 @@
 package domain
 
+import (
+	"database/sql/driver"
+	"github.com/lib/pq"
+)
+
 type ZoneName string
+
+type ZoneNames []ZoneName
+
+func (z ZoneNames) Value() (driver.Value, error) {
+	a := make(pq.StringArray, 0, len(z))
+	for _, name := range z {
+		a = append(a, string(name))
+	}
+	return a.Value()
+}
+
+func (z *ZoneNames) Scan(src interface{}) error {
+	var a pq.StringArray
+	if err := a.Scan(src); err != nil {
+		return err
+	}
+	*z = make(ZoneNames, 0, len(a))
+	for _, name := range a {
+		*z = append(*z, ZoneName(name))
+	}
+	return nil
+}
+
+func (z ZoneNames) Contains(name ZoneName) bool {
+	for _, n := range z {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
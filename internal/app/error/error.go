@@ -8,6 +8,12 @@ var (
 	ErrNotExist           = errors.New("not exist")
 	ErrNotControlled      = errors.New("not controlled")
 	ErrLocationOutOfRange = errors.New("location out of range")
+	ErrTimestampInFuture  = errors.New("timestamp in future")
 	ErrDuplicateRecord    = errors.New("duplicate record")
 	ErrLogin              = errors.New("bad pair login/password")
+	ErrRefreshToken       = errors.New("refresh token invalid or expired")
+	ErrRefreshReuse       = errors.New("refresh token reused, session revoked")
+	ErrResumeExpired      = errors.New("resume token expired, reload states")
+	ErrRoleInUse          = errors.New("role is builtin or assigned to users or api keys")
+	ErrAPIKey             = errors.New("api key invalid, expired or revoked")
 )
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// Alerts
+// @Tags        Alert
+// @Summary     Список оповещений
+// @Description о входе, выходе судов из карт или нахождении в карте дольше заданного в правиле времени.
+// @Description Пользователю с ограничением - только по судам его флота в его зонах
+// @Accept      json
+// @Produce     json
+// @Param       InputAlerts   query    domain.InputAlerts    false "фильтр: суда (vesselIDs, groupIDs), период, подтверждённые (acknowledged)"
+// @Success     200           {object} []domain.Alert
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /alerts [get]
+// @Security    BearerAuth
+func (h *Handler) Alerts() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputAlerts
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		if !h.expandGroups(ctx, c, &query.InputVessels) {
+			return nil
+		}
+		if query.NoVessels() {
+			return c.Status(http.StatusOK).JSON([]domain.Alert{})
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+
+		result, err := h.s.Alert.Alerts(ctx, scope, query)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get alerts", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AcknowledgeAlerts
+// @Tags        Alert
+// @Summary     Подтверждение оповещений
+// @Description оператором из токена
+// @Accept      json
+// @Produce     json
+// @Param       AlertIDs   body     []domain.AlertID    true "список ID оповещений"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /alerts [patch]
+// @Security    BearerAuth
+func (h *Handler) AcknowledgeAlerts() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			AlertIDs []domain.AlertID
+		)
+		err = c.BodyParser(&AlertIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(AlertIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Alert.AcknowledgeAlerts(ctx, GetUserID(c), AlertIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error acknowledge alerts", zap.Error(err), zap.Any("ids", AlertIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// AlertRules
+// @Tags        Alert
+// @Summary     Правила оповещений
+// @Description
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.AlertRule
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /alerts/rules [get]
+// @Security    BearerAuth
+func (h *Handler) AlertRules() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Alert.AlertRules(ctx)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get alert rules", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddAlertRule
+// @Tags        Alert
+// @Summary     Добавление правила оповещения
+// @Description Событие event: enter - вход в карту, exit - выход, dwell - нахождение в карте дольше dwellMinutes.
+// @Description Пустые vesselIDs, zoneNames - любое судно на мониторинге, любая карта
+// @Accept      json
+// @Produce     json
+// @Param       AlertRule  body      domain.AlertRule    true "правило"
+// @Success     201        {integer} domain.AlertRuleID
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /alerts/rules [post]
+// @Security    BearerAuth
+func (h *Handler) AddAlertRule() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			rule domain.AlertRule
+		)
+		err = c.BodyParser(&rule)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Alert.AddAlertRule(ctx, &rule)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add alert rule", zap.Error(err), zap.Any("rule", rule))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateAlertRule
+// @Tags        Alert
+// @Summary     Изменение правила оповещения
+// @Description
+// @Accept      json
+// @Produce     json
+// @Param       AlertRule  body     domain.AlertRule    true "правило"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /alerts/rules [put]
+// @Security    BearerAuth
+func (h *Handler) UpdateAlertRule() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			rule domain.AlertRule
+		)
+		err = c.BodyParser(&rule)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Alert.UpdateAlertRule(ctx, &rule)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error update alert rule", zap.Error(err), zap.Any("rule", rule))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// DeleteAlertRules
+// @Tags        Alert
+// @Summary     Удаление правил оповещения
+// @Description
+// @Accept      json
+// @Produce     json
+// @Param       AlertRuleIDs   body     []domain.AlertRuleID    true "список ID правил"
+// @Success     200            {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /alerts/rules [delete]
+// @Security    BearerAuth
+func (h *Handler) DeleteAlertRules() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			RuleIDs []domain.AlertRuleID
+		)
+		err = c.BodyParser(&RuleIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(RuleIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Alert.DeleteAlertRules(ctx, RuleIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error delete alert rules", zap.Error(err), zap.Any("ids", RuleIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
@@ -0,0 +1,282 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func (suite *HandlerTestSuite) TestAddAlertRule() {
+	t := suite.T()
+
+	type want struct {
+		code            int
+		responseLen     *bool
+		response        *string
+		responseContain string
+		contentType     string
+	}
+	type args struct {
+		method  string
+		body    interface{}
+		headers map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "Add alert rule. No jwt",
+			args: args{
+				method: http.MethodPost,
+				body: domain.AlertRule{
+					Name:  "Enter zone",
+					Event: domain.AlertEventEnter,
+				},
+			},
+			want: want{
+				code:        http.StatusUnauthorized,
+				response:    &[]string{"Missing or malformed JWT"}[0],
+				contentType: "text/plain",
+			},
+		},
+		{
+			name: "Add alert rule. Wrong role in jwt",
+			args: args{
+				method: http.MethodPost,
+				body: domain.AlertRule{
+					Name:  "Enter zone",
+					Event: domain.AlertEventEnter,
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtVessel,
+				},
+			},
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
+		{
+			name: "Add alert rule. OK",
+			args: args{
+				method: http.MethodPost,
+				body: domain.AlertRule{
+					Name:      "Enter zone",
+					VesselIDs: domain.VesselIDs{suite.cfg.VesselID},
+					ZoneNames: domain.ZoneNames{suite.cfg.ZoneName},
+					Event:     domain.AlertEventEnter,
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:        http.StatusCreated,
+				responseLen: &[]bool{true}[0],
+				contentType: "application/json",
+			},
+		},
+		{
+			name: "Add alert rule. Validate. Dwell without minutes",
+			args: args{
+				method: http.MethodPost,
+				body: domain.AlertRule{
+					Name:  "Dwell zone",
+					Event: domain.AlertEventDwell,
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:            http.StatusBadRequest,
+				responseContain: "DwellMinutes",
+			},
+		},
+		{
+			name: "Add alert rule. Validate. Unknown event",
+			args: args{
+				method: http.MethodPost,
+				body: domain.AlertRule{
+					Name:  "Unknown",
+					Event: "pass",
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:            http.StatusBadRequest,
+				responseContain: "Event",
+			},
+		},
+		{
+			name: "Add alert rule. Bad body data",
+			args: args{
+				method: http.MethodPost,
+				body:   []interface{}{12.12, "rule"},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			bodyJSON, _ := json.Marshal(test.args.body)
+			request, err := http.NewRequest(test.args.method, constant.RouteAPI+constant.RouteAlerts+constant.RouteRules, bytes.NewReader(bodyJSON))
+			require.NoError(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			if len(test.args.headers) > 0 {
+				for k, v := range test.args.headers {
+					request.Header.Set(k, v)
+				}
+			}
+
+			res, err := suite.app.Test(request)
+			require.NoError(t, err)
+
+			var resBody []byte
+			assert.Equal(t, test.want.code, res.StatusCode)
+			func() {
+				defer func(Body io.ReadCloser) {
+					err := Body.Close()
+					require.NoError(t, err)
+				}(res.Body)
+				resBody, err = io.ReadAll(res.Body)
+				require.NoError(t, err)
+			}()
+
+			if strings.Contains(test.want.contentType, "application/json") {
+				var data domain.AlertRuleID
+				err = json.Unmarshal(resBody, &data)
+				require.NoError(t, err)
+				assert.Greater(t, int64(data), int64(0))
+			}
+
+			if test.want.responseLen != nil {
+				if *test.want.responseLen {
+					assert.Greater(t, len(resBody), 0)
+				} else {
+					assert.Equal(t, len(resBody), 0)
+				}
+			}
+
+			if test.want.contentType != "" {
+				assert.Contains(t, res.Header.Get("Content-Type"), test.want.contentType)
+			}
+
+			if test.want.responseContain != "" {
+				cont := string(resBody)
+				assert.Contains(t, cont, test.want.responseContain)
+			}
+
+			if test.want.response != nil {
+				cont := strings.TrimSpace(string(resBody))
+				assert.Equal(t, cont, *test.want.response)
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestAlerts() {
+	t := suite.T()
+	ctx := context.Background()
+
+	ruleID, err := suite.srv.AddAlertRule(ctx, &domain.AlertRule{
+		Name:      "Enter test zone",
+		VesselIDs: domain.VesselIDs{suite.cfg.VesselID},
+		ZoneNames: domain.ZoneNames{suite.cfg.ZoneName},
+		Event:     domain.AlertEventEnter,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, suite.cfg.VesselID))
+	// outside, then inside of test zone
+	require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{12.12, 12.12}))
+	require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+
+	getAlerts := func(t *testing.T, query string, headers map[string]string) (code int, alerts []domain.Alert) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteAlerts+query, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&alerts))
+		}
+		return res.StatusCode, alerts
+	}
+	operator := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}
+
+	t.Run("Alerts. No jwt", func(t *testing.T) {
+		code, _ := getAlerts(t, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Alerts. Wrong role in jwt", func(t *testing.T) {
+		code, _ := getAlerts(t, "", map[string]string{"Authorization": "Bearer " + suite.cfg.jwtVessel})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	var alertID domain.AlertID
+	t.Run("Alerts. Enter raised", func(t *testing.T) {
+		code, alerts := getAlerts(t, "?acknowledged=false&vesselIDs="+suite.cfg.VesselID.String(), operator)
+		require.Equal(t, http.StatusOK, code)
+		for _, alert := range alerts {
+			if alert.RuleID == ruleID {
+				assert.Equal(t, domain.AlertEventEnter, alert.Event)
+				assert.Equal(t, suite.cfg.ZoneName, alert.ZoneName)
+				alertID = alert.ID
+			}
+		}
+		require.NotZero(t, alertID)
+	})
+
+	t.Run("Alerts. Acknowledge", func(t *testing.T) {
+		bodyJSON, _ := json.Marshal([]domain.AlertID{alertID})
+		request, err := http.NewRequest(http.MethodPatch, constant.RouteAPI+constant.RouteAlerts, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		code, alerts := getAlerts(t, "?acknowledged=true&vesselIDs="+suite.cfg.VesselID.String(), operator)
+		require.Equal(t, http.StatusOK, code)
+		found := false
+		for _, alert := range alerts {
+			if alert.ID == alertID {
+				found = true
+				assert.NotNil(t, alert.AcknowledgedAt)
+				require.NotNil(t, alert.AcknowledgedBy)
+				assert.Equal(t, domain.UserID(12), *alert.AcknowledgedBy)
+			}
+		}
+		assert.True(t, found)
+	})
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// APIKeys
+// @Tags        APIKey
+// @Summary     Действующие API ключи
+// @Description не отозванные, без самих ключей: prefix - начало ключа
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.APIKey
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /keys [get]
+// @Security    BearerAuth
+func (h *Handler) APIKeys() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.APIKey.APIKeys(ctx)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get api keys", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddAPIKey
+// @Tags        APIKey
+// @Summary     Создание API ключа
+// @Description Ключ для интеграций, передается в заголовке X-API-Key вместо токена. Запросы выполняются от имени
+// @Description пользователя userID с ролью role и ограничением zoneNames, vesselIDs, groupIDs ключа.
+// @Description Роль ключа должна входить в роли пользователя, при запросе учитываются текущие роли и ограничение пользователя.
+// @Description Ограничение ключа сужается ограничением пользователя: зоны - общие, без флота ключ наследует флот пользователя.
+// @Description expiresAt - необязательный срок действия. Ключ возвращается только в ответе, хранится хэш
+// @Accept      json
+// @Produce     json
+// @Param       APIKey  body      domain.APIKey    true "ключ"
+// @Success     201     {object}  domain.APIKeySecret
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404     {string} string "пользователь не найден"
+// @Failure     500
+// @Router      /keys [post]
+// @Security    BearerAuth
+func (h *Handler) AddAPIKey() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			key domain.APIKey
+		)
+		err = c.BodyParser(&key)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		key.CreatedBy = GetUserID(c)
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.APIKey.AddAPIKey(ctx, key)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add api key", zap.Error(err), zap.Any("key", key))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// RotateAPIKey
+// @Tags        APIKey
+// @Summary     Замена API ключа
+// @Description новый ключ с теми же ролью и ограничением, прежний ключ действует еще сутки
+// @Accept      json
+// @Produce     json
+// @Param       id    path     integer    true "ID ключа"
+// @Success     200   {object} domain.APIKeySecret
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /keys/{id}/rotate [post]
+// @Security    BearerAuth
+func (h *Handler) RotateAPIKey() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.APIKeyID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.APIKey.RotateAPIKey(ctx, id)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error rotate api key", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// RevokeAPIKeys
+// @Tags        APIKey
+// @Summary     Отзыв API ключей
+// @Description отозванный ключ (и прежний ключ после замены) отклоняется сразу
+// @Accept      json
+// @Produce     json
+// @Param       KeyIDs   body     []domain.APIKeyID    true "список ID ключей"
+// @Success     200      {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404      "нет действующих ключей"
+// @Failure     500
+// @Router      /keys [delete]
+// @Security    BearerAuth
+func (h *Handler) RevokeAPIKeys() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			KeyIDs []domain.APIKeyID
+		)
+		err = c.BodyParser(&KeyIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(KeyIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.APIKey.RevokeAPIKeys(ctx, KeyIDs...)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error revoke api keys", zap.Error(err), zap.Any("ids", KeyIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
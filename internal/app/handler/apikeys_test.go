@@ -0,0 +1,161 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestAPIKeys() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	password := domain.Password("Pa$$w0rd")
+	userID, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: domain.UserLogin("Test_api_key_" + uniq), Password: &password, Role: constant.RoleOperator | constant.RoleAnalyst})
+	require.NoError(t, err)
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Key fleet "+uniq), domain.VesselName("Key other "+uniq))
+	require.NoError(t, err)
+	require.Len(t, vessels, 2)
+	fleet, other := vessels[0].ID, vessels[1].ID
+
+	send := func(t *testing.T, headers map[string]string, method, route string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	admin := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtAdmin}
+	withKey := func(key string) map[string]string {
+		return map[string]string{constant.HeaderAPIKey: key}
+	}
+
+	var secret domain.APIKeySecret
+	t.Run("API keys. Add", func(t *testing.T) {
+		code, _ := send(t, map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}, http.MethodPost, constant.RouteKeys,
+			domain.APIKey{Name: "Operator " + uniq, Role: constant.RoleAnalyst, UserScope: domain.UserScope{UserID: userID}})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, admin, http.MethodPost, constant.RouteKeys,
+			domain.APIKey{Name: "Vessel role " + uniq, Role: constant.RoleVessel, UserScope: domain.UserScope{UserID: userID}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = send(t, admin, http.MethodPost, constant.RouteKeys,
+			domain.APIKey{Name: "Admin role " + uniq, Role: constant.RoleAdmin, UserScope: domain.UserScope{UserID: userID}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = send(t, admin, http.MethodPost, constant.RouteKeys,
+			domain.APIKey{Name: "No user " + uniq, Role: constant.RoleAnalyst, UserScope: domain.UserScope{UserID: 100500000}})
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, resBody := send(t, admin, http.MethodPost, constant.RouteKeys, domain.APIKey{Name: "Integration " + uniq, Role: constant.RoleAnalyst,
+			UserScope: domain.UserScope{UserID: userID, VesselIDs: domain.VesselIDs{fleet}}})
+		require.Equal(t, http.StatusCreated, code)
+		require.NoError(t, json.Unmarshal(resBody, &secret))
+		assert.True(t, strings.HasPrefix(secret.Key, constant.APIKeyPrefix))
+		assert.Equal(t, secret.Key[:constant.APIKeyShownLen], secret.Prefix)
+		assert.Equal(t, domain.UserID(1), secret.CreatedBy)
+
+		code, resBody = send(t, admin, http.MethodGet, constant.RouteKeys, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.NotContains(t, string(resBody), secret.Key)
+		var keys []domain.APIKey
+		require.NoError(t, json.Unmarshal(resBody, &keys))
+		found := false
+		for _, key := range keys {
+			found = found || key.ID == secret.ID
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("API keys. Access by key", func(t *testing.T) {
+		code, _ := send(t, withKey(secret.Key), http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = send(t, withKey(secret.Key), http.MethodPost, constant.RouteMonitor, []domain.VesselID{fleet})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, withKey(secret.Key), http.MethodGet, constant.RouteTrack+"/"+fleet.String(), nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = send(t, withKey(secret.Key), http.MethodGet, constant.RouteTrack+"/"+other.String(), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, withKey(secret.Key+"x"), http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("API keys. Scope of user", func(t *testing.T) {
+		require.NoError(t, suite.srv.Scope.SetUserScope(ctx, &domain.UserScope{UserID: userID, ZoneNames: domain.ZoneNames{suite.cfg.ZoneName}, VesselIDs: domain.VesselIDs{fleet}}))
+		defer func() {
+			require.NoError(t, suite.srv.Scope.SetUserScope(ctx, &domain.UserScope{UserID: userID}))
+		}()
+		code, _ := send(t, admin, http.MethodPost, constant.RouteKeys, domain.APIKey{Name: "Other zone " + uniq, Role: constant.RoleAnalyst,
+			UserScope: domain.UserScope{UserID: userID, ZoneNames: domain.ZoneNames{"zone_" + domain.ZoneName(uniq)}}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, resBody := send(t, admin, http.MethodPost, constant.RouteKeys, domain.APIKey{Name: "Unrestricted " + uniq, Role: constant.RoleAnalyst,
+			UserScope: domain.UserScope{UserID: userID}})
+		require.Equal(t, http.StatusCreated, code)
+		var unrestricted domain.APIKeySecret
+		require.NoError(t, json.Unmarshal(resBody, &unrestricted))
+		assert.Equal(t, domain.ZoneNames{suite.cfg.ZoneName}, unrestricted.ZoneNames)
+		assert.Equal(t, domain.VesselIDs{fleet}, unrestricted.VesselIDs)
+
+		code, _ = send(t, withKey(secret.Key), http.MethodGet, constant.RouteTrack+"/"+other.String(), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		require.NoError(t, suite.srv.Scope.SetUserScope(ctx, &domain.UserScope{UserID: userID, VesselIDs: domain.VesselIDs{other}}))
+		code, _ = send(t, withKey(secret.Key), http.MethodGet, constant.RouteTrack+"/"+fleet.String(), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		login := domain.UserLogin("Test_api_key_" + uniq)
+		require.NoError(t, suite.srv.UpdateUser(ctx, &domain.UserChange{ID: &userID, Login: login, Role: constant.RoleOperator}))
+		code, _ = send(t, withKey(secret.Key), http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		require.NoError(t, suite.srv.UpdateUser(ctx, &domain.UserChange{ID: &userID, Login: login, Role: constant.RoleOperator | constant.RoleAnalyst}))
+	})
+
+	t.Run("API keys. Rotate and revoke", func(t *testing.T) {
+		code, resBody := send(t, admin, http.MethodPost, constant.RouteKeys+"/"+strconv.FormatInt(int64(secret.ID), 10)+constant.RouteRotate, nil)
+		require.Equal(t, http.StatusOK, code)
+		var rotated domain.APIKeySecret
+		require.NoError(t, json.Unmarshal(resBody, &rotated))
+		assert.Equal(t, secret.ID, rotated.ID)
+		assert.NotEqual(t, secret.Key, rotated.Key)
+		assert.NotNil(t, rotated.PreviousExpiresAt)
+		for _, key := range []string{secret.Key, rotated.Key} {
+			code, _ = send(t, withKey(key), http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+			assert.Equal(t, http.StatusOK, code)
+		}
+
+		code, _ = send(t, admin, http.MethodDelete, constant.RouteKeys, []domain.APIKeyID{secret.ID})
+		require.Equal(t, http.StatusOK, code)
+		for _, key := range []string{secret.Key, rotated.Key} {
+			code, _ = send(t, withKey(key), http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+			assert.Equal(t, http.StatusUnauthorized, code)
+		}
+		code, _ = send(t, admin, http.MethodDelete, constant.RouteKeys, []domain.APIKeyID{secret.ID})
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, admin, http.MethodPost, constant.RouteKeys+"/"+strconv.FormatInt(int64(secret.ID), 10)+constant.RouteRotate, nil)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
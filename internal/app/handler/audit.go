@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"charts_analyser/internal/app/service"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+// GetAuditWare audit of successful mutating calls. Changed rows are recorded by repository in transaction of
+// the change through request context, call without changed rows is recorded here. Calls to skip paths
+// are not recorded: they do not change data (POST queries of charts and states) or are a log themselves -
+// track points of vessels come every few seconds and are kept in tracks with vessel and time.
+// Calls without token (login, refresh, logout) are recorded on behalf of user set by service, see domain.AuditUser
+func GetAuditWare(audit service.Audit, log *zap.Logger, skip ...string) fiber.Handler {
+	skipPaths := make(map[string]struct{}, len(skip))
+	for _, path := range skip {
+		skipPaths[path] = struct{}{}
+	}
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+		path := strings.TrimSuffix(c.Path(), "/")
+		if _, ok := skipPaths[path]; ok {
+			return c.Next()
+		}
+		req := &domain.AuditRequest{Method: c.Method(), Path: path}
+		if userID := GetUserID(c); userID > 0 {
+			req.UserID = &userID
+		}
+		if apiKey := GetAPIKey(c); apiKey != nil {
+			req.APIKeyID = &apiKey.ID
+		}
+		c.Locals(constant.CtxAudit, req)
+
+		err := c.Next()
+		if status := c.Response().StatusCode(); err != nil || status < http.StatusOK || status >= http.StatusMultipleChoices || req.Recorded {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		if err = audit.AddAudit(ctx, req); err != nil {
+			log.Error("AddAudit", zap.Error(err), zap.Any("request", req))
+		}
+		return nil
+	}
+}
+
+// Audit
+// @Tags        Audit
+// @Summary     Журнал изменений
+// @Description успешные POST/PUT/PATCH/DELETE запросы к /api, новые первыми: кто (userID, apiKeyID), запрос (method, path),
+// @Description таблица target, ID ее строк targetIDs и строки до (before) и после (after) изменения, секреты не сохраняются.
+// @Description Запрос, изменивший несколько таблиц, - запись на каждую, не изменивший ничего - запись без target.
+// @Description Вход, обновление токена и выход записываются без target от имени пользователя, треки судов не записываются.
+// @Description Фильтры: userIDs, apiKeyID, method, path - начало пути, target, targetID, период.
+// @Description Следующая страница - cursor=nextCursor с теми же фильтрами
+// @Accept      json
+// @Produce     json
+// @Param       InputAudit query    domain.InputAudit false "фильтры и страница"
+// @Success     200        {object} domain.AuditPage
+// @Failure     400        {string} string "ошибка валидации"
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /audit [get]
+// @Security    BearerAuth
+func (h *Handler) Audit() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputAudit
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Audit.Audit(ctx, query)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error audit", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
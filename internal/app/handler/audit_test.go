@@ -0,0 +1,182 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestAudit() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	send := func(t *testing.T, jwt, method, route string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	audit := func(t *testing.T, query url.Values) (page domain.AuditPage) {
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodGet, constant.RouteAudit+"?"+query.Encode(), nil)
+		require.Equal(t, http.StatusOK, code, string(resBody))
+		require.NoError(t, json.Unmarshal(resBody, &page))
+		return
+	}
+	rows := func(t *testing.T, r domain.AuditRows) (result []map[string]interface{}) {
+		require.NoError(t, json.Unmarshal(r, &result))
+		return
+	}
+
+	var vessel domain.Vessel
+	t.Run("Audit. Vessel changes", func(t *testing.T) {
+		code, resBody := send(t, suite.cfg.jwtOperator, http.MethodPost, constant.RouteVessels, []domain.VesselName{domain.VesselName("Audit " + uniq)})
+		require.Equal(t, http.StatusCreated, code)
+		var vessels []domain.Vessel
+		require.NoError(t, json.Unmarshal(resBody, &vessels))
+		require.Len(t, vessels, 1)
+		vessel = vessels[0]
+		code, _ = send(t, suite.cfg.jwtOperator, http.MethodPut, constant.RouteVessels, []domain.Vessel{{ID: vessel.ID, Name: domain.VesselName("Audit renamed " + uniq)}})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, suite.cfg.jwtOperator, http.MethodDelete, constant.RouteVessels, []domain.VesselID{vessel.ID})
+		require.Equal(t, http.StatusOK, code)
+
+		page := audit(t, url.Values{"target": {constant.DBVessels}, "targetID": {vessel.ID.String()}})
+		require.Len(t, page.Records, 3)
+		for i, method := range []string{http.MethodDelete, http.MethodPut, http.MethodPost} {
+			record := page.Records[i]
+			assert.Equal(t, method, record.Method)
+			assert.Equal(t, constant.RouteAPI+constant.RouteVessels, record.Path)
+			require.NotNil(t, record.UserID)
+			assert.Equal(t, domain.UserID(12), *record.UserID)
+			assert.Equal(t, []string{vessel.ID.String()}, []string(record.TargetIDs))
+		}
+		assert.Equal(t, true, rows(t, page.Records[0].After)[0]["is_deleted"])
+		assert.Equal(t, "Audit "+uniq, rows(t, page.Records[1].Before)[0]["name"])
+		assert.Equal(t, "Audit renamed "+uniq, rows(t, page.Records[1].After)[0]["name"])
+		assert.Empty(t, rows(t, page.Records[2].Before))
+
+		page = audit(t, url.Values{"target": {constant.DBVessels}, "targetID": {vessel.ID.String()}, "limit": {"2"}})
+		require.Len(t, page.Records, 2)
+		require.NotEmpty(t, page.NextCursor)
+		page = audit(t, url.Values{"target": {constant.DBVessels}, "targetID": {vessel.ID.String()}, "limit": {"2"}, "cursor": {page.NextCursor}})
+		require.Len(t, page.Records, 1)
+		assert.Equal(t, http.MethodPost, page.Records[0].Method)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Audit. User role without secrets", func(t *testing.T) {
+		password := domain.Password("Pa$$w0rd")
+		login := domain.UserLogin("Test_audit_" + uniq)
+		userID, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: login, Password: &password, Role: constant.RoleOperator})
+		require.NoError(t, err)
+		code, _ := send(t, suite.cfg.jwtAdmin, http.MethodPut, constant.RouteUser, domain.UserChange{ID: &userID, Login: login, Role: constant.RoleAnalyst})
+		require.Equal(t, http.StatusOK, code)
+
+		page := audit(t, url.Values{"target": {constant.DBUsers}, "targetID": {userID.String()}})
+		require.Len(t, page.Records, 1)
+		require.NotNil(t, page.Records[0].UserID)
+		assert.Equal(t, domain.UserID(1), *page.Records[0].UserID)
+		before, after := rows(t, page.Records[0].Before), rows(t, page.Records[0].After)
+		assert.EqualValues(t, constant.RoleOperator, before[0]["role"])
+		assert.EqualValues(t, constant.RoleAnalyst, after[0]["role"])
+		assert.NotContains(t, after[0], "hash")
+	})
+
+	t.Run("Audit. Calls without changes and failed calls", func(t *testing.T) {
+		last := audit(t, url.Values{"method": {http.MethodPut}, "path": {constant.RouteAPI + constant.RouteVessels}, "limit": {"1"}})
+		require.Len(t, last.Records, 1)
+		code, _ := send(t, suite.cfg.jwtOperator, http.MethodPut, constant.RouteVessels, "wrong")
+		require.Equal(t, http.StatusBadRequest, code)
+		page := audit(t, url.Values{"method": {http.MethodPut}, "path": {constant.RouteAPI + constant.RouteVessels}, "limit": {"1"}})
+		require.Len(t, page.Records, 1)
+		assert.Equal(t, last.Records[0].ID, page.Records[0].ID)
+
+		code, _ = send(t, suite.cfg.jwtOperator, http.MethodDelete, constant.RouteVessels, []domain.VesselID{100500000})
+		require.Equal(t, http.StatusOK, code)
+		page = audit(t, url.Values{"method": {http.MethodDelete}, "path": {constant.RouteAPI + constant.RouteVessels}, "userIDs": {"12"}, "limit": {"1"}})
+		require.Len(t, page.Records, 1)
+		assert.Nil(t, page.Records[0].Target)
+		assert.Empty(t, page.Records[0].TargetIDs)
+		assert.Nil(t, page.Records[0].After)
+	})
+
+	t.Run("Audit. Login, refresh and logout", func(t *testing.T) {
+		password := domain.Password("Pa$$w0rd")
+		login := domain.UserLogin("Test_audit_login_" + uniq)
+		userID, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: login, Password: &password, Role: constant.RoleOperator})
+		require.NoError(t, err)
+
+		code, resBody := send(t, "", http.MethodPost, constant.RouteLogin, domain.LoginForm{Login: login, Password: password})
+		require.Equal(t, http.StatusOK, code)
+		var tokens domain.AuthTokens
+		require.NoError(t, json.Unmarshal(resBody, &tokens))
+		code, resBody = send(t, "", http.MethodPost, constant.RouteRefresh, domain.InputRefresh{RefreshToken: tokens.RefreshToken})
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, json.Unmarshal(resBody, &tokens))
+		code, _ = send(t, "", http.MethodPost, constant.RouteLogout, domain.InputRefresh{RefreshToken: tokens.RefreshToken})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, "", http.MethodPost, constant.RouteLogin, domain.LoginForm{Login: login, Password: "wrong"})
+		require.Equal(t, http.StatusUnauthorized, code)
+
+		page := audit(t, url.Values{"userIDs": {userID.String()}})
+		require.Len(t, page.Records, 3)
+		for i, route := range []string{constant.RouteLogout, constant.RouteRefresh, constant.RouteLogin} {
+			assert.Equal(t, http.MethodPost, page.Records[i].Method)
+			assert.Equal(t, constant.RouteAPI+route, page.Records[i].Path)
+			assert.Nil(t, page.Records[i].Target)
+		}
+	})
+
+	t.Run("Audit. Control of vessel", func(t *testing.T) {
+		vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Audit control "+uniq))
+		require.NoError(t, err)
+		vesselID := vessels[0].ID
+		code, _ := send(t, suite.cfg.jwtOperator, http.MethodPost, constant.RouteMonitor, []domain.ControlItem{{VesselID: vesselID}})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, suite.cfg.jwtOperator, http.MethodDelete, constant.RouteMonitor, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+
+		for _, target := range []string{constant.DBWatchlistVessels, constant.DBControlDashboard} {
+			page := audit(t, url.Values{"target": {target}, "targetID": {vesselID.String()}})
+			require.Len(t, page.Records, 2, target)
+			assert.Equal(t, http.MethodDelete, page.Records[0].Method)
+			assert.Equal(t, http.MethodPost, page.Records[1].Method)
+			assert.Empty(t, rows(t, page.Records[1].Before))
+		}
+		page := audit(t, url.Values{"target": {constant.DBControlDashboard}, "targetID": {vesselID.String()}})
+		assert.Equal(t, false, rows(t, page.Records[0].After)[0]["state"])
+	})
+
+	t.Run("Audit. Access", func(t *testing.T) {
+		code, _ := send(t, suite.cfg.jwtOperator, http.MethodGet, constant.RouteAudit, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodGet, constant.RouteAudit+"?method=GET", nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -4,30 +4,110 @@ import (
 	"charts_analyser/internal/app/config"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/service"
+	"context"
+	"errors"
 	"github.com/gofiber/fiber/v2"
-	jwtware "github.com/gofiber/jwt/v2"
 	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
 	"net/http"
+	"strings"
+	"time"
 )
 
-func GetAccessWare(confJWT *config.JWT) fiber.Handler {
-	return jwtware.New(jwtware.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
+// GetAPIKeyWare authenticates by api key header, then jwt is not checked. Request acts as user of key
+// with role of key
+func GetAPIKeyWare(apiKeys service.APIKey, log *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(constant.HeaderAPIKey)
+		if key == "" {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		apiKey, err := apiKeys.Authenticate(ctx, key)
+		if err != nil {
+			if errors.Is(err, myErr.ErrAPIKey) {
+				_, err = c.Status(http.StatusUnauthorized).WriteString(err.Error())
+				return err
+			}
+			c.Status(http.StatusInternalServerError)
+			log.Error("Authenticate", zap.Error(err))
+			return nil
+		}
+		c.Locals(constant.CtxAPIKey, apiKey)
+		c.Locals(constant.CtxStorageKey, &jwt.Token{Valid: true, Claims: jwt.MapClaims{
+			constant.TokenIDKey:   apiKey.UserID.String(),
+			constant.TokenRoleKey: float64(apiKey.Role),
+		}})
+		return c.Next()
+	}
+}
+
+// GetAccessWare checks jwt against key set, token with jti must be active credential.
+// Vessel token without jti is rejected, unless legacy tokens are still accepted by config.
+// Request authenticated by api key is passed
+func GetAccessWare(confJWT *config.JWT, credential service.Credential, log *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if GetAPIKey(c) != nil {
+			return c.Next()
+		}
+		auth := c.Get(fiber.HeaderAuthorization)
+		l := len(constant.AuthScheme)
+		if len(auth) <= l+1 || !strings.EqualFold(auth[:l], constant.AuthScheme) {
+			_, err := c.Status(http.StatusUnauthorized).WriteString("Missing or malformed JWT")
+			return err
+		}
+		token, err := jwt.Parse(auth[l+1:], confJWT.KeyFunc)
+		if err != nil {
 			_, err = c.Status(http.StatusUnauthorized).WriteString(err.Error())
 			return err
-		},
-		SigningKey:    []byte(confJWT.JWTSigningKey),
-		SigningMethod: jwt.SigningMethodHS512.Name,
-		TokenLookup:   "header:" + fiber.HeaderAuthorization,
-		AuthScheme:    "Bearer",
-		ContextKey:    constant.CtxStorageKey,
-	})
+		}
+		if !token.Valid {
+			_, err = c.Status(http.StatusUnauthorized).WriteString("Invalid or expired JWT")
+			return err
+		}
+		c.Locals(constant.CtxStorageKey, token)
+
+		claims := GetTokenClaims(c)
+		jti, _ := claims[constant.TokenJTIKey].(string)
+		if jti == "" {
+			if role(claims).CheckIsRole(constant.RoleVessel) && !confJWT.VesselLegacyAccepted(time.Now()) {
+				_, err = c.Status(http.StatusUnauthorized).WriteString("vessel token without jti")
+				return err
+			}
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		ok, err := credential.IsCredentialActive(ctx, jti)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			log.Error("IsCredentialActive", zap.Error(err))
+			return nil
+		}
+		if !ok {
+			_, err = c.Status(http.StatusUnauthorized).WriteString("token revoked")
+			return err
+		}
+		return c.Next()
+	}
 }
 
-func CheckIsRole(expRole constant.Role) fiber.Handler {
+// CheckPermission any role of token grants permission
+func CheckPermission(roles service.Role, perm constant.Permission, log *zap.Logger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		if !role(GetTokenClaims(c)).CheckIsRole(expRole) {
-			_, err := c.Status(http.StatusForbidden).WriteString("wrong role")
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		ok, err := roles.Can(ctx, role(GetTokenClaims(c)), perm)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			log.Error("Can", zap.Error(err), zap.String("permission", string(perm)))
+			return nil
+		}
+		if !ok {
+			_, err = c.Status(http.StatusForbidden).WriteString("permission required: " + string(perm))
 			return err
 		}
 		return c.Next()
@@ -47,6 +127,25 @@ func GetVesselID(c *fiber.Ctx) (id domain.VesselID) {
 	return
 }
 
+func GetUserID(c *fiber.Ctx) (id domain.UserID) {
+	claims := GetTokenClaims(c)
+	if !role(claims).CheckIsRole(constant.RoleVessel) {
+		switch cl := claims[constant.TokenIDKey].(type) {
+		case float64:
+			id = domain.UserID(cl)
+		case string:
+			_ = id.SetFromStr(cl)
+		}
+	}
+	return
+}
+
+// GetAPIKey of request authenticated by api key, nil for jwt
+func GetAPIKey(c *fiber.Ctx) *domain.APIKey {
+	apiKey, _ := c.Locals(constant.CtxAPIKey).(*domain.APIKey)
+	return apiKey
+}
+
 func GetTokenClaims(c *fiber.Ctx) (claims jwt.MapClaims) {
 	if u := c.Locals(constant.CtxStorageKey); u != nil {
 		if cl, ok := u.(*jwt.Token); ok {
@@ -15,9 +15,9 @@ import (
 // ChartZones
 // @Tags        Chart
 // @Summary     список морских карт
-// @Description которые пересекались заданными в запросе судами в заданный временной промежуток.
+// @Description которые пересекались заданными в запросе судами (vesselIDs и суда групп groupIDs) в заданный временной промежуток.
 // @Accept      json
-// @Param       InputVesselsInterval        body     domain.InputVesselsInterval true "Входные параметры: идентификаторы судов, стартовая дата, конечная дата."
+// @Param       InputVesselsInterval        body     domain.InputVesselsInterval true "Входные параметры: идентификаторы судов и групп, стартовая дата, конечная дата."
 // @Produce     json
 // @Success     200         {object} []string
 // @Failure     400
@@ -32,15 +32,26 @@ func (h *Handler) ChartZones() fiber.Handler {
 			query domain.InputVesselsInterval
 		)
 		err = c.BodyParser(&query)
-		if err != nil && !errors.Is(err, io.EOF) || len(query.VesselIDs) == 0 {
+		if err != nil && !errors.Is(err, io.EOF) {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
 
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
+		if !h.expandGroups(ctx, c, &query.InputVessels) {
+			return nil
+		}
+		if len(query.VesselIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
 		var result []domain.ZoneName
-		result, err = h.s.Chart.Zones(ctx, query)
+		result, err = h.s.Chart.Zones(ctx, scope, query)
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			h.log.Error("Error get zones", zap.Error(err))
@@ -78,8 +89,12 @@ func (h *Handler) ChartVessels() fiber.Handler {
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
 
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
 		var result []domain.VesselID
-		result, err = h.s.Chart.Vessels(ctx, query)
+		result, err = h.s.Chart.Vessels(ctx, scope, query)
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			h.log.Error("Error vessel zones", zap.Error(err))
@@ -92,11 +107,12 @@ func (h *Handler) ChartVessels() fiber.Handler {
 // Track
 // @Tags        Track
 // @Summary     Запись трека судна
-// @Description
+// @Description Точка - [lon, lat] или {"location": [lon, lat], "timestamp"} со временем точки (для отложенной отправки, по умолчанию - время приема).
+// @Description Состояние мониторинга считается по времени точек: точка старше текущего состояния сохраняется только в треке
 // @Accept      json
 // @Param       Authorization  header string          true "Bearer: JWT claims must have: id key used as vesselID and role: 1"
 // @Param       VesselID       header domain.VesselID true "id field of jwt key"
-// @Param       Point          body   domain.Point    true "[lon, lat]"
+// @Param       Point          body   domain.InputTrack    true "[lon, lat] или точка со временем"
 // @Produce     json
 // @Success     200         {string} string "Ok"
 // @Failure     400
@@ -108,14 +124,14 @@ func (h *Handler) ChartVessels() fiber.Handler {
 func (h *Handler) Track() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
 		var (
-			location domain.InputPoint
-			id       = GetVesselID(c)
+			track domain.InputTrack
+			id    = GetVesselID(c)
 		)
 		if id == 0 {
 			c.Status(http.StatusForbidden)
 			return nil
 		}
-		err = c.BodyParser(&location)
+		err = c.BodyParser(&track)
 		if err != nil && !errors.Is(err, io.EOF) {
 			c.Status(http.StatusBadRequest)
 			return nil
@@ -123,7 +139,12 @@ func (h *Handler) Track() fiber.Handler {
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
 
-		if err = h.s.Track(ctx, id, location); err != nil {
+		if track.Timestamp != nil {
+			err = h.s.TrackAt(ctx, id, track.Location, *track.Timestamp)
+		} else {
+			err = h.s.Track(ctx, id, track.Location)
+		}
+		if err != nil {
 			if errors.Is(err, myErr.ErrNotExist) {
 				c.Status(http.StatusNotFound)
 				return nil
@@ -132,8 +153,12 @@ func (h *Handler) Track() fiber.Handler {
 				_, err = c.Status(http.StatusBadRequest).WriteString(myErr.ErrLocationOutOfRange.Error())
 				return
 			}
+			if errors.Is(err, myErr.ErrTimestampInFuture) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(myErr.ErrTimestampInFuture.Error())
+				return
+			}
 			c.Status(http.StatusInternalServerError)
-			h.log.Error("Track", zap.Error(err), zap.Any("id", id), zap.Any("location", location))
+			h.log.Error("Track", zap.Error(err), zap.Any("id", id), zap.Any("track", track))
 			return nil
 		}
 		_, err = c.Status(http.StatusOK).WriteString("ok")
@@ -144,10 +169,11 @@ func (h *Handler) Track() fiber.Handler {
 // GetTrack
 // @Tags        Track
 // @Summary     Маршрут судна за указанный период
-// @Description
+// @Description вместе с маршрутами судов групп groupIDs
 // @Accept      json
 // @Param       id            path      uint64               true  "ID Судна "
 // @Param       DateInterval  query     domain.DateInterval  true  "Входные параметры: стартовая дата, конечная дата."
+// @Param       groupIDs      query     []uint64             false "ID групп судов"
 // @Produce     json
 // @Success     200          {object} []domain.Track
 // @Failure     400
@@ -177,8 +203,15 @@ func (h *Handler) GetTrack() fiber.Handler {
 
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
+		if !h.expandGroups(ctx, c, &query.InputVessels) {
+			return nil
+		}
 
-		if result, err = h.s.GetTrack(ctx, query); err != nil {
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+		if result, err = h.s.GetTrack(ctx, scope, query); err != nil {
 			if errors.Is(err, myErr.ErrNotExist) {
 				c.Status(http.StatusNotFound)
 				return nil
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
+	"context"
 	"encoding/json"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
@@ -345,9 +346,7 @@ func (suite *HandlerTestSuite) TestChartVessels() {
 
 func (suite *HandlerTestSuite) TestTrack() {
 	t := suite.T()
-	claimsUnknownVessel := domain.NewClaimVessels(&suite.cfg.JWT, domain.VesselID(10000000000), "")
-	jwtUnknownVessel, err := claimsUnknownVessel.Token()
-	require.NoError(t, err)
+	jwtUnknownVessel := suite.vesselToken(domain.VesselID(10000000000), "")
 
 	claimsNoVessel := jwt.NewWithClaims(jwt.SigningMethodHS512, struct {
 		jwt.RegisteredClaims
@@ -416,6 +415,34 @@ func (suite *HandlerTestSuite) TestTrack() {
 				contentType: "text/plain",
 			},
 		},
+		{
+			name: "Track. OK with timestamp",
+			args: args{
+				method: http.MethodPost,
+				query:  domain.InputTrack{Location: domain.InputPoint{12.12, 12.12}, Timestamp: &[]time.Time{time.Now().Add(-time.Hour)}[0]},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtVessel,
+				},
+			},
+			want: want{
+				code:        http.StatusOK,
+				responseLen: &[]bool{true}[0],
+				contentType: "text/plain",
+			},
+		},
+		{
+			name: "Track. Timestamp in future",
+			args: args{
+				method: http.MethodPost,
+				query:  domain.InputTrack{Location: domain.InputPoint{12.12, 12.12}, Timestamp: &[]time.Time{time.Now().Add(time.Hour)}[0]},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtVessel,
+				},
+			},
+			want: want{
+				code: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "Track. No track data",
 			args: args{
@@ -534,3 +561,69 @@ func (suite *HandlerTestSuite) TestTrack() {
 		})
 	}
 }
+
+func (suite *HandlerTestSuite) TestTrackEventTime() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Replayed vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+	jwtVessel, err := domain.NewClaimVessels(&suite.cfg.JWT, vesselID, "Replayed vessel").Token()
+	require.NoError(t, err)
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, vesselID))
+
+	send := func(t *testing.T, location domain.InputPoint, timestamp time.Time) {
+		bodyJSON, _ := json.Marshal(domain.InputTrack{Location: location, Timestamp: &timestamp})
+		request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+constant.RouteTrack, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwtVessel)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	state := func(t *testing.T) *domain.VesselState {
+		states, err := suite.srv.GetStates(ctx, vesselID)
+		require.NoError(t, err)
+		require.Len(t, states, 1)
+		return states[0]
+	}
+
+	// buffered points of an hour ago
+	entered := time.Now().Add(-time.Hour).Truncate(time.Second)
+	send(t, domain.InputPoint{10, 40}, entered)
+	send(t, domain.InputPoint{10, 40}, entered.Add(10*time.Minute))
+
+	t.Run("Event time. Zone duration by point time", func(t *testing.T) {
+		s := state(t)
+		require.NotNil(t, s.CurrentZone)
+		assert.True(t, entered.Equal(s.CurrentZone.TimeIn))
+		require.NotNil(t, s.ZoneDuration)
+		assert.Equal(t, 10*time.Minute, time.Duration(*s.ZoneDuration))
+	})
+
+	t.Run("Event time. Older point does not change state", func(t *testing.T) {
+		send(t, domain.InputPoint{12.12, 12.12}, entered.Add(5*time.Minute))
+		s := state(t)
+		require.NotNil(t, s.Timestamp)
+		assert.True(t, entered.Add(10*time.Minute).Equal(*s.Timestamp))
+		require.NotNil(t, s.CurrentZone)
+		assert.Equal(t, []domain.ZoneName{suite.cfg.ZoneName}, s.CurrentZone.Zones)
+		require.NotNil(t, s.Location)
+		assert.Equal(t, domain.Point{10, 40}, *s.Location)
+	})
+
+	t.Run("Event time. Older point is kept in track", func(t *testing.T) {
+		start := entered.Add(-time.Minute)
+		tracks, err := suite.srv.GetTrack(ctx, nil, domain.InputVesselsInterval{
+			InputVessels: domain.InputVessels{VesselIDs: domain.VesselIDs{vesselID}},
+			DateInterval: domain.DateInterval{Start: &start},
+		})
+		require.NoError(t, err)
+		assert.Len(t, tracks, 3)
+	})
+
+	require.NoError(t, suite.srv.CancelControl(ctx, suite.operatorAction(ctx), vesselID))
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// VesselCredentials
+// @Tags        Credential
+// @Summary     Действующие токены судов
+// @Description выданные операторами, не отозванные и не истекшие. Фильтр по судам vesselIDs, groupIDs
+// @Accept      json
+// @Produce     json
+// @Param       InputVessels  query    domain.InputVessels    false "фильтр по судам"
+// @Success     200           {object} []domain.VesselCredential
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /credentials [get]
+// @Security    BearerAuth
+func (h *Handler) VesselCredentials() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputVessels
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		if !h.expandGroups(ctx, c, &query) {
+			return nil
+		}
+		if query.NoVessels() {
+			return c.Status(http.StatusOK).JSON([]domain.VesselCredential{})
+		}
+
+		result, err := h.s.Credential.VesselCredentials(ctx, query.VesselIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get vessel credentials", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// IssueVesselToken
+// @Tags        Credential
+// @Summary     Выдача токена судна
+// @Description токен с идентификатором jti, по которому его можно отозвать. Выдал - оператор из токена,
+// @Description comment - например, устройство. Токен возвращается только в ответе
+// @Accept      json
+// @Produce     json
+// @Param       VesselCredential  body     domain.VesselCredential    true "vesselID и комментарий"
+// @Success     201               {object} domain.VesselToken
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /credentials [post]
+// @Security    BearerAuth
+func (h *Handler) IssueVesselToken() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			credential domain.VesselCredential
+		)
+		err = c.BodyParser(&credential)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		credential.IssuedBy = GetUserID(c)
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Credential.IssueVesselToken(ctx, credential)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error issue vessel token", zap.Error(err), zap.Any("credential", credential))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// RevokeVesselCredentials
+// @Tags        Credential
+// @Summary     Отзыв токенов судов
+// @Description токены с указанными jti отклоняются сразу после отзыва
+// @Accept      json
+// @Produce     json
+// @Param       JTIs   body     []string    true "список jti токенов"
+// @Success     200    {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404    "нет действующих токенов с такими jti"
+// @Failure     500
+// @Router      /credentials [delete]
+// @Security    BearerAuth
+func (h *Handler) RevokeVesselCredentials() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			JTIs []string
+		)
+		err = c.BodyParser(&JTIs)
+		if err != nil && !errors.Is(err, io.EOF) || len(JTIs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Credential.RevokeVesselCredentials(ctx, GetUserID(c), JTIs...)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error revoke vessel credentials", zap.Error(err), zap.Any("jtis", JTIs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
@@ -0,0 +1,111 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestVesselCredentials() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Credential vessel "+time.Now().Format(time.RFC3339Nano)))
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+
+	send := func(t *testing.T, method, route, jwt string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+
+	var token domain.VesselToken
+	t.Run("Credentials. Issue", func(t *testing.T) {
+		code, _ := send(t, http.MethodPost, constant.RouteCredentials, suite.cfg.jwtVessel, domain.VesselCredential{VesselID: vesselID})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, http.MethodPost, constant.RouteCredentials, suite.cfg.jwtOperator, domain.VesselCredential{VesselID: 100500000})
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, http.MethodPost, constant.RouteCredentials, suite.cfg.jwtOperator, domain.VesselCredential{})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, resBody := send(t, http.MethodPost, constant.RouteCredentials, suite.cfg.jwtOperator,
+			domain.VesselCredential{VesselID: vesselID, Comment: "bridge tablet"})
+		require.Equal(t, http.StatusCreated, code)
+		require.NoError(t, json.Unmarshal(resBody, &token))
+		assert.NotEmpty(t, token.JTI)
+		assert.NotEmpty(t, token.Token)
+		assert.Equal(t, domain.UserID(12), token.IssuedBy)
+		assert.True(t, token.ExpiresAt.After(time.Now()))
+
+		code, _ = send(t, http.MethodPost, constant.RouteTrack, token.Token, domain.InputPoint{12.12, 12.12})
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Credentials. List active", func(t *testing.T) {
+		code, resBody := send(t, http.MethodGet, constant.RouteCredentials+"?vesselIDs="+vesselID.String(), suite.cfg.jwtOperator, nil)
+		require.Equal(t, http.StatusOK, code)
+		var credentials []domain.VesselCredential
+		require.NoError(t, json.Unmarshal(resBody, &credentials))
+		require.Equal(t, 1, len(credentials))
+		assert.Equal(t, token.JTI, credentials[0].JTI)
+		assert.Equal(t, "bridge tablet", credentials[0].Comment)
+		assert.Equal(t, vessels[0].Name, credentials[0].VesselName)
+	})
+
+	t.Run("Credentials. Revoke", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, constant.RouteCredentials, suite.cfg.jwtOperator, []string{token.JTI})
+		require.Equal(t, http.StatusOK, code)
+
+		code, resBody := send(t, http.MethodPost, constant.RouteTrack, token.Token, domain.InputPoint{12.12, 12.12})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, "token revoked", string(resBody))
+
+		code, resBody = send(t, http.MethodGet, constant.RouteCredentials+"?vesselIDs="+vesselID.String(), suite.cfg.jwtOperator, nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "[]", string(resBody))
+
+		code, _ = send(t, http.MethodDelete, constant.RouteCredentials, suite.cfg.jwtOperator, []string{token.JTI})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Credentials. Vessel token without jti", func(t *testing.T) {
+		legacy, err := domain.NewClaimVessels(&suite.cfg.JWT, vesselID, vessels[0].Name).Token()
+		require.NoError(t, err)
+		code, resBody := send(t, http.MethodPost, constant.RouteTrack, legacy, domain.InputPoint{12.12, 12.12})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Equal(t, "vessel token without jti", string(resBody))
+
+		suite.cfg.TokenVesselLegacyUntil = uint64(time.Now().Add(time.Hour).Unix())
+		defer func() { suite.cfg.TokenVesselLegacyUntil = 0 }()
+		code, _ = send(t, http.MethodPost, constant.RouteTrack, legacy, domain.InputPoint{12.12, 12.12})
+		assert.Equal(t, http.StatusOK, code)
+
+		suite.cfg.TokenVesselLegacyUntil = uint64(time.Now().Add(-time.Second).Unix())
+		code, _ = send(t, http.MethodPost, constant.RouteTrack, legacy, domain.InputPoint{12.12, 12.12})
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+}
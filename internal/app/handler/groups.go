@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// VesselGroups
+// @Tags        VesselGroup
+// @Summary     Группы судов (флоты)
+// @Description с текущими не удаленными судами
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.VesselGroup
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /groups [get]
+// @Security    BearerAuth
+func (h *Handler) VesselGroups() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.VesselGroup.VesselGroups(ctx)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get vessel groups", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddVesselGroup
+// @Tags        VesselGroup
+// @Summary     Добавление группы судов
+// @Description название уникально, несуществующие ID судов пропускаются.
+// @Description ID группы можно передавать в groupIDs вместе с vesselIDs - группа заменяется ее текущими судами
+// @Accept      json
+// @Produce     json
+// @Param       VesselGroup  body      domain.VesselGroup    true "группа"
+// @Success     201          {integer} domain.VesselGroupID
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     409
+// @Failure     500
+// @Router      /groups [post]
+// @Security    BearerAuth
+func (h *Handler) AddVesselGroup() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			group domain.VesselGroup
+		)
+		err = c.BodyParser(&group)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		group.CreatedBy = GetUserID(c)
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.VesselGroup.AddVesselGroup(ctx, &group)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrDuplicateRecord) {
+				c.Status(http.StatusConflict)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add vessel group", zap.Error(err), zap.Any("group", group))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateVesselGroup
+// @Tags        VesselGroup
+// @Summary     Изменение группы судов
+// @Description название и суда, список судов заменяется целиком
+// @Accept      json
+// @Produce     json
+// @Param       VesselGroup  body     domain.VesselGroup    true "группа"
+// @Success     200          {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     409
+// @Failure     500
+// @Router      /groups [put]
+// @Security    BearerAuth
+func (h *Handler) UpdateVesselGroup() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			group domain.VesselGroup
+		)
+		err = c.BodyParser(&group)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.VesselGroup.UpdateVesselGroup(ctx, &group)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrDuplicateRecord) {
+				c.Status(http.StatusConflict)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error update vessel group", zap.Error(err), zap.Any("group", group))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// DeleteVesselGroups
+// @Tags        VesselGroup
+// @Summary     Удаление групп судов
+// @Description суда не удаляются и остаются на мониторинге
+// @Accept      json
+// @Produce     json
+// @Param       GroupIDs   body     []domain.VesselGroupID    true "список ID групп"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /groups [delete]
+// @Security    BearerAuth
+func (h *Handler) DeleteVesselGroups() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			GroupIDs []domain.VesselGroupID
+		)
+		err = c.BodyParser(&GroupIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(GroupIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.VesselGroup.DeleteVesselGroups(ctx, GroupIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error delete vessel groups", zap.Error(err), zap.Any("ids", GroupIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// expandGroups adds current vessels of in.GroupIDs to in.VesselIDs, responds 404 if some group does not exist
+func (h *Handler) expandGroups(ctx context.Context, c *fiber.Ctx, in *domain.InputVessels) bool {
+	err := h.s.ExpandGroups(ctx, in)
+	if errors.Is(err, myErr.ErrNotExist) {
+		c.Status(http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		h.log.Error("Error expand vessel groups", zap.Error(err), zap.Any("groupIDs", in.GroupIDs))
+		return false
+	}
+	return true
+}
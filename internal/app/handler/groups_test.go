@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestVesselGroups() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Fleet vessel A "+uniq), domain.VesselName("Fleet vessel B "+uniq))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(vessels))
+	vesselIDs := vessels.IDs()
+
+	send := func(t *testing.T, method, route string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+
+	var groupID domain.VesselGroupID
+	var query string
+	t.Run("Vessel groups. Add", func(t *testing.T) {
+		code, resBody := send(t, http.MethodPost, constant.RouteGroups,
+			domain.VesselGroup{Name: "Charterer " + uniq, VesselIDs: domain.VesselIDs{vesselIDs[0], 100500000}})
+		require.Equal(t, http.StatusCreated, code)
+		require.NoError(t, json.Unmarshal(resBody, &groupID))
+		query = "?groupIDs=" + strconv.FormatInt(int64(groupID), 10)
+
+		code, _ = send(t, http.MethodPost, constant.RouteGroups, domain.VesselGroup{Name: "Charterer " + uniq})
+		assert.Equal(t, http.StatusConflict, code)
+		code, _ = send(t, http.MethodPost, constant.RouteGroups, domain.VesselGroup{})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Vessel groups. Update and list", func(t *testing.T) {
+		code, _ := send(t, http.MethodPut, constant.RouteGroups,
+			domain.VesselGroup{ID: groupID, Name: "Region " + uniq, VesselIDs: vesselIDs})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, http.MethodPut, constant.RouteGroups, domain.VesselGroup{ID: 100500000, Name: "Not exist"})
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, resBody := send(t, http.MethodGet, constant.RouteGroups, nil)
+		require.Equal(t, http.StatusOK, code)
+		var groups []domain.VesselGroup
+		require.NoError(t, json.Unmarshal(resBody, &groups))
+		found := false
+		for _, g := range groups {
+			if g.ID == groupID {
+				found = true
+				assert.Equal(t, "Region "+uniq, g.Name)
+				assert.Equal(t, vesselIDs, g.VesselIDs)
+				assert.Equal(t, domain.UserID(12), g.CreatedBy)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Vessel groups. Expand in monitor and states", func(t *testing.T) {
+		code, _ := send(t, http.MethodPost, constant.RouteMonitor+query, nil)
+		require.Equal(t, http.StatusOK, code)
+		states, err := suite.srv.GetStates(ctx, vesselIDs...)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(states))
+		for _, state := range states {
+			assert.True(t, state.State)
+		}
+
+		code, resBody := send(t, http.MethodPost, constant.RouteMonitor+constant.RouteState+query, nil)
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, json.Unmarshal(resBody, &states))
+		assert.Equal(t, 2, len(states))
+
+		code, _ = send(t, http.MethodPost, constant.RouteMonitor+"?groupIDs=100500000", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, _ = send(t, http.MethodDelete, constant.RouteMonitor+query, nil)
+		require.Equal(t, http.StatusOK, code)
+		states, err = suite.srv.GetStates(ctx, vesselIDs...)
+		require.NoError(t, err)
+		for _, state := range states {
+			assert.False(t, state.State)
+		}
+	})
+
+	t.Run("Vessel groups. Expand in chart zones and tracks", func(t *testing.T) {
+		require.NoError(t, suite.srv.Track(ctx, vesselIDs[1], domain.InputPoint{10, 40}))
+		start, finish := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+		code, resBody := send(t, http.MethodPost, constant.RouteChart+constant.RouteZones, domain.InputVesselsInterval{
+			InputVessels: domain.InputVessels{GroupIDs: domain.VesselGroupIDs{groupID}},
+			DateInterval: domain.DateInterval{Start: &start, Finish: &finish},
+		})
+		require.Equal(t, http.StatusOK, code)
+		assert.Contains(t, string(resBody), "zone_47")
+
+		code, resBody = send(t, http.MethodGet, constant.RouteTrack+"/"+vesselIDs[0].String()+query+
+			"&start="+start.UTC().Format(time.RFC3339)+"&finish="+finish.UTC().Format(time.RFC3339), nil)
+		require.Equal(t, http.StatusOK, code)
+		var tracks []domain.Track
+		require.NoError(t, json.Unmarshal(resBody, &tracks))
+		require.Equal(t, 1, len(tracks))
+		assert.Equal(t, vesselIDs[1], tracks[0].Vessel.ID)
+	})
+
+	t.Run("Vessel groups. Delete", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, constant.RouteGroups, []domain.VesselGroupID{groupID})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, http.MethodPost, constant.RouteMonitor+constant.RouteState+query, nil)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
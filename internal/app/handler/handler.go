@@ -22,45 +22,115 @@ func NewHandler(app *fiber.App, s *service.Service, conf *config.Config, log *za
 // Handler init routes
 func (h *Handler) Handler() *Handler {
 
-	h.app.Post(constant.RouteAPI+constant.RouteLogin, h.Login())
+	h.app.Get(constant.RouteJWKS, h.JWKS())
+	auth := GetAuditWare(h.s.Audit, h.log)
+	h.app.Post(constant.RouteAPI+constant.RouteLogin, auth, h.Login())
+	h.app.Post(constant.RouteAPI+constant.RouteRefresh, auth, h.Refresh())
+	h.app.Post(constant.RouteAPI+constant.RouteLogout, auth, h.Logout())
 
 	api := h.app.Group(constant.RouteAPI)
-	api.Use(GetAccessWare(&h.conf.JWT))
+	api.Use(GetAPIKeyWare(h.s.APIKey, h.log), GetAccessWare(&h.conf.JWT, h.s.Credential, h.log),
+		// tracks are not audited: see GetAuditWare
+		GetAuditWare(h.s.Audit, h.log,
+			constant.RouteAPI+constant.RouteTrack,
+			constant.RouteAPI+constant.RouteChart+constant.RouteZones,
+			constant.RouteAPI+constant.RouteChart+constant.RouteVessels,
+			constant.RouteAPI+constant.RouteMonitor+constant.RouteState))
 
-	opAw := CheckIsRole(constant.RoleOperator)
-	veAw := CheckIsRole(constant.RoleVessel)
-	admAw := CheckIsRole(constant.RoleAdmin)
+	can := func(perm constant.Permission) fiber.Handler {
+		return CheckPermission(h.s.Role, perm, h.log)
+	}
 
-	operator := api.Group(constant.RouteUser)
-	operator.Use(admAw)
-	operator.Post("", h.AddUser())
-	operator.Put("", h.UpdateUser())
-	operator.Delete("", h.DeleteUsers())
-	operator.Patch("", h.RestoreUsers())
+	user := api.Group(constant.RouteUser)
+	user.Get("", can(constant.PermUserRead), h.ListUsers())
+	user.Get(constant.RouteID, can(constant.PermUserRead), h.GetUser())
+	user.Get(constant.RouteID+constant.RouteScope, can(constant.PermUserRead), h.UserScope())
+	user.Put(constant.RouteID+constant.RouteScope, can(constant.PermUserWrite), h.SetUserScope())
+	user.Post("", can(constant.PermUserWrite), h.AddUser())
+	user.Put("", can(constant.PermUserWrite), h.UpdateUser())
+	user.Delete("", can(constant.PermUserWrite), h.DeleteUsers())
+	user.Patch("", can(constant.PermUserWrite), h.RestoreUsers())
+
+	roles := api.Group(constant.RouteRoles)
+	roles.Get("", can(constant.PermRoleRead), h.Roles())
+	roles.Post("", can(constant.PermRoleWrite), h.AddRole())
+	roles.Put("", can(constant.PermRoleWrite), h.UpdateRole())
+	roles.Delete("", can(constant.PermRoleWrite), h.DeleteRoles())
+
+	keys := api.Group(constant.RouteKeys)
+	keys.Get("", can(constant.PermAPIKeyRead), h.APIKeys())
+	keys.Post("", can(constant.PermAPIKeyWrite), h.AddAPIKey())
+	keys.Post(constant.RouteID+constant.RouteRotate, can(constant.PermAPIKeyWrite), h.RotateAPIKey())
+	keys.Delete("", can(constant.PermAPIKeyWrite), h.RevokeAPIKeys())
+
+	api.Get(constant.RouteAudit, can(constant.PermAuditRead), h.Audit())
+
+	admin := api.Group(constant.RouteAdmin)
+	admin.Get(constant.RouteVessels+constant.RouteID+constant.RouteExport, can(constant.PermVesselExport), h.ExportVessel())
+	admin.Delete(constant.RouteVessels+constant.RouteID, can(constant.PermVesselPurge), h.PurgeVessel())
 
 	chart := api.Group(constant.RouteChart)
-	chart.Use(opAw)
+	chart.Use(can(constant.PermChartRead))
 	chart.Post(constant.RouteZones, h.ChartZones())
 	chart.Post(constant.RouteVessels, h.ChartVessels())
 
 	monitor := api.Group(constant.RouteMonitor)
-	monitor.Use(opAw)
-	monitor.Post(constant.RouteState, h.VesselState())
-	monitor.Get("", h.MonitoredList())
-	monitor.Post("", h.SetControl())
-	monitor.Delete("", h.DelControl())
+	monitor.Post(constant.RouteState, can(constant.PermMonitorRead), h.VesselState())
+	monitor.Get(constant.RouteStream, can(constant.PermMonitorRead), h.MonitorStream())
+	monitor.Get("", can(constant.PermMonitorRead), h.MonitoredList())
+	monitor.Post("", can(constant.PermMonitorWrite), h.SetControl())
+	monitor.Delete("", can(constant.PermMonitorWrite), h.DelControl())
+	monitor.Get(constant.RouteSchedule, can(constant.PermMonitorRead), h.ControlSchedule())
+	monitor.Get(constant.RouteLog, can(constant.PermMonitorRead), h.ControlLog())
+	monitor.Get(constant.RouteSummary, can(constant.PermMonitorRead), h.MonitorSummary())
+	monitor.Get(constant.RoutePredict, can(constant.PermMonitorRead), h.PredictZoneEntries())
+
+	alerts := api.Group(constant.RouteAlerts)
+	alerts.Get("", can(constant.PermAlertRead), h.Alerts())
+	alerts.Patch("", can(constant.PermAlertWrite), h.AcknowledgeAlerts())
+	alerts.Get(constant.RouteRules, can(constant.PermAlertRead), h.AlertRules())
+	alerts.Post(constant.RouteRules, can(constant.PermAlertWrite), h.AddAlertRule())
+	alerts.Put(constant.RouteRules, can(constant.PermAlertWrite), h.UpdateAlertRule())
+	alerts.Delete(constant.RouteRules, can(constant.PermAlertWrite), h.DeleteAlertRules())
+
+	watchlists := api.Group(constant.RouteWatchlists)
+	watchlists.Get("", can(constant.PermWatchlistRead), h.Watchlists())
+	watchlists.Post("", can(constant.PermWatchlistWrite), h.AddWatchlist())
+	watchlists.Put("", can(constant.PermWatchlistWrite), h.UpdateWatchlist())
+	watchlists.Delete("", can(constant.PermWatchlistWrite), h.DeleteWatchlists())
+
+	groups := api.Group(constant.RouteGroups)
+	groups.Get("", can(constant.PermGroupRead), h.VesselGroups())
+	groups.Post("", can(constant.PermGroupWrite), h.AddVesselGroup())
+	groups.Put("", can(constant.PermGroupWrite), h.UpdateVesselGroup())
+	groups.Delete("", can(constant.PermGroupWrite), h.DeleteVesselGroups())
+
+	credentials := api.Group(constant.RouteCredentials)
+	credentials.Get("", can(constant.PermCredentialRead), h.VesselCredentials())
+	credentials.Post("", can(constant.PermCredentialWrite), h.IssueVesselToken())
+	credentials.Delete("", can(constant.PermCredentialWrite), h.RevokeVesselCredentials())
+
+	webhooks := api.Group(constant.RouteWebhooks)
+	webhooks.Get("", can(constant.PermWebhookRead), h.Webhooks())
+	webhooks.Post("", can(constant.PermWebhookWrite), h.AddWebhook())
+	webhooks.Put("", can(constant.PermWebhookWrite), h.UpdateWebhook())
+	webhooks.Delete("", can(constant.PermWebhookWrite), h.DeleteWebhooks())
+	webhooks.Get(constant.RouteID+constant.RouteDeliveries, can(constant.PermWebhookRead), h.WebhookDeliveries())
 
 	track := api.Group(constant.RouteTrack)
-	track.Post("", veAw, h.Track())
-	track.Get(constant.RouteID, opAw, h.GetTrack())
+	track.Post("", can(constant.PermTrackWrite), h.Track())
+	track.Get(constant.RouteID, can(constant.PermTrackRead), h.GetTrack())
 
 	vessel := api.Group(constant.RouteVessels)
-	vessel.Use(opAw)
-	vessel.Get("", h.GetVessel())
-	vessel.Post("", h.AddVessel())
-	vessel.Put("", h.UpdateVessel())
-	vessel.Delete("", h.DeleteVessel())
-	vessel.Patch("", h.RestoreVessel())
+	vessel.Get("", can(constant.PermVesselRead), h.GetVessel())
+	vessel.Get(constant.RouteSearch, can(constant.PermVesselRead), h.SearchVessels())
+	vessel.Get(constant.RouteList, can(constant.PermVesselRead), h.ListVessels())
+	vessel.Get(constant.RouteID+constant.RouteNames, can(constant.PermVesselRead), h.VesselNames())
+	vessel.Post("", can(constant.PermVesselWrite), h.AddVessel())
+	vessel.Post(constant.RouteImport, can(constant.PermVesselWrite), h.ImportVessels())
+	vessel.Put("", can(constant.PermVesselWrite), h.UpdateVessel())
+	vessel.Delete("", can(constant.PermVesselWrite), h.DeleteVessel())
+	vessel.Patch("", can(constant.PermVesselWrite), h.RestoreVessel())
 
 	return h
 }
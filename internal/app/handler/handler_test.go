@@ -2,11 +2,14 @@ package handler_test
 
 import (
 	"charts_analyser/internal/app/config"
+	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	"charts_analyser/internal/app/handler"
 	"charts_analyser/internal/app/repository"
 	"charts_analyser/internal/app/service"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -49,10 +52,6 @@ func newConfig() (c *testConfig) {
 	c.VesselID = 9110913
 	c.ZoneName = "zone_47"
 
-	c.jwtVessel, err = domain.NewClaimVessels(&c.JWT, c.VesselID, "Test Vessel").Token()
-	if err != nil {
-		log.Fatal(err)
-	}
 	return
 }
 
@@ -85,6 +84,7 @@ type HandlerTestSuite struct {
 	ctx    context.Context
 	app    *fiber.App
 	srv    *service.Service
+	repo   *repository.Repository
 	cfg    *testConfig
 	pgCont *postgres.PostgresContainer
 }
@@ -102,7 +102,7 @@ func (suite *HandlerTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	repo := repository.NewRepository(func() *sqlx.DB {
+	suite.repo = repository.NewRepository(func() *sqlx.DB {
 		db, err := sqlx.Connect("pgx", suite.cfg.DatabaseDSN)
 		if err != nil {
 			log.Fatal(err)
@@ -112,12 +112,41 @@ func (suite *HandlerTestSuite) SetupSuite() {
 
 	logger, _ := zap.NewDevelopment()
 
-	suite.srv = service.NewService(repo, &suite.cfg.JWT, logger)
+	suite.srv = service.NewService(suite.repo, suite.cfg.Config, logger)
 
 	suite.app = fiber.New()
 	suite.app.Use(recover.New())
 
 	_ = handler.NewHandler(suite.app, suite.srv, suite.cfg.Config, logger).Handler()
+
+	suite.cfg.jwtVessel = suite.vesselToken(suite.cfg.VesselID, "Test Vessel")
+}
+
+// vesselToken with jti registered as vessel credential, vessel may not exist
+func (suite *HandlerTestSuite) vesselToken(vesselID domain.VesselID, name domain.VesselName) string {
+	jti := make([]byte, constant.TokenJTILen)
+	_, err := rand.Read(jti)
+	suite.Require().NoError(err)
+	claims := domain.NewClaimVessels(&suite.cfg.JWT, vesselID, name).WithID(hex.EncodeToString(jti))
+	token, err := claims.Token()
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.Credential.AddVesselCredential(suite.ctx, &domain.VesselCredential{
+		JTI:        claims.ID,
+		VesselID:   vesselID,
+		VesselName: name,
+		IssuedBy:   1,
+		IssuedAt:   time.Now(),
+		ExpiresAt:  claims.ExpiresAt.Time,
+	}))
+	return token
+}
+
+// operatorAction of test operator in personal watchlist
+func (suite *HandlerTestSuite) operatorAction(ctx context.Context) domain.ControlAction {
+	userID := domain.UserID(12)
+	watchlistID, err := suite.srv.UserWatchlist(ctx, userID, 0)
+	suite.Require().NoError(err)
+	return domain.ControlAction{WatchlistID: watchlistID, UserID: &userID}
 }
 
 func (suite *HandlerTestSuite) TearDownSuite() {
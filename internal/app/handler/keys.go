@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/config"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// JWKS
+// @Tags        User
+// @Summary     Публичные ключи токенов
+// @Description JSON Web Key Set для проверки токенов: ключ выбирается по kid из заголовка токена. Пустой, если токены подписываются общим секретом (HS512)
+// @Produce     json
+// @Success     200        {object} config.JWKS
+// @Router      /.well-known/jwks.json [get]
+func (h *Handler) JWKS() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		result := config.JWKS{Keys: make([]config.JWK, 0)}
+		if h.conf.Keys != nil {
+			result = h.conf.Keys.JWKS()
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
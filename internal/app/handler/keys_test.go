@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"charts_analyser/internal/app/config"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func (suite *HandlerTestSuite) TestJWKS() {
+	t := suite.T()
+	dir := t.TempDir()
+	writeKey := func(t *testing.T, name string, key interface{}) {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writeKey(t, "2024-01.pem", rsaKey)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writeKey(t, "2024-02.pem", edKey)
+
+	conf := &suite.cfg.JWT
+	defer func() { conf.JWTKeysDir, conf.Keys, conf.JWTAcceptHS512 = "", nil, false }()
+
+	send := func(t *testing.T, route, jwt string) (code int, resBody []byte) {
+		request, err := http.NewRequest(http.MethodGet, route, nil)
+		require.NoError(t, err)
+		if jwt != "" {
+			request.Header.Set("Authorization", "Bearer "+jwt)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	jwks := func(t *testing.T) (set config.JWKS) {
+		code, resBody := send(t, constant.RouteJWKS, "")
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, json.Unmarshal(resBody, &set))
+		return
+	}
+
+	t.Run("JWKS. Shared secret", func(t *testing.T) {
+		assert.Equal(t, 0, len(jwks(t).Keys))
+	})
+
+	conf.JWTKeysDir = dir
+	require.NoError(t, conf.LoadKeys())
+	token, err := domain.NewClaimOperator(conf, 12, "Test Operator").Token()
+	require.NoError(t, err)
+
+	t.Run("JWKS. Key pairs", func(t *testing.T) {
+		set := jwks(t)
+		require.Equal(t, 2, len(set.Keys))
+		assert.Equal(t, config.JWK{Kty: "RSA", Kid: "2024-01", Use: "sig", Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()), E: "AQAB"}, set.Keys[0])
+		assert.Equal(t, config.JWK{Kty: "OKP", Kid: "2024-02", Use: "sig", Alg: "EdDSA", Crv: "Ed25519",
+			X: base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey))}, set.Keys[1])
+
+		// the last key signs, token is verified by published key
+		x, err := base64.RawURLEncoding.DecodeString(set.Keys[1].X)
+		require.NoError(t, err)
+		parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) { return ed25519.PublicKey(x), nil })
+		require.NoError(t, err)
+		assert.Equal(t, "2024-02", parsed.Header["kid"])
+		assert.Equal(t, "EdDSA", parsed.Method.Alg())
+	})
+
+	t.Run("JWKS. Verify", func(t *testing.T) {
+		code, _ := send(t, constant.RouteAPI+constant.RouteGroups, token)
+		assert.Equal(t, http.StatusOK, code)
+
+		// HS512 is accepted only in transition
+		code, _ = send(t, constant.RouteAPI+constant.RouteGroups, suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusUnauthorized, code)
+		conf.JWTAcceptHS512 = true
+		code, _ = send(t, constant.RouteAPI+constant.RouteGroups, suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusOK, code)
+
+		// retired signing key still verifies
+		conf.JWTSigningKID = "2024-01"
+		require.NoError(t, conf.LoadKeys())
+		conf.JWTSigningKID = ""
+		rsaToken, err := domain.NewClaimOperator(conf, 12, "Test Operator").Token()
+		require.NoError(t, err)
+		require.NoError(t, conf.LoadKeys())
+		code, _ = send(t, constant.RouteAPI+constant.RouteGroups, rsaToken)
+		assert.Equal(t, http.StatusOK, code)
+
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		other := jwt.NewWithClaims(jwt.SigningMethodEdDSA, domain.NewClaimOperator(conf, 12, "Test Operator"))
+		other.Header["kid"] = "2024-02"
+		otherToken, err := other.SignedString(otherKey)
+		require.NoError(t, err)
+		code, _ = send(t, constant.RouteAPI+constant.RouteGroups, otherToken)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+}
@@ -1,36 +1,68 @@
 package handler
 
 import (
+	"bufio"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	myErr "charts_analyser/internal/app/error"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"time"
 )
 
 // MonitoredList
 // @Tags        Monitor
 // @Summary     Список судов
-// @Description поставленных на мониторинг
+// @Description поставленных на мониторинг, со статусом связи contact: ok, stale - нет треков дольше CONTACT_STALE_AFTER,
+// @Description lost - дольше CONTACT_LOST_AFTER. lastSeen - время последнего трека.
+// @Description Суда из списка наблюдения watchlist или, если не задан, из всех списков оператора
 // @Accept      json
 // @Produce     json
-// @Success     200         {object} []domain.Vessel "Ok"
+// @Param       InputWatchlist   query    domain.InputWatchlist false "список наблюдения"
+// @Success     200         {object} []domain.MonitoredVessel "Ok"
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /monitor [get]
 // @Security    BearerAuth
 func (h *Handler) MonitoredList() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputWatchlist
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
 
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
-		result, err := h.s.Monitor.MonitoredVessels(ctx)
+		userID := GetUserID(c)
+		if query.WatchlistID > 0 {
+			if _, err = h.s.UserWatchlist(ctx, userID, query.WatchlistID); err != nil {
+				if errors.Is(err, myErr.ErrNotExist) {
+					c.Status(http.StatusNotFound)
+					return nil
+				}
+				c.Status(http.StatusInternalServerError)
+				h.log.Error("Error monitored list, watchlist", zap.Error(err), zap.Any("query", query))
+				return nil
+			}
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+		result, err := h.s.Monitor.MonitoredVessels(ctx, scope, userID, query.WatchlistID)
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			h.log.Error("Error monitored list", zap.Error(err))
@@ -40,13 +72,129 @@ func (h *Handler) MonitoredList() fiber.Handler {
 	}
 }
 
+// MonitorSummary
+// @Tags        Monitor
+// @Summary     Сводка по мониторингу
+// @Description судов на мониторинге: всего (monitored), по текущим зонам (zones), вне зон (noZone),
+// @Description без треков дольше CONTACT_STALE_AFTER (stale) и CONTACT_LOST_AFTER (lost),
+// @Description суда с наибольшим временем нахождения в текущей зоне (longestDwell).
+// @Description Суда из списка наблюдения watchlist или, если не задан, из всех списков оператора.
+// @Description Пользователю с ограничением - только суда его флота, находящиеся в его зонах, и счетчики его зон
+// @Produce     json
+// @Param       InputWatchlist   query    domain.InputWatchlist false "список наблюдения"
+// @Success     200         {object} domain.MonitorSummary "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /monitor/summary [get]
+// @Security    BearerAuth
+func (h *Handler) MonitorSummary() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputWatchlist
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		userID := GetUserID(c)
+		if query.WatchlistID > 0 {
+			if _, err = h.s.UserWatchlist(ctx, userID, query.WatchlistID); err != nil {
+				if errors.Is(err, myErr.ErrNotExist) {
+					c.Status(http.StatusNotFound)
+					return nil
+				}
+				c.Status(http.StatusInternalServerError)
+				h.log.Error("Error monitor summary, watchlist", zap.Error(err), zap.Any("query", query))
+				return nil
+			}
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+		result, err := h.s.Monitor.MonitorSummary(ctx, scope, userID, query.WatchlistID)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error monitor summary", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// PredictZoneEntries
+// @Tags        Monitor
+// @Summary     Прогноз входа в зоны
+// @Description судов на мониторинге: путь продлевается от последней точки трека с курсом (course, град.) и скоростью (speed, м/с)
+// @Description по точкам трека за последние 10 минут, eta - время пересечения границы зоны в пределах horizon (сек, по умолчанию PREDICTION_HORIZON).
+// @Description Суда из списка наблюдения watchlist или, если не задан, из всех списков оператора, кроме судов с потерянной связью.
+// @Description Пользователю с ограничением - только суда его флота, находящиеся в его зонах, и вход в его зоны
+// @Produce     json
+// @Param       InputPrediction   query    domain.InputPrediction false "список наблюдения, горизонт прогноза"
+// @Success     200         {object} []domain.ZoneEntry "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /monitor/predict [get]
+// @Security    BearerAuth
+func (h *Handler) PredictZoneEntries() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputPrediction
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+		userID := GetUserID(c)
+		if query.WatchlistID > 0 {
+			if _, err = h.s.UserWatchlist(ctx, userID, query.WatchlistID); err != nil {
+				if errors.Is(err, myErr.ErrNotExist) {
+					c.Status(http.StatusNotFound)
+					return nil
+				}
+				c.Status(http.StatusInternalServerError)
+				h.log.Error("Error predict zone entries, watchlist", zap.Error(err), zap.Any("query", query))
+				return nil
+			}
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+		result, err := h.s.ZoneEntries(ctx, scope, userID, query.WatchlistID, query.Horizon)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error predict zone entries", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
 // VesselState
 // @Tags        Monitor
 // @Summary     Текущие данные
-// @Description для выбранных судов, стоящих на мониторинге
+// @Description для выбранных судов, стоящих на мониторинге: из тела запроса и групп groupIDs
 // @Accept      json
 // @Produce     json
 // @Param       VesselIDs   body     []domain.VesselID    true "список ID Судов"
+// @Param       groupIDs    query    []uint64             false "ID групп судов"
 // @Success     200         {object} []domain.VesselState "Ok"
 // @Failure     400
 // @Failure     401
@@ -58,28 +206,45 @@ func (h *Handler) MonitoredList() fiber.Handler {
 func (h *Handler) VesselState() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
 		var (
-			VesselIDs []domain.VesselID
+			query domain.InputVessels
 		)
-		err = c.BodyParser(&VesselIDs)
-		if err != nil && !errors.Is(err, io.EOF) {
+		if err = c.QueryParser(&query); err != nil {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
-
-		if len(VesselIDs) == 0 {
+		err = c.BodyParser(&query.VesselIDs)
+		if err != nil && !errors.Is(err, io.EOF) {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
 
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
-
-		result, err := h.s.Monitor.GetStates(ctx, VesselIDs...)
-		if err != nil && !errors.Is(err, myErr.ErrNotExist) {
-			c.Status(http.StatusInternalServerError)
-			h.log.Error("Error get states", zap.Error(err), zap.Any("ids", VesselIDs))
+		if !h.expandGroups(ctx, c, &query) {
+			return nil
+		}
+		if len(query.VesselIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
 			return nil
 		}
+		result := make([]*domain.VesselState, 0, len(query.VesselIDs))
+		if vesselIDs := scope.Vessels(query.VesselIDs); len(vesselIDs) > 0 {
+			states, err := h.s.Monitor.GetStates(ctx, vesselIDs...)
+			if err != nil && !errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusInternalServerError)
+				h.log.Error("Error get states", zap.Error(err), zap.Any("ids", query.VesselIDs))
+				return nil
+			}
+			for _, state := range states {
+				if scope.HasState(state) {
+					result = append(result, state)
+				}
+			}
+		}
 		return c.Status(http.StatusOK).JSON(result)
 	}
 }
@@ -88,53 +253,92 @@ func (h *Handler) VesselState() fiber.Handler {
 // @Tags        Monitor
 // @Summary     Поставить судно на контроль
 // @Description
-// на мониторинг (снять с мониторинга)
+// на мониторинг (снять с мониторинга). Элемент списка - ID судна или окно мониторинга {"vesselID", "start", "end"}:
+// @Description судно ставится на мониторинг в start (сейчас, если не задан) и снимается в end (не снимается, если не задан).
+// @Description В end судно убирается из списка, только если его добавило окно, а не оператор
 // @Accept      json
-// @Param       VesselIDs   body   []domain.VesselID true "список ID Судов"
+// @Description Суда добавляются в список наблюдения watchlist или в личный список оператора,
+// @Description на мониторинге судно остается, пока оно есть хотя бы в одном списке
+// @Description Действие записывается в журнал контроля от имени оператора из токена с комментарием comment
+// @Description Суда групп groupIDs, которых нет в теле запроса, ставятся на мониторинг сразу
+// @Param       ControlItems   body   []domain.ControlItem true "список ID Судов или окон мониторинга"
+// @Param       InputControl   query    domain.InputControl false "список наблюдения, комментарий"
+// @Param       groupIDs       query    []uint64 false "ID групп судов"
 // @Produce     json
 // @Success     200         {string} string "Ok"
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /monitor [post]
 // @Security    BearerAuth
 func (h *Handler) SetControl() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
 		var (
-			VesselIDs []domain.VesselID
+			items   []domain.ControlItem
+			control domain.InputControl
 		)
-		err = c.BodyParser(&VesselIDs)
+		err = c.BodyParser(&items)
 		if err != nil && !errors.Is(err, io.EOF) {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
-
-		if len(VesselIDs) == 0 {
-			var query domain.InputVessels
-			if err = c.QueryParser(&query); err != nil {
-				c.Status(http.StatusBadRequest)
-				h.log.Error("SetControl,query", zap.Error(err))
-				return nil
-			}
-			VesselIDs = query.VesselIDs
+		if err = c.QueryParser(&control); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
 		}
 
-		if len(VesselIDs) == 0 {
+		var query domain.InputVessels
+		if err = c.QueryParser(&query); err != nil {
 			c.Status(http.StatusBadRequest)
+			h.log.Error("SetControl,query", zap.Error(err))
 			return nil
 		}
+		if len(items) == 0 {
+			for _, id := range query.VesselIDs {
+				items = append(items, domain.ControlItem{VesselID: id})
+			}
+		}
+
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
 
-		err = h.s.SetControl(ctx, true, VesselIDs...)
+		// vessels of groups not listed in items are set on control now
+		groups := domain.InputVessels{GroupIDs: query.GroupIDs}
+		for _, item := range items {
+			groups.AddVessels(item.VesselID)
+		}
+		listed := len(groups.VesselIDs)
+		if !h.expandGroups(ctx, c, &groups) {
+			return nil
+		}
+		for _, id := range groups.VesselIDs[listed:] {
+			items = append(items, domain.ControlItem{VesselID: id})
+		}
+		if len(items) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		var (
+			userID      = GetUserID(c)
+			watchlistID domain.WatchlistID
+		)
+		if watchlistID, err = h.s.UserWatchlist(ctx, userID, control.WatchlistID); err == nil {
+			err = h.s.ScheduleControl(ctx, control.Action(userID, watchlistID), items...)
+		}
 		if err != nil {
 			if errors.Is(err, myErr.ErrNotExist) {
 				c.Status(http.StatusNotFound)
 				return nil
 			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
 			c.Status(http.StatusInternalServerError)
-			h.log.Error("SetControl", zap.Error(err), zap.Any("ids", VesselIDs))
+			h.log.Error("SetControl", zap.Error(err), zap.Any("items", items))
 			return nil
 		}
 		_, err = c.Status(http.StatusOK).WriteString("ok")
@@ -145,9 +349,13 @@ func (h *Handler) SetControl() fiber.Handler {
 // DelControl
 // @Tags        Monitor
 // @Summary     Снять судно с контроля
-// @Description
+// @Description Суда удаляются из списка наблюдения watchlist или из личного списка оператора,
+// @Description мониторинг прекращается, если судна нет в других списках. Запланированные окна мониторинга судов в списке отменяются
 // @Accept      json
+// @Description Действие записывается в журнал контроля от имени оператора из токена с комментарием comment
 // @Param       VesselIDs   body   []domain.VesselID true "список ID Судов"
+// @Param       InputControl   query    domain.InputControl false "список наблюдения, комментарий"
+// @Param       groupIDs       query    []uint64 false "ID групп судов, снимаются вместе с судами из тела запроса"
 // @Produce     json
 // @Success     200         {string} string "Ok"
 // @Failure     400
@@ -159,32 +367,235 @@ func (h *Handler) SetControl() fiber.Handler {
 func (h *Handler) DelControl() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
 		var (
-			VesselIDs []domain.VesselID
+			query   domain.InputVessels
+			control domain.InputControl
 		)
-		err = c.BodyParser(&VesselIDs)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		err = c.BodyParser(&query.VesselIDs)
 		if err != nil && !errors.Is(err, io.EOF) {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
-
-		if len(VesselIDs) == 0 {
+		if err = c.QueryParser(&control); err != nil {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
+
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
+		if !h.expandGroups(ctx, c, &query) {
+			return nil
+		}
+		if len(query.VesselIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
 
-		err = h.s.SetControl(ctx, false, VesselIDs...)
+		var (
+			userID      = GetUserID(c)
+			watchlistID domain.WatchlistID
+		)
+		if watchlistID, err = h.s.UserWatchlist(ctx, userID, control.WatchlistID); err == nil {
+			err = h.s.CancelControl(ctx, control.Action(userID, watchlistID), query.VesselIDs...)
+		}
 		if err != nil {
 			if errors.Is(err, myErr.ErrNotExist) {
 				c.Status(http.StatusNotFound)
 				return nil
 			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
 			c.Status(http.StatusInternalServerError)
-			h.log.Error("SetControl", zap.Error(err), zap.Any("ids", VesselIDs))
+			h.log.Error("DelControl", zap.Error(err), zap.Any("ids", query.VesselIDs))
 			return nil
 		}
 		_, err = c.Status(http.StatusOK).WriteString("ok")
 		return
 	}
 }
+
+// ControlSchedule
+// @Tags        Monitor
+// @Summary     Запланированные окна мониторинга
+// @Description не завершенные и не отмененные, в списках наблюдения оператора
+// @Produce     json
+// @Success     200         {object} []domain.ControlWindow "Ok"
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /monitor/schedule [get]
+// @Security    BearerAuth
+func (h *Handler) ControlSchedule() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.ControlWindows(ctx, GetUserID(c))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error control schedule", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// ControlLog
+// @Tags        Monitor
+// @Summary     Журнал контроля
+// @Description постановки на мониторинг и снятия с него: оператор (userID, пусто - по расписанию), список наблюдения, комментарий.
+// @Description Фильтр по судам (vesselIDs и суда групп groupIDs), операторам и периоду.
+// @Description Пользователю с ограничением - только суда его флота, находящиеся сейчас в его зонах
+// @Produce     json
+// @Param       InputControlLog   query    domain.InputControlLog false "фильтр: суда, операторы, период"
+// @Success     200         {object} []domain.ControlLog "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /monitor/log [get]
+// @Security    BearerAuth
+func (h *Handler) ControlLog() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputControlLog
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		if !h.expandGroups(ctx, c, &query.InputVessels) {
+			return nil
+		}
+		if query.NoVessels() {
+			return c.Status(http.StatusOK).JSON([]domain.ControlLog{})
+		}
+		scope, ok := h.scope(ctx, c)
+		if !ok {
+			return nil
+		}
+
+		result, err := h.s.Monitor.ControlLog(ctx, scope, query)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error control log", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// MonitorStream
+// @Tags        Monitor
+// @Summary     Поток изменений состояния судов
+// @Description Server-Sent Events (event: state) при обновлении состояния судов на мониторинге, в т.ч. смене зоны.
+// @Description Без vesselIDs и groupIDs - по всем судам, суда групп - на момент подключения.
+// @Description Пользователю с ограничением - только суда его флота, находящиеся в его зонах. Для догрузки пропущенных событий после переподключения
+// @Description передать id последнего полученного события в заголовке Last-Event-ID или параметре lastEventID.
+// @Description 410 - события уже недоступны, нужно перечитать состояния через /monitor/state
+// @Param       InputStream   query    domain.InputStream false "фильтр по судам, токен возобновления"
+// @Param       Last-Event-ID header   string             false "токен возобновления"
+// @Produce     text/event-stream
+// @Success     200         {object} domain.StateEvent "data события"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     410
+// @Failure     500
+// @Router      /monitor/stream [get]
+// @Security    BearerAuth
+func (h *Handler) MonitorStream() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputStream
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		lastEventID := c.Get("Last-Event-ID", query.LastEventID)
+
+		expandCtx, expandCancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer expandCancel()
+		if !h.expandGroups(expandCtx, c, &query.InputVessels) {
+			return nil
+		}
+		if query.NoVessels() {
+			_, err = c.Status(http.StatusBadRequest).WriteString("no vessels in groups")
+			return
+		}
+		scope, ok := h.scope(expandCtx, c)
+		if !ok {
+			return nil
+		}
+		// without vessels in query - all vessels of scope, subscriber checks scope of each event
+		vesselIDs := query.VesselIDs
+		if len(vesselIDs) > 0 {
+			if vesselIDs = scope.Vessels(vesselIDs); len(vesselIDs) == 0 {
+				_, err = c.Status(http.StatusBadRequest).WriteString("no vessels in scope")
+				return
+			}
+		}
+
+		// stream outlives handler, so it can't use request context
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := h.s.Stream.Subscribe(ctx, lastEventID, scope, vesselIDs...)
+		if err != nil {
+			cancel()
+			if errors.Is(err, myErr.ErrResumeExpired) {
+				_, err = c.Status(http.StatusGone).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("MonitorStream", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Status(http.StatusOK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+			// headers are sent with the first flush: client knows subscription is active before the first event
+			if _, er := w.WriteString(": connected\n\n"); er != nil || w.Flush() != nil {
+				return
+			}
+			keepAlive := time.NewTicker(constant.StreamKeepAlive)
+			defer keepAlive.Stop()
+			for {
+				select {
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					data, er := json.Marshal(event)
+					if er != nil {
+						h.log.Error("MonitorStream marshal", zap.Error(er))
+						continue
+					}
+					if _, er = fmt.Fprintf(w, "id: %s\nevent: state\ndata: %s\n\n", event.ID, data); er != nil {
+						return
+					}
+				case <-keepAlive.C:
+					if _, er := w.WriteString(": keep-alive\n\n"); er != nil {
+						return
+					}
+				}
+				// client gone
+				if er := w.Flush(); er != nil {
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
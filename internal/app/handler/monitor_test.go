@@ -1,17 +1,27 @@
 package handler_test
 
 import (
+	"bufio"
 	"bytes"
+	"charts_analyser/internal/app/config"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
+	"charts_analyser/internal/app/handler"
+	"charts_analyser/internal/app/service"
+	"context"
 	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"io"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func (suite *HandlerTestSuite) TestVesselState() {
@@ -337,6 +347,66 @@ func (suite *HandlerTestSuite) TestSetControl() {
 				contentType: "text/plain",
 			},
 		},
+		{
+			name: "Set control. Window Ok",
+			args: args{
+				method: http.MethodPost,
+				query: []interface{}{
+					domain.ControlItem{
+						VesselID: suite.cfg.VesselID,
+						Start:    &[]time.Time{time.Now().Add(24 * time.Hour)}[0],
+						End:      &[]time.Time{time.Now().Add(48 * time.Hour)}[0],
+					},
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:        http.StatusOK,
+				responseLen: &[]bool{true}[0],
+				contentType: "text/plain",
+			},
+		},
+		{
+			name: "Set control. Window end in past",
+			args: args{
+				method: http.MethodPost,
+				query: []interface{}{
+					domain.ControlItem{
+						VesselID: suite.cfg.VesselID,
+						End:      &[]time.Time{time.Now().Add(-time.Hour)}[0],
+					},
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:            http.StatusBadRequest,
+				responseContain: "end",
+			},
+		},
+		{
+			name: "Set control. Window end before start",
+			args: args{
+				method: http.MethodPost,
+				query: []interface{}{
+					domain.ControlItem{
+						VesselID: suite.cfg.VesselID,
+						Start:    &[]time.Time{time.Now().Add(48 * time.Hour)}[0],
+						End:      &[]time.Time{time.Now().Add(24 * time.Hour)}[0],
+					},
+				},
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:            http.StatusBadRequest,
+				responseContain: "start",
+			},
+		},
 		{
 			name: "Set control. Bad vessel ids",
 			args: args{
@@ -583,3 +653,708 @@ func (suite *HandlerTestSuite) TestDeleteControl() {
 		})
 	}
 }
+
+func (suite *HandlerTestSuite) TestMonitorStream() {
+	t := suite.T()
+	type want struct {
+		code            int
+		response        *string
+		responseContain string
+		contentType     string
+	}
+	type args struct {
+		method  string
+		query   string
+		headers map[string]string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "Monitor stream. No jwt",
+			args: args{
+				method: http.MethodGet,
+			},
+			want: want{
+				code:        http.StatusUnauthorized,
+				response:    &[]string{"Missing or malformed JWT"}[0],
+				contentType: "text/plain",
+			},
+		},
+		{
+			name: "Monitor stream. Wrong role in jwt",
+			args: args{
+				method: http.MethodGet,
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtVessel,
+				},
+			},
+			want: want{
+				code: http.StatusForbidden,
+			},
+		},
+		{
+			name: "Monitor stream. Unknown resume token",
+			args: args{
+				method: http.MethodGet,
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+					"Last-Event-ID": "unknown.10",
+				},
+			},
+			want: want{
+				code:            http.StatusGone,
+				responseContain: "resume token expired",
+			},
+		},
+		{
+			name: "Monitor stream. Unknown resume token in query",
+			args: args{
+				method: http.MethodGet,
+				query:  "?vesselIDs=" + strconv.FormatInt(int64(suite.cfg.VesselID), 10) + "&lastEventID=bad",
+				headers: map[string]string{
+					"Authorization": "Bearer " + suite.cfg.jwtOperator,
+				},
+			},
+			want: want{
+				code:            http.StatusGone,
+				responseContain: "resume token expired",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			request, err := http.NewRequest(test.args.method, constant.RouteAPI+constant.RouteMonitor+constant.RouteStream+test.args.query, nil)
+			require.NoError(t, err)
+
+			if len(test.args.headers) > 0 {
+				for k, v := range test.args.headers {
+					request.Header.Set(k, v)
+				}
+			}
+
+			res, err := suite.app.Test(request)
+			require.NoError(t, err)
+
+			var resBody []byte
+			assert.Equal(t, test.want.code, res.StatusCode)
+			func() {
+				defer func(Body io.ReadCloser) {
+					err := Body.Close()
+					require.NoError(t, err)
+				}(res.Body)
+				resBody, err = io.ReadAll(res.Body)
+				require.NoError(t, err)
+			}()
+
+			if test.want.contentType != "" {
+				assert.Contains(t, res.Header.Get("Content-Type"), test.want.contentType)
+			}
+
+			if test.want.responseContain != "" {
+				cont := string(resBody)
+				assert.Contains(t, cont, test.want.responseContain)
+			}
+
+			if test.want.response != nil {
+				cont := strings.TrimSpace(string(resBody))
+				assert.Equal(t, cont, *test.want.response)
+			}
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestMonitorStreamEvents() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Stream other "+uniq))
+	require.NoError(t, err)
+	require.Len(t, vessels, 1)
+	other := vessels[0].ID
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, suite.cfg.VesselID, other))
+	vesselQuery := func(id domain.VesselID) string {
+		return "?vesselIDs=" + id.String()
+	}
+
+	t.Run("Monitor stream. State change", func(t *testing.T) {
+		code, frames := suite.stream(t, suite.cfg.jwtOperator, vesselQuery(suite.cfg.VesselID), "")
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+
+		frame := nextFrame(t, frames)
+		assert.NotEmpty(t, frame.id)
+		assert.Equal(t, frame.id, frame.event.ID)
+		assert.Equal(t, suite.cfg.VesselID, frame.event.State.ID)
+		require.NotNil(t, frame.event.State.Location)
+		assert.Equal(t, domain.Point{10, 40}, *frame.event.State.Location)
+	})
+
+	t.Run("Monitor stream. Filter by vessels", func(t *testing.T) {
+		code, frames := suite.stream(t, suite.cfg.jwtOperator, vesselQuery(other), "")
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+		require.NoError(t, suite.srv.Track(ctx, other, domain.InputPoint{12.12, 12.12}))
+
+		frame := nextFrame(t, frames)
+		assert.Equal(t, other, frame.event.State.ID)
+	})
+
+	t.Run("Monitor stream. Resume", func(t *testing.T) {
+		code, frames := suite.stream(t, suite.cfg.jwtOperator, vesselQuery(suite.cfg.VesselID), "")
+		require.Equal(t, http.StatusOK, code)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+		}
+		require.NoError(t, suite.srv.Track(ctx, other, domain.InputPoint{12.12, 12.12}))
+		first := nextFrame(t, frames)
+		missed := []string{nextFrame(t, frames).id, nextFrame(t, frames).id}
+
+		code, frames = suite.stream(t, suite.cfg.jwtOperator, vesselQuery(suite.cfg.VesselID), first.id)
+		require.Equal(t, http.StatusOK, code)
+		resumed := []string{nextFrame(t, frames).id, nextFrame(t, frames).id}
+		assert.Equal(t, missed, resumed)
+
+		code, frames = suite.stream(t, suite.cfg.jwtOperator, vesselQuery(suite.cfg.VesselID)+"&lastEventID="+missed[1], "")
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+		frame := nextFrame(t, frames)
+		assert.NotContains(t, append(missed, first.id), frame.id)
+		assert.Equal(t, suite.cfg.VesselID, frame.event.State.ID)
+	})
+}
+
+// streamFrame server-sent event of monitor stream
+type streamFrame struct {
+	id    string
+	event domain.StateEvent
+}
+
+// stream of monitor events read by client of app on loopback, app.Test waits for the end of response.
+// Response is closed at the end of test t
+func (suite *HandlerTestSuite) stream(t *testing.T, jwt, query, lastEventID string) (code int, frames <-chan streamFrame) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	_ = handler.NewHandler(app, suite.srv, suite.cfg.Config, zap.NewNop()).Handler()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = app.Listener(ln)
+	}()
+
+	request, err := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+constant.RouteAPI+constant.RouteMonitor+constant.RouteStream+query, nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer "+jwt)
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		_ = res.Body.Close()
+		_ = app.ShutdownWithTimeout(time.Second)
+	})
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode, nil
+	}
+
+	ch := make(chan streamFrame)
+	go func() {
+		defer close(ch)
+		var frame streamFrame
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				frame.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &frame.event); err != nil {
+					return
+				}
+			case line == "" && frame.id != "":
+				select {
+				case ch <- frame:
+				case <-done:
+					return
+				}
+				frame = streamFrame{}
+			}
+		}
+	}()
+	return res.StatusCode, ch
+}
+
+// nextFrame of stream, fails if there is no event in time
+func nextFrame(t *testing.T, frames <-chan streamFrame) streamFrame {
+	select {
+	case frame, ok := <-frames:
+		require.True(t, ok, "stream closed")
+		return frame
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no stream event")
+	}
+	return streamFrame{}
+}
+
+func (suite *HandlerTestSuite) TestContactWatchdog() {
+	t := suite.T()
+	ctx := context.Background()
+
+	contact := &config.Contact{ContactStaleAfter: 0, ContactLostAfter: 60 * 60}
+	monitor := service.NewMonitorService(suite.repo, contact, zap.NewNop(), suite.srv.Stream)
+
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, suite.cfg.VesselID))
+	require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{12.12, 12.12}))
+
+	monitored := func(t *testing.T) (vessel domain.MonitoredVessel) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteMonitor, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var vessels []domain.MonitoredVessel
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&vessels))
+		for _, v := range vessels {
+			if v.ID == suite.cfg.VesselID {
+				return v
+			}
+		}
+		require.Fail(t, "vessel is not monitored")
+		return
+	}
+
+	t.Run("Contact. Ok after track", func(t *testing.T) {
+		v := monitored(t)
+		assert.Equal(t, domain.ContactOK, v.Contact)
+		assert.NotNil(t, v.LastSeen)
+	})
+
+	t.Run("Contact. Stale", func(t *testing.T) {
+		require.NoError(t, monitor.CheckContact(ctx))
+		assert.Equal(t, domain.ContactStale, monitored(t).Contact)
+		states, err := suite.srv.GetStates(ctx, suite.cfg.VesselID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ContactStale, states[0].Contact)
+	})
+
+	t.Run("Contact. Lost", func(t *testing.T) {
+		contact.ContactLostAfter = 0
+		require.NoError(t, monitor.CheckContact(ctx))
+		assert.Equal(t, domain.ContactLost, monitored(t).Contact)
+	})
+
+	t.Run("Contact. Not restored by point older than threshold", func(t *testing.T) {
+		chart := service.NewChartService(suite.repo, contact, suite.srv.Stream, suite.srv.Alert)
+		require.NoError(t, chart.TrackAt(ctx, suite.cfg.VesselID, domain.InputPoint{12.12, 12.12}, time.Now()))
+		assert.Equal(t, domain.ContactLost, monitored(t).Contact)
+		require.NoError(t, monitor.CheckContact(ctx))
+		assert.Equal(t, domain.ContactLost, monitored(t).Contact)
+	})
+
+	t.Run("Contact. Restored by track", func(t *testing.T) {
+		require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{12.12, 12.12}))
+		assert.Equal(t, domain.ContactOK, monitored(t).Contact)
+	})
+}
+
+func (suite *HandlerTestSuite) TestControlSchedule() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Scheduled vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+
+	send := func(t *testing.T, method string, body interface{}) {
+		bodyJSON, _ := json.Marshal(body)
+		request, err := http.NewRequest(method, constant.RouteAPI+constant.RouteMonitor, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	}
+	schedule := func(t *testing.T) (windows []domain.ControlWindow) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteMonitor+constant.RouteSchedule, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var all []domain.ControlWindow
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&all))
+		for _, w := range all {
+			if w.Vessel.ID == vesselID {
+				windows = append(windows, w)
+			}
+		}
+		return
+	}
+	controlState := func(t *testing.T) bool {
+		states, err := suite.srv.GetStates(ctx, vesselID)
+		require.NoError(t, err)
+		return states[0].State
+	}
+
+	start := time.Now().Add(time.Second)
+	end := start.Add(time.Second)
+
+	t.Run("Schedule. Window saved", func(t *testing.T) {
+		send(t, http.MethodPost, []domain.ControlItem{{VesselID: vesselID, Start: &start, End: &end}})
+		windows := schedule(t)
+		require.Len(t, windows, 1)
+		assert.Nil(t, windows[0].StartedAt)
+		assert.Equal(t, "Scheduled vessel", string(windows[0].Vessel.Name))
+	})
+
+	t.Run("Schedule. Started", func(t *testing.T) {
+		time.Sleep(time.Until(start))
+		require.NoError(t, suite.srv.ApplyControlSchedule(ctx))
+		assert.True(t, controlState(t))
+		windows := schedule(t)
+		require.Len(t, windows, 1)
+		assert.NotNil(t, windows[0].StartedAt)
+	})
+
+	t.Run("Schedule. Ended", func(t *testing.T) {
+		time.Sleep(time.Until(end))
+		require.NoError(t, suite.srv.ApplyControlSchedule(ctx))
+		assert.False(t, controlState(t))
+		assert.Empty(t, schedule(t))
+	})
+
+	t.Run("Schedule. End keeps vessel added without window", func(t *testing.T) {
+		send(t, http.MethodPost, []domain.VesselID{vesselID})
+		end := time.Now().Add(time.Second)
+		send(t, http.MethodPost, []domain.ControlItem{{VesselID: vesselID, End: &end}})
+		require.Len(t, schedule(t), 1)
+
+		time.Sleep(time.Until(end))
+		require.NoError(t, suite.srv.ApplyControlSchedule(ctx))
+		assert.Empty(t, schedule(t))
+		assert.True(t, controlState(t))
+	})
+
+	t.Run("Schedule. Canceled by delete control", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		send(t, http.MethodPost, []domain.ControlItem{{VesselID: vesselID, Start: &later}})
+		require.Len(t, schedule(t), 1)
+		send(t, http.MethodDelete, []domain.VesselID{vesselID})
+		assert.Empty(t, schedule(t))
+	})
+}
+
+func (suite *HandlerTestSuite) TestControlLog() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Logged vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+	jwtOperator2, err := domain.NewClaimOperator(&suite.cfg.JWT, 13, "Test Operator 2").Token()
+	require.NoError(t, err)
+	since := time.Now()
+
+	send := func(t *testing.T, method, jwt, comment string) int {
+		bodyJSON, _ := json.Marshal([]domain.VesselID{vesselID})
+		request, err := http.NewRequest(method, constant.RouteAPI+constant.RouteMonitor+"?comment="+comment, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode
+	}
+	controlLog := func(t *testing.T, query string, headers map[string]string) (code int, logs []domain.ControlLog) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteMonitor+constant.RouteLog+query, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&logs))
+		}
+		return res.StatusCode, logs
+	}
+	operator := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}
+	byVessel := "?vesselIDs=" + strconv.FormatInt(int64(vesselID), 10)
+
+	t.Run("Control log. No jwt", func(t *testing.T) {
+		code, _ := controlLog(t, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Control log. Wrong role in jwt", func(t *testing.T) {
+		code, _ := controlLog(t, "", map[string]string{"Authorization": "Bearer " + suite.cfg.jwtVessel})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Control log. Validate. Comment too long", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(t, http.MethodPost, suite.cfg.jwtOperator, strings.Repeat("a", constant.ControlCommentMaxLen+1)))
+	})
+
+	require.Equal(t, http.StatusOK, send(t, http.MethodPost, suite.cfg.jwtOperator, "patrol"))
+	require.Equal(t, http.StatusOK, send(t, http.MethodPost, jwtOperator2, ""))
+	require.Equal(t, http.StatusOK, send(t, http.MethodDelete, suite.cfg.jwtOperator, "done"))
+
+	t.Run("Control log. By vessel", func(t *testing.T) {
+		var logs []domain.ControlLog
+		require.Eventually(t, func() bool {
+			var code int
+			code, logs = controlLog(t, byVessel, operator)
+			return code == http.StatusOK && len(logs) == 3
+		}, 5*time.Second, 100*time.Millisecond)
+		// newest first
+		assert.False(t, logs[0].Control)
+		require.NotNil(t, logs[0].Comment)
+		assert.Equal(t, "done", *logs[0].Comment)
+		require.NotNil(t, logs[0].UserID)
+		assert.Equal(t, domain.UserID(12), *logs[0].UserID)
+		assert.Equal(t, "Logged vessel", string(logs[0].Vessel.Name))
+		assert.Nil(t, logs[1].Comment)
+		require.NotNil(t, logs[2].Comment)
+		assert.Equal(t, "patrol", *logs[2].Comment)
+	})
+
+	t.Run("Control log. By operator", func(t *testing.T) {
+		code, logs := controlLog(t, byVessel+"&userIDs=13", operator)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, logs, 1)
+		assert.True(t, logs[0].Control)
+		require.NotNil(t, logs[0].UserID)
+		assert.Equal(t, domain.UserID(13), *logs[0].UserID)
+	})
+
+	t.Run("Control log. By period", func(t *testing.T) {
+		code, logs := controlLog(t, byVessel+"&finish="+since.UTC().Format(time.RFC3339Nano), operator)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, logs)
+	})
+}
+
+func (suite *HandlerTestSuite) TestMonitorSummary() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Summary in zone", "Summary outside")
+	require.NoError(t, err)
+	require.Len(t, vessels, 2)
+	inZone, outside := vessels[0].ID, vessels[1].ID
+
+	userID := domain.UserID(12)
+	watchlistID, err := suite.srv.AddWatchlist(ctx, &domain.Watchlist{Name: "Summary", OwnerID: userID})
+	require.NoError(t, err)
+	require.NoError(t, suite.srv.SetControl(ctx, domain.ControlAction{WatchlistID: watchlistID, UserID: &userID}, true, inZone, outside))
+	require.NoError(t, suite.srv.Track(ctx, inZone, domain.InputPoint{10, 40}))
+	require.NoError(t, suite.srv.Track(ctx, outside, domain.InputPoint{12.12, 12.12}))
+	time.Sleep(time.Second)
+	require.NoError(t, suite.srv.Track(ctx, inZone, domain.InputPoint{10, 40}))
+
+	summary := func(t *testing.T, query string, headers map[string]string) (code int, result domain.MonitorSummary) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteMonitor+constant.RouteSummary+query, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		}
+		return res.StatusCode, result
+	}
+	operator := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}
+
+	t.Run("Summary. No jwt", func(t *testing.T) {
+		code, _ := summary(t, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Summary. Wrong role in jwt", func(t *testing.T) {
+		code, _ := summary(t, "", map[string]string{"Authorization": "Bearer " + suite.cfg.jwtVessel})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Summary. Not member of watchlist", func(t *testing.T) {
+		code, _ := summary(t, "?watchlist=999999", operator)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Summary. Watchlist", func(t *testing.T) {
+		code, result := summary(t, "?watchlist="+strconv.FormatInt(int64(watchlistID), 10), operator)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 2, result.Monitored)
+		assert.Equal(t, 1, result.NoZone)
+		assert.Equal(t, 0, result.Lost)
+		assert.Equal(t, []domain.ZoneCount{{ZoneName: suite.cfg.ZoneName, Vessels: 1}}, result.Zones)
+		require.Len(t, result.LongestDwell, 1)
+		assert.Equal(t, inZone, result.LongestDwell[0].ID)
+		require.NotNil(t, result.LongestDwell[0].ZoneDuration)
+		assert.GreaterOrEqual(t, time.Duration(*result.LongestDwell[0].ZoneDuration), time.Second)
+	})
+
+	t.Run("Summary. All watchlists of operator", func(t *testing.T) {
+		code, result := summary(t, "", operator)
+		require.Equal(t, http.StatusOK, code)
+		assert.GreaterOrEqual(t, result.Monitored, 2)
+	})
+
+	require.NoError(t, suite.srv.DeleteWatchlists(ctx, userID, watchlistID))
+}
+
+func (suite *HandlerTestSuite) TestZoneEntries() {
+	t := suite.T()
+	ctx := context.Background()
+
+	// small harbour chart inside of test zone
+	db, err := sqlx.Connect("pgx", suite.cfg.DatabaseDSN)
+	require.NoError(t, err)
+	defer func() {
+		_, err := db.ExecContext(ctx, "DELETE FROM "+constant.DBZones+" where name = 'harbour_47'")
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+	}()
+	_, err = db.ExecContext(ctx, "INSERT INTO "+constant.DBZones+" (name, geometry) "+
+		" VALUES ('harbour_47', ST_GeomFromText('POLYGON((0 35, 1 35, 1 36, 0 36, 0 35))', 4326))")
+	require.NoError(t, err)
+	harbour := domain.ZoneName("harbour_47")
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Harbour vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, vesselID))
+
+	state := func(t *testing.T) *domain.VesselState {
+		states, err := suite.srv.GetStates(ctx, vesselID)
+		require.NoError(t, err)
+		require.Len(t, states, 1)
+		return states[0]
+	}
+	entered := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	t.Run("Zone entries. Overlapping zones entered together", func(t *testing.T) {
+		require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{0.5, 35.5}, entered))
+		s := state(t)
+		require.NotNil(t, s.CurrentZone)
+		assert.ElementsMatch(t, []domain.ZoneName{suite.cfg.ZoneName, harbour}, s.CurrentZone.Zones)
+		assert.True(t, entered.Equal(s.CurrentZone.EnteredAt(harbour)))
+		assert.True(t, entered.Equal(s.CurrentZone.EnteredAt(suite.cfg.ZoneName)))
+	})
+
+	t.Run("Zone entries. Leaving harbour keeps time in covering zone", func(t *testing.T) {
+		require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{2, 38}, entered.Add(10*time.Minute)))
+		require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{2, 38}, entered.Add(20*time.Minute)))
+		s := state(t)
+		require.NotNil(t, s.CurrentZone)
+		assert.Equal(t, []domain.ZoneName{suite.cfg.ZoneName}, s.CurrentZone.Zones)
+		assert.True(t, entered.Add(10*time.Minute).Equal(s.CurrentZone.TimeIn))
+		assert.True(t, entered.Equal(s.CurrentZone.EnteredAt(suite.cfg.ZoneName)))
+		assert.Equal(t, domain.ZoneDurations{suite.cfg.ZoneName: domain.Duration(20 * time.Minute)}, s.ZoneDurations)
+	})
+
+	require.NoError(t, suite.srv.CancelControl(ctx, suite.operatorAction(ctx), vesselID))
+}
+
+func (suite *HandlerTestSuite) TestPredictZoneEntries() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Approaching vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+
+	userID := domain.UserID(12)
+	watchlistID, err := suite.srv.AddWatchlist(ctx, &domain.Watchlist{Name: "Prediction", OwnerID: userID})
+	require.NoError(t, err)
+	require.NoError(t, suite.srv.SetControl(ctx, domain.ControlAction{WatchlistID: watchlistID, UserID: &userID}, true, vesselID))
+
+	// heading east to west border of test zone (lon -6.73) with ~140 m/s
+	last := time.Now().Truncate(time.Second)
+	require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{-7, 40}, last.Add(-time.Minute)))
+	require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{-6.9, 40}, last))
+
+	predict := func(t *testing.T, query string, headers map[string]string) (code int, entries []domain.ZoneEntry) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteMonitor+constant.RoutePredict+
+			"?watchlist="+strconv.FormatInt(int64(watchlistID), 10)+query, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&entries))
+		}
+		return res.StatusCode, entries
+	}
+	operator := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}
+
+	t.Run("Predict. No jwt", func(t *testing.T) {
+		code, _ := predict(t, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Predict. Wrong role in jwt", func(t *testing.T) {
+		code, _ := predict(t, "", map[string]string{"Authorization": "Bearer " + suite.cfg.jwtVessel})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Predict. Validate. Horizon too long", func(t *testing.T) {
+		code, _ := predict(t, "&horizon="+strconv.Itoa(constant.PredictionHorizonMax+1), operator)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Predict. Zone entry within horizon", func(t *testing.T) {
+		code, entries := predict(t, "", operator)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, entries, 1)
+		assert.Equal(t, vesselID, entries[0].ID)
+		assert.Equal(t, suite.cfg.ZoneName, entries[0].ZoneName)
+		assert.True(t, last.Equal(entries[0].Timestamp))
+		assert.InDelta(t, 90, entries[0].Course, 1)
+		assert.InDelta(t, 142, entries[0].Speed, 5)
+		assert.WithinRange(t, entries[0].ETA, last.Add(80*time.Second), last.Add(120*time.Second))
+	})
+
+	t.Run("Predict. Zone entry beyond horizon", func(t *testing.T) {
+		code, entries := predict(t, "&horizon=60", operator)
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, entries)
+	})
+
+	require.NoError(t, suite.srv.DeleteWatchlists(ctx, userID, watchlistID))
+}
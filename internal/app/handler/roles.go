@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// Roles
+// @Tags        Role
+// @Summary     Роли с разрешениями
+// @Description ID роли - бит маски role пользователя, права пользователя - объединение разрешений его ролей
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.Role
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /roles [get]
+// @Security    BearerAuth
+func (h *Handler) Roles() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Role.Roles(ctx)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get roles", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddRole
+// @Tags        Role
+// @Summary     Добавление роли
+// @Description название уникально, ID - свободный бит маски ролей. Разрешения: chart:read, track:read, track:write,
+// @Description monitor:read, monitor:write, alert:read, alert:write, watchlist:read, watchlist:write, group:read, group:write,
+// @Description vessel:read, vessel:write, vessel:export, vessel:purge, credential:read, credential:write,
+// @Description webhook:read, webhook:write, user:read, user:write, role:read, role:write
+// @Accept      json
+// @Produce     json
+// @Param       Role  body      domain.Role    true "роль"
+// @Success     201   {integer} constant.Role
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     409
+// @Failure     500
+// @Router      /roles [post]
+// @Security    BearerAuth
+func (h *Handler) AddRole() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			role domain.Role
+		)
+		err = c.BodyParser(&role)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Role.AddRole(ctx, &role)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrDuplicateRecord) {
+				c.Status(http.StatusConflict)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add role", zap.Error(err), zap.Any("role", role))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateRole
+// @Tags        Role
+// @Summary     Изменение роли
+// @Description название и разрешения, список разрешений заменяется целиком. Роль admin сохраняет role:write
+// @Accept      json
+// @Produce     json
+// @Param       Role  body     domain.Role    true "роль"
+// @Success     200   {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     409
+// @Failure     500
+// @Router      /roles [put]
+// @Security    BearerAuth
+func (h *Handler) UpdateRole() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			role domain.Role
+		)
+		err = c.BodyParser(&role)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Role.UpdateRole(ctx, &role)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrDuplicateRecord) {
+				c.Status(http.StatusConflict)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error update role", zap.Error(err), zap.Any("role", role))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// DeleteRoles
+// @Tags        Role
+// @Summary     Удаление ролей
+// @Description встроенные роли и роли, назначенные пользователям (в т.ч. удаленным) или не отозванным API ключам, не удаляются
+// @Accept      json
+// @Produce     json
+// @Param       RoleIDs   body     []constant.Role    true "список ID ролей"
+// @Success     200       {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     409       {string} string "роль используется"
+// @Failure     500
+// @Router      /roles [delete]
+// @Security    BearerAuth
+func (h *Handler) DeleteRoles() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			RoleIDs []constant.Role
+		)
+		err = c.BodyParser(&RoleIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(RoleIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Role.DeleteRoles(ctx, RoleIDs...)
+		if err != nil {
+			if errors.Is(err, myErr.ErrRoleInUse) {
+				_, err = c.Status(http.StatusConflict).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error delete roles", zap.Error(err), zap.Any("ids", RoleIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
@@ -0,0 +1,167 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestRoles() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	send := func(t *testing.T, token, method, route string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	token := func(t *testing.T, role constant.Role) string {
+		jwt, err := domain.NewClaimUser(&suite.cfg.JWT, 12, "Test Operator", role).Token()
+		require.NoError(t, err)
+		return jwt
+	}
+
+	t.Run("Roles. Analyst reads, does not change monitoring", func(t *testing.T) {
+		analyst := token(t, constant.RoleAnalyst)
+		code, _ := send(t, analyst, http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = send(t, analyst, http.MethodGet, constant.RouteMonitor, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, resBody := send(t, analyst, http.MethodPost, constant.RouteMonitor, []domain.VesselID{suite.cfg.VesselID})
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Contains(t, string(resBody), string(constant.PermMonitorWrite))
+		code, _ = send(t, analyst, http.MethodPost, constant.RouteGroups, domain.VesselGroup{Name: "Analyst " + uniq})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Roles. Fleet manager edits vessels, not users", func(t *testing.T) {
+		manager := token(t, constant.RoleFleetManager)
+		code, _ := send(t, manager, http.MethodPost, constant.RouteGroups, domain.VesselGroup{Name: "Manager " + uniq})
+		assert.Equal(t, http.StatusCreated, code)
+		code, _ = send(t, manager, http.MethodGet, constant.RouteUser, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, manager, http.MethodDelete, constant.RouteMonitor, []domain.VesselID{suite.cfg.VesselID})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, token(t, constant.RoleFleetManager|constant.RoleAnalyst), http.MethodGet, constant.RouteMonitor, nil)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Roles. Builtin roles keep access", func(t *testing.T) {
+		code, _ := send(t, suite.cfg.jwtOperator, http.MethodGet, constant.RouteRoles, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, suite.cfg.jwtVessel, http.MethodGet, constant.RouteVessels+constant.RouteList, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodGet, constant.RouteRoles, nil)
+		require.Equal(t, http.StatusOK, code)
+		var roles []domain.Role
+		require.NoError(t, json.Unmarshal(resBody, &roles))
+		require.GreaterOrEqual(t, len(roles), 5)
+		assert.Equal(t, constant.RoleVessel, roles[0].ID)
+		assert.True(t, roles[0].IsBuiltin)
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, constant.RouteRoles,
+			domain.Role{ID: constant.RoleAdmin, Name: "admin", Permissions: domain.Permissions{constant.PermRoleRead}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodDelete, constant.RouteRoles, []constant.Role{constant.RoleOperator})
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Roles. Custom role", func(t *testing.T) {
+		code, _ := send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteRoles,
+			domain.Role{Name: "Bad " + uniq, Permissions: domain.Permissions{"chart:write"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteRoles,
+			domain.Role{Name: "Webhooks " + uniq, Permissions: domain.Permissions{constant.PermWebhookRead}})
+		require.Equal(t, http.StatusCreated, code)
+		var roleID constant.Role
+		require.NoError(t, json.Unmarshal(resBody, &roleID))
+		assert.Greater(t, roleID, constant.RoleFleetManager)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteRoles, domain.Role{Name: "Webhooks " + uniq})
+		assert.Equal(t, http.StatusConflict, code)
+
+		custom := token(t, roleID)
+		code, _ = send(t, custom, http.MethodGet, constant.RouteWebhooks, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = send(t, custom, http.MethodGet, constant.RouteGroups, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, constant.RouteRoles,
+			domain.Role{ID: roleID, Name: "Webhooks " + uniq, Permissions: domain.Permissions{constant.PermGroupRead}})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, custom, http.MethodGet, constant.RouteGroups, nil)
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = send(t, custom, http.MethodGet, constant.RouteWebhooks, nil)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		password := domain.Password("Password_123")
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteUser,
+			domain.UserChange{Login: domain.UserLogin("Custom_role_" + uniq), Password: &password, Role: roleID | constant.RoleAnalyst})
+		require.Equal(t, http.StatusCreated, code)
+		code, resBody = send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteUser,
+			domain.UserChange{Login: domain.UserLogin("Unknown_role_" + uniq), Password: &password, Role: 1 << 29})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, string(resBody), "Role")
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodDelete, constant.RouteRoles, []constant.Role{roleID})
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("Roles. Delete", func(t *testing.T) {
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteRoles, domain.Role{Name: "Temporary " + uniq})
+		require.Equal(t, http.StatusCreated, code)
+		var roleID constant.Role
+		require.NoError(t, json.Unmarshal(resBody, &roleID))
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodDelete, constant.RouteRoles, []constant.Role{roleID})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, constant.RouteRoles, domain.Role{ID: roleID, Name: "Temporary " + uniq})
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Roles. Delete role of api key", func(t *testing.T) {
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodPost, constant.RouteRoles,
+			domain.Role{Name: "Key role " + uniq, Permissions: domain.Permissions{constant.PermWebhookRead}})
+		require.Equal(t, http.StatusCreated, code)
+		var roleID constant.Role
+		require.NoError(t, json.Unmarshal(resBody, &roleID))
+		key, err := suite.srv.APIKey.AddAPIKey(ctx, domain.APIKey{Name: "Key role " + uniq,
+			Role: roleID | constant.RoleAnalyst, UserScope: domain.UserScope{UserID: 1}, CreatedBy: 1})
+		require.NoError(t, err)
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodDelete, constant.RouteRoles, []constant.Role{roleID})
+		assert.Equal(t, http.StatusConflict, code)
+
+		require.NoError(t, suite.srv.APIKey.RevokeAPIKeys(ctx, key.ID))
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodDelete, constant.RouteRoles, []constant.Role{roleID})
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
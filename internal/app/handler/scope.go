@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// UserScope
+// @Tags        User
+// @Summary     Ограничение доступа пользователя
+// @Description зоны (юрисдикция) и суда или группы судов (флот), пустой список - без ограничения
+// @Accept      json
+// @Produce     json
+// @Param       id    path     integer    true "ID пользователя"
+// @Success     200   {object} domain.UserScope
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /user/{id}/scope [get]
+// @Security    BearerAuth
+func (h *Handler) UserScope() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.UserID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Scope.UserScope(ctx, id)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get user scope", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// SetUserScope
+// @Tags        User
+// @Summary     Изменение ограничения доступа пользователя
+// @Description Пользователь видит в картах, треках, текущих данных и списке мониторинга только суда из vesselIDs
+// @Description и текущих судов групп groupIDs, только в зонах zoneNames: треки - точки в этих зонах, текущие
+// @Description данные и мониторинг - суда, находящиеся в них сейчас. Все списки пустые - ограничение снимается
+// @Accept      json
+// @Produce     json
+// @Param       id         path     integer             true "ID пользователя"
+// @Param       UserScope  body     domain.UserScope    true "ограничение"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /user/{id}/scope [put]
+// @Security    BearerAuth
+func (h *Handler) SetUserScope() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			scope domain.UserScope
+		)
+		err = c.BodyParser(&scope)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		if err = scope.UserID.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Scope.SetUserScope(ctx, &scope)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error set user scope", zap.Error(err), zap.Any("scope", scope))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// scope restriction of caller: of api key or user, responds 500 on error
+func (h *Handler) scope(ctx context.Context, c *fiber.Ctx) (scope *domain.Scope, ok bool) {
+	var err error
+	if apiKey := GetAPIKey(c); apiKey != nil {
+		scope, err = h.s.Scope.APIKeyScope(ctx, apiKey)
+	} else {
+		scope, err = h.s.Scope.Scope(ctx, GetUserID(c))
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		h.log.Error("Error get scope", zap.Error(err))
+		return nil, false
+	}
+	return scope, true
+}
@@ -0,0 +1,220 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func (suite *HandlerTestSuite) TestUserScope() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	password := domain.Password("Pa$$w0rd")
+	login := domain.UserLogin("Test_scope_" + uniq)
+	userID, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: login, Password: &password, Role: constant.RoleOperator})
+	require.NoError(t, err)
+	jwtScoped, err := domain.NewClaimOperator(&suite.cfg.JWT, userID, login).Token()
+	require.NoError(t, err)
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Scope fleet in zone "+uniq),
+		domain.VesselName("Scope fleet outside "+uniq), domain.VesselName("Scope other "+uniq))
+	require.NoError(t, err)
+	require.Len(t, vessels, 3)
+	inZone, outside, other := vessels[0].ID, vessels[1].ID, vessels[2].ID
+	_, err = suite.srv.AddAlertRule(ctx, &domain.AlertRule{
+		Name:      "Scope enter " + uniq,
+		VesselIDs: domain.VesselIDs{inZone, other},
+		ZoneNames: domain.ZoneNames{suite.cfg.ZoneName},
+		Event:     domain.AlertEventEnter,
+	})
+	require.NoError(t, err)
+
+	watchlistID, err := suite.srv.UserWatchlist(ctx, userID, 0)
+	require.NoError(t, err)
+	require.NoError(t, suite.srv.SetControl(ctx, domain.ControlAction{WatchlistID: watchlistID, UserID: &userID}, true, vessels.IDs()...))
+	require.NoError(t, suite.srv.Track(ctx, inZone, domain.InputPoint{12.12, 12.12}))
+	require.NoError(t, suite.srv.Track(ctx, outside, domain.InputPoint{12.12, 12.12}))
+	require.NoError(t, suite.srv.Track(ctx, other, domain.InputPoint{10, 40}))
+	time.Sleep(time.Second)
+	require.NoError(t, suite.srv.Track(ctx, inZone, domain.InputPoint{10, 40}))
+
+	send := func(t *testing.T, jwt, method, route string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	scopeRoute := constant.RouteUser + "/" + userID.String() + constant.RouteScope
+	track := func(t *testing.T, vesselID domain.VesselID) (code int, tracks []domain.Track) {
+		code, resBody := send(t, jwtScoped, http.MethodGet, constant.RouteTrack+"/"+vesselID.String(), nil)
+		if code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resBody, &tracks))
+		}
+		return
+	}
+	monitored := func(t *testing.T) (vesselIDs domain.VesselIDs) {
+		code, resBody := send(t, jwtScoped, http.MethodGet, constant.RouteMonitor, nil)
+		require.Equal(t, http.StatusOK, code)
+		var result []domain.MonitoredVessel
+		require.NoError(t, json.Unmarshal(resBody, &result))
+		for _, v := range result {
+			vesselIDs = append(vesselIDs, v.ID)
+		}
+		return
+	}
+
+	t.Run("User scope. Set", func(t *testing.T) {
+		code, _ := send(t, jwtScoped, http.MethodPut, scopeRoute, domain.UserScope{})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, constant.RouteUser+"/100500000"+constant.RouteScope,
+			domain.UserScope{VesselIDs: domain.VesselIDs{inZone}})
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, scopeRoute, domain.UserScope{VesselIDs: domain.VesselIDs{0}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = send(t, suite.cfg.jwtAdmin, http.MethodPut, scopeRoute,
+			domain.UserScope{ZoneNames: domain.ZoneNames{suite.cfg.ZoneName}, VesselIDs: domain.VesselIDs{inZone, outside}})
+		require.Equal(t, http.StatusOK, code)
+		code, resBody := send(t, suite.cfg.jwtAdmin, http.MethodGet, scopeRoute, nil)
+		require.Equal(t, http.StatusOK, code)
+		var scope domain.UserScope
+		require.NoError(t, json.Unmarshal(resBody, &scope))
+		assert.Equal(t, domain.ZoneNames{suite.cfg.ZoneName}, scope.ZoneNames)
+		assert.Equal(t, domain.VesselIDs{inZone, outside}, scope.VesselIDs)
+	})
+
+	t.Run("User scope. Charts and tracks", func(t *testing.T) {
+		code, resBody := send(t, jwtScoped, http.MethodPost, constant.RouteChart+constant.RouteZones,
+			domain.InputVesselsInterval{InputVessels: domain.InputVessels{VesselIDs: domain.VesselIDs{other}}})
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, "[]", string(resBody))
+
+		code, resBody = send(t, jwtScoped, http.MethodPost, constant.RouteChart+constant.RouteVessels,
+			domain.InputZones{ZoneNames: []domain.ZoneName{suite.cfg.ZoneName}})
+		require.Equal(t, http.StatusOK, code)
+		var vesselIDs domain.VesselIDs
+		require.NoError(t, json.Unmarshal(resBody, &vesselIDs))
+		assert.Contains(t, vesselIDs, inZone)
+		assert.NotContains(t, vesselIDs, other)
+
+		code, tracks := track(t, inZone)
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, tracks, 1)
+		code, _ = track(t, other)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("User scope. Monitoring", func(t *testing.T) {
+		code, resBody := send(t, jwtScoped, http.MethodPost, constant.RouteMonitor+constant.RouteState, vessels.IDs())
+		require.Equal(t, http.StatusOK, code)
+		var states []domain.VesselState
+		require.NoError(t, json.Unmarshal(resBody, &states))
+		require.Len(t, states, 1)
+		assert.Equal(t, inZone, states[0].ID)
+
+		assert.Equal(t, domain.VesselIDs{inZone}, monitored(t))
+	})
+
+	t.Run("User scope. Summary, prediction, control log and alerts", func(t *testing.T) {
+		code, resBody := send(t, jwtScoped, http.MethodGet, constant.RouteMonitor+constant.RouteSummary, nil)
+		require.Equal(t, http.StatusOK, code)
+		var summary domain.MonitorSummary
+		require.NoError(t, json.Unmarshal(resBody, &summary))
+		assert.Equal(t, 1, summary.Monitored)
+		assert.Equal(t, 0, summary.NoZone)
+		require.NotEmpty(t, summary.Zones)
+		for _, zone := range summary.Zones {
+			assert.Equal(t, suite.cfg.ZoneName, zone.ZoneName)
+		}
+		for _, dwell := range summary.LongestDwell {
+			assert.Equal(t, inZone, dwell.ID)
+		}
+
+		code, resBody = send(t, jwtScoped, http.MethodGet, constant.RouteMonitor+constant.RoutePredict, nil)
+		require.Equal(t, http.StatusOK, code)
+		var entries []domain.ZoneEntry
+		require.NoError(t, json.Unmarshal(resBody, &entries))
+		for _, entry := range entries {
+			assert.Equal(t, inZone, entry.ID)
+			assert.Equal(t, suite.cfg.ZoneName, entry.ZoneName)
+		}
+
+		code, resBody = send(t, jwtScoped, http.MethodGet, constant.RouteMonitor+constant.RouteLog, nil)
+		require.Equal(t, http.StatusOK, code)
+		var logs []domain.ControlLog
+		require.NoError(t, json.Unmarshal(resBody, &logs))
+		require.NotEmpty(t, logs)
+		for _, controlLog := range logs {
+			require.NotNil(t, controlLog.Vessel)
+			assert.Equal(t, inZone, controlLog.Vessel.ID)
+		}
+
+		code, resBody = send(t, jwtScoped, http.MethodGet, constant.RouteAlerts, nil)
+		require.Equal(t, http.StatusOK, code)
+		var alerts []domain.Alert
+		require.NoError(t, json.Unmarshal(resBody, &alerts))
+		require.NotEmpty(t, alerts)
+		for _, alert := range alerts {
+			assert.Equal(t, inZone, alert.Vessel.ID)
+			assert.Equal(t, suite.cfg.ZoneName, alert.ZoneName)
+		}
+	})
+
+	t.Run("User scope. Remove", func(t *testing.T) {
+		code, _ := send(t, suite.cfg.jwtAdmin, http.MethodPut, scopeRoute, domain.UserScope{})
+		require.Equal(t, http.StatusOK, code)
+
+		code, tracks := track(t, inZone)
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, tracks, 2)
+		assert.ElementsMatch(t, vessels.IDs(), monitored(t))
+	})
+
+	t.Run("User scope. Monitor stream", func(t *testing.T) {
+		code, _ := send(t, suite.cfg.jwtAdmin, http.MethodPut, scopeRoute,
+			domain.UserScope{ZoneNames: domain.ZoneNames{suite.cfg.ZoneName}, VesselIDs: domain.VesselIDs{inZone, outside}})
+		require.Equal(t, http.StatusOK, code)
+
+		code, _ = suite.stream(t, jwtScoped, "?vesselIDs="+other.String(), "")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, frames := suite.stream(t, jwtScoped, "", "")
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, suite.srv.Track(ctx, outside, domain.InputPoint{12.12, 12.12}))
+		require.NoError(t, suite.srv.Track(ctx, other, domain.InputPoint{10, 40}))
+		require.NoError(t, suite.srv.Track(ctx, inZone, domain.InputPoint{10, 40}))
+		frame := nextFrame(t, frames)
+		assert.Equal(t, inZone, frame.event.State.ID)
+
+		code, frames = suite.stream(t, jwtScoped, "?vesselIDs="+outside.String(), frame.id)
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, suite.srv.Track(ctx, outside, domain.InputPoint{10, 40}))
+		frame = nextFrame(t, frames)
+		assert.Equal(t, outside, frame.event.State.ID)
+	})
+}
@@ -11,16 +11,23 @@ import (
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"time"
 )
 
 // Login
 // @Tags        User
 // @Summary     Идентификация
-// @Description Получение токена
+// @Description Получение короткоживущего токена доступа и refresh токена для его обновления (POST /refresh).
+// @Description Как и раньше, тело ответа - токен доступа (text/plain), refresh токен и срок его действия - в заголовках
+// @Description X-Refresh-Token, X-Refresh-Expires-At. С Accept: application/json - все в теле (domain.AuthTokens)
 // @Accept      json
+// @Produce     plain
 // @Produce     json
 // @Param       UserAuth   body     domain.LoginForm    true "Логин, пароль"
-// @Success     200        {string} string "JWT token"
+// @Success     200        {string} string "токен доступа"
+// @Success     200        {object} domain.AuthTokens
+// @Header      200        {string} X-Refresh-Token "refresh токен"
+// @Header      200        {string} X-Refresh-Expires-At "срок действия refresh токена"
 // @Failure     400
 // @Failure     401
 // @Failure     403
@@ -52,15 +59,190 @@ func (h *Handler) Login() fiber.Handler {
 			h.log.Error("Error login", zap.Error(err), zap.Any("login", login))
 			return nil
 		}
-		_, err = c.Status(http.StatusOK).WriteString(result)
+		if c.Accepts(fiber.MIMETextPlain, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+			return c.Status(http.StatusOK).JSON(result)
+		}
+		c.Set(constant.HeaderRefreshToken, result.RefreshToken)
+		c.Set(constant.HeaderRefreshExpiresAt, result.RefreshExpiresAt.Format(time.RFC3339))
+		_, err = c.Status(http.StatusOK).WriteString(result.AccessToken)
+		return
+	}
+}
+
+// Refresh
+// @Tags        User
+// @Summary     Обновление токена
+// @Description Новая пара токенов по refresh токену. Refresh токен одноразовый: повторное использование
+// @Description отзывает все токены этого входа (401)
+// @Accept      json
+// @Produce     json
+// @Param       InputRefresh   body     domain.InputRefresh    true "refresh токен"
+// @Success     200        {object} domain.AuthTokens
+// @Failure     400
+// @Failure     401        {string} string "токен недействителен"
+// @Failure     500
+// @Router      /refresh [post]
+func (h *Handler) Refresh() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			input domain.InputRefresh
+		)
+		err = c.BodyParser(&input)
+		if err != nil && !errors.Is(err, io.EOF) || input.RefreshToken == "" {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.User.Refresh(ctx, input.RefreshToken)
+		if err != nil {
+			if errors.Is(err, myErr.ErrRefreshReuse) {
+				h.log.Warn("Refresh token reused", zap.Error(err))
+				_, err = c.Status(http.StatusUnauthorized).WriteString(myErr.ErrRefreshReuse.Error())
+				return
+			}
+			if errors.Is(err, myErr.ErrRefreshToken) {
+				_, err = c.Status(http.StatusUnauthorized).WriteString(myErr.ErrRefreshToken.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error refresh", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// Logout
+// @Tags        User
+// @Summary     Выход
+// @Description Отзывает refresh токен и все токены этого входа. Выданный токен доступа действует до истечения срока
+// @Accept      json
+// @Produce     json
+// @Param       InputRefresh   body     domain.InputRefresh    true "refresh токен"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401        {string} string "токен недействителен"
+// @Failure     500
+// @Router      /logout [post]
+func (h *Handler) Logout() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			input domain.InputRefresh
+		)
+		err = c.BodyParser(&input)
+		if err != nil && !errors.Is(err, io.EOF) || input.RefreshToken == "" {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		if err = h.s.User.Logout(ctx, input.RefreshToken); err != nil {
+			if errors.Is(err, myErr.ErrRefreshToken) {
+				_, err = c.Status(http.StatusUnauthorized).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error logout", zap.Error(err))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
 		return
 	}
 }
 
+// ListUsers
+// @Tags        User
+// @Summary     Список пользователей
+// @Description постранично, по логину. Фильтры: role - пользователи с любым из битов роли, deleted - только удаленные,
+// @Description login - часть логина. Следующая страница - cursor=nextCursor с теми же фильтрами
+// @Accept      json
+// @Produce     json
+// @Param       InputUserList query    domain.InputUserList false "фильтры и страница"
+// @Success     200        {object} domain.UserPage
+// @Failure     400        {string} string "ошибка валидации"
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /user [get]
+// @Security    BearerAuth
+func (h *Handler) ListUsers() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputUserList
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.User.ListUsers(ctx, query)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error list users", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// GetUser
+// @Tags        User
+// @Summary     Пользователь
+// @Description в т.ч. удаленный
+// @Accept      json
+// @Produce     json
+// @Param       id         path     uint64    true "ID пользователя"
+// @Success     200        {object} domain.UserDB
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /user/{id} [get]
+// @Security    BearerAuth
+func (h *Handler) GetUser() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.UserID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.User.GetUserByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get user", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
 // AddUser
 // @Tags        User
 // @Summary     Добавление оператора
-// @Description
+// @Description role - сумма ID ролей (GET /roles), роль судна недопустима
 // @Accept      json
 // @Produce     json
 // @Param       UserData   body     domain.UserChange    true "данные пользователя"
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -853,3 +854,185 @@ func (suite *HandlerTestSuite) TestLogin() {
 		})
 	}
 }
+
+func (suite *HandlerTestSuite) TestRefreshToken() {
+	t := suite.T()
+	ctx := context.Background()
+	login := domain.LoginForm{
+		Login:    domain.UserLogin("Test_refresh_" + time.Now().Format(time.RFC3339Nano)),
+		Password: domain.Password("Pa$$w0rd"),
+	}
+	_, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: login.Login, Password: &login.Password, Role: constant.RoleOperator})
+	require.NoError(t, err)
+
+	send := func(t *testing.T, route string, body interface{}) (code int, resBody []byte) {
+		bodyJSON, _ := json.Marshal(body)
+		request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+route, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	tokens := func(t *testing.T, route string, body interface{}) (tokens domain.AuthTokens) {
+		code, resBody := send(t, route, body)
+		require.Equal(t, http.StatusOK, code, string(resBody))
+		require.NoError(t, json.Unmarshal(resBody, &tokens))
+		require.NotEmpty(t, tokens.AccessToken)
+		require.NotEmpty(t, tokens.RefreshToken)
+		return
+	}
+	useAccess := func(t *testing.T, accessToken string) int {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteGroups, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res.StatusCode
+	}
+
+	t.Run("Refresh. Login with text response", func(t *testing.T) {
+		bodyJSON, _ := json.Marshal(login)
+		request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+constant.RouteLogin, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		accessToken, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Content-Type"), "text/plain")
+		assert.Equal(t, http.StatusOK, useAccess(t, string(accessToken)))
+		refreshToken := res.Header.Get(constant.HeaderRefreshToken)
+		require.NotEmpty(t, refreshToken)
+		_, err = time.Parse(time.RFC3339, res.Header.Get(constant.HeaderRefreshExpiresAt))
+		assert.NoError(t, err)
+		tokens(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: refreshToken})
+	})
+
+	t.Run("Refresh. Bad token", func(t *testing.T) {
+		code, _ := send(t, constant.RouteRefresh, domain.InputRefresh{})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = send(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: "not a token"})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		code, _ = send(t, constant.RouteLogout, domain.InputRefresh{RefreshToken: "not a token"})
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Refresh. Rotation and reuse", func(t *testing.T) {
+		first := tokens(t, constant.RouteLogin, login)
+		assert.True(t, first.ExpiresAt.Before(first.RefreshExpiresAt))
+		assert.Equal(t, http.StatusOK, useAccess(t, first.AccessToken))
+
+		second := tokens(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: first.RefreshToken})
+		assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+		assert.Equal(t, http.StatusOK, useAccess(t, second.AccessToken))
+
+		// reuse of rotated token revokes the family
+		code, resBody := send(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: first.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, code)
+		assert.Contains(t, string(resBody), "reused")
+		code, _ = send(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: second.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Refresh. Logout", func(t *testing.T) {
+		first := tokens(t, constant.RouteLogin, login)
+		second := tokens(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: first.RefreshToken})
+		other := tokens(t, constant.RouteLogin, login)
+
+		code, _ := send(t, constant.RouteLogout, domain.InputRefresh{RefreshToken: second.RefreshToken})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: second.RefreshToken})
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		// other login is not affected
+		tokens(t, constant.RouteRefresh, domain.InputRefresh{RefreshToken: other.RefreshToken})
+	})
+}
+
+func (suite *HandlerTestSuite) TestListUsers() {
+	t := suite.T()
+	ctx := context.Background()
+	prefix := "Test_list_" + strconv.FormatInt(time.Now().UnixNano(), 36) + "_"
+	password := domain.Password("Pa$$w0rd")
+	ids := make([]domain.UserID, 0, 4)
+	for i, role := range []constant.Role{constant.RoleOperator, constant.RoleOperator, constant.RoleAdmin, constant.RoleOperator} {
+		id, err := suite.srv.AddUser(ctx, &domain.UserChange{Login: domain.UserLogin(prefix + strconv.Itoa(i)), Password: &password, Role: role})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	require.NoError(t, suite.srv.SetDeletedUser(ctx, true, ids[3]))
+
+	send := func(t *testing.T, route, jwt string) (code int, resBody []byte) {
+		request, err := http.NewRequest(http.MethodGet, constant.RouteAPI+constant.RouteUser+route, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	list := func(t *testing.T, query string) (page domain.UserPage) {
+		code, resBody := send(t, "?login="+prefix+query, suite.cfg.jwtAdmin)
+		require.Equal(t, http.StatusOK, code, string(resBody))
+		assert.NotContains(t, string(resBody), "hash")
+		require.NoError(t, json.Unmarshal(resBody, &page))
+		return
+	}
+
+	t.Run("List users. Operator", func(t *testing.T) {
+		code, _ := send(t, "", suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, "/"+ids[0].String(), suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("List users. Pages", func(t *testing.T) {
+		page := list(t, "&limit=2")
+		require.Equal(t, 2, len(page.Users))
+		assert.Equal(t, ids[0], page.Users[0].ID)
+		assert.Equal(t, ids[1], page.Users[1].ID)
+		require.NotEmpty(t, page.NextCursor)
+
+		page = list(t, "&limit=2&cursor="+page.NextCursor)
+		require.Equal(t, 1, len(page.Users))
+		assert.Equal(t, ids[2], page.Users[0].ID)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("List users. Filters", func(t *testing.T) {
+		page := list(t, "&role="+strconv.Itoa(int(constant.RoleAdmin)))
+		require.Equal(t, 1, len(page.Users))
+		assert.Equal(t, ids[2], page.Users[0].ID)
+
+		page = list(t, "&deleted=true")
+		require.Equal(t, 1, len(page.Users))
+		assert.Equal(t, ids[3], page.Users[0].ID)
+		assert.True(t, page.Users[0].IsDeleted)
+
+		code, _ := send(t, "?cursor=wrong", suite.cfg.jwtAdmin)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("List users. By id", func(t *testing.T) {
+		code, resBody := send(t, "/"+ids[3].String(), suite.cfg.jwtAdmin)
+		require.Equal(t, http.StatusOK, code)
+		assert.NotContains(t, string(resBody), "hash")
+		var user domain.UserDB
+		require.NoError(t, json.Unmarshal(resBody, &user))
+		assert.Equal(t, domain.UserLogin(prefix+"3"), user.Login)
+		assert.True(t, user.IsDeleted)
+
+		code, _ = send(t, "/100500000", suite.cfg.jwtAdmin)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	myErr "charts_analyser/internal/app/error"
 	"context"
 	"errors"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 	"io"
@@ -50,17 +51,81 @@ func (h *Handler) AddVessel() fiber.Handler {
 	}
 }
 
+// ImportVessels
+// @Tags        Vessel
+// @Summary     Импорт судов из CSV
+// @Description CSV с заголовком, колонки id (необязательна) и name, остальные колонки пропускаются.
+// @Description Судно с id создается с этим id или переименовывается, без id - создается по названию (существующее возвращается).
+// @Description Результат по строкам: created, updated, existing, invalid (ошибка в строке), conflict (название другого судна).
+// @Description С dryRun=true строки проверяются без сохранения
+// @Accept      mpfd
+// @Produce     json
+// @Param       file               formData file                     true  "CSV файл"
+// @Param       InputVesselImport  query    domain.InputVesselImport false "проверка без сохранения"
+// @Success     200           {object} domain.VesselImport
+// @Failure     400           {string} string "ошибка файла"
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /vessels/import [post]
+// @Security    BearerAuth
+func (h *Handler) ImportVessels() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputVesselImport
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		fileHeader, err := c.FormFile(constant.VesselImportFile)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		defer func() { _ = file.Close() }()
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.ImportVessels(ctx, file, query.DryRun)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error import vessels", zap.Error(err), zap.String("file", fileHeader.Filename))
+			return nil
+		}
+		if !query.DryRun {
+			h.log.Info("Vessels imported", zap.Any("userID", GetUserID(c)), zap.Int("created", result.Created),
+				zap.Int("updated", result.Updated))
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
 // UpdateVessel
 // @Tags        Vessel
 // @Summary     Изменение судна
-// @Description Смена названия судна, для не удаленных
+// @Description Смена названия и атрибутов (IMO, MMSI, позывной, флаг, тип, длина, ширина) судна, для не удаленных.
+// @Description Атрибуты заменяются целиком: не переданный атрибут очищается.
+// @Description Смена названия записывается в историю названий с момента renamedAt (по умолчанию - сейчас)
 // @Accept      json
 // @Produce     json
-// @Param       VesselNames   body     []domain.Vessel    true "список названий судов"
+// @Param       VesselNames   body     []domain.Vessel    true "список судов"
+// @Param       InputRename   query    domain.InputRename false "время переименования"
 // @Success     200           {object} []domain.Vessel    "успешно обновлённые суда"
-// @Failure     400
+// @Failure     400           {string} string "ошибка валидации"
 // @Failure     401
 // @Failure     403
+// @Failure     409           "IMO или MMSI уже заданы другому судну"
 // @Failure     500
 // @Router      /vessels [put]
 // @Security    BearerAuth
@@ -68,17 +133,32 @@ func (h *Handler) UpdateVessel() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
 		var (
 			Vessels []domain.Vessel
+			rename  domain.InputRename
 		)
 		err = c.BodyParser(&Vessels)
 		if err != nil && !errors.Is(err, io.EOF) || len(Vessels) == 0 {
 			c.Status(http.StatusBadRequest)
 			return nil
 		}
+		if err = c.QueryParser(&rename); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
 
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
 
-		result, err := h.s.Vessel.UpdateVessels(ctx, Vessels...)
+		result, err := h.s.Vessel.UpdateVessels(ctx, rename.RenamedAt, Vessels...)
+		var vErr validator.ValidationErrors
+		if errors.As(err, &vErr) {
+			c.Status(http.StatusBadRequest)
+			_, err = c.WriteString(err.Error())
+			return
+		}
+		if errors.Is(err, myErr.ErrDuplicateRecord) {
+			c.Status(http.StatusConflict)
+			return nil
+		}
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			h.log.Error("Error add vessels", zap.Error(err), zap.Any("Vessels", Vessels))
@@ -94,7 +174,7 @@ func (h *Handler) UpdateVessel() fiber.Handler {
 // @Description
 // @Accept      json
 // @Produce     json
-// @Param       vesselIDs     query    domain.InputVessels    true "список ID Судов"
+// @Param       vesselIDs     query    domain.InputVessels    true "список ID Судов и групп судов"
 // @Success     200           {object} []domain.Vessel
 // @Failure     400
 // @Failure     401
@@ -114,6 +194,10 @@ func (h *Handler) GetVessel() fiber.Handler {
 		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
 		defer cancel()
 
+		if !h.expandGroups(ctx, c, &query) {
+			return nil
+		}
+
 		result, err := h.s.Vessel.GetVessels(ctx, query.VesselIDs...)
 		if err != nil && !errors.Is(err, myErr.ErrNotExist) {
 			c.Status(http.StatusInternalServerError)
@@ -124,6 +208,129 @@ func (h *Handler) GetVessel() fiber.Handler {
 	}
 }
 
+// VesselNames
+// @Tags        Vessel
+// @Summary     История названий судна
+// @Description названия с временем начала действия validFrom (пусто - до первого переименования), по возрастанию.
+// @Description Треки, журнал контроля и оповещения показывают название, действовавшее на время записи
+// @Accept      json
+// @Produce     json
+// @Param       id            path     uint64    true "ID Судна"
+// @Success     200           {object} []domain.VesselNameRecord
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /vessels/{id}/names [get]
+// @Security    BearerAuth
+func (h *Handler) VesselNames() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.VesselID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.VesselNames(ctx, id)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get vessel names", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// SearchVessels
+// @Tags        Vessel
+// @Summary     Поиск судов
+// @Description Полнотекстовый поиск `q` по началу слов названия, IMO, MMSI, позывного, флага и типа (все слова),
+// @Description фильтры по атрибутам. Не более 100 судов, наиболее релевантные первыми, кроме удаленных
+// @Accept      json
+// @Produce     json
+// @Param       query         query    domain.InputVesselSearch false "запрос и фильтры"
+// @Success     200           {object} []domain.Vessel
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /vessels/search [get]
+// @Security    BearerAuth
+func (h *Handler) SearchVessels() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputVesselSearch
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.SearchVessels(ctx, query)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error search vessels", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// ListVessels
+// @Tags        Vessel
+// @Summary     Список судов постранично
+// @Description Сортировка `sort`: `name` (по умолчанию), `createdAt`, `lastSeen` (время последнего трека), `desc` - по убыванию.
+// @Description Фильтры: `deleted` - только удаленные, `monitored` - на мониторинге или нет, `name` - часть названия
+// @Description (`match=prefix` - начало). Следующая страница - `cursor` из `nextCursor` с теми же сортировкой и фильтрами
+// @Accept      json
+// @Produce     json
+// @Param       query         query    domain.InputVesselList false "фильтры, сортировка и курсор"
+// @Success     200           {object} domain.VesselPage
+// @Failure     400           {string} string "ошибка валидации"
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /vessels/list [get]
+// @Security    BearerAuth
+func (h *Handler) ListVessels() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			query domain.InputVesselList
+		)
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.ListVessels(ctx, query)
+		var vErr validator.ValidationErrors
+		if errors.As(err, &vErr) {
+			c.Status(http.StatusBadRequest)
+			_, err = c.WriteString(err.Error())
+			return
+		}
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error list vessels", zap.Error(err), zap.Any("query", query))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
 // DeleteVessel
 // @Tags        Vessel
 // @Summary     Удаление судна
@@ -201,3 +408,101 @@ func (h *Handler) RestoreVessel() fiber.Handler {
 		return
 	}
 }
+
+// ExportVessel
+// @Tags        Admin
+// @Summary     Выгрузка данных судна
+// @Description архив всего, что хранится о судне (в т.ч. удаленном): строки судна и зависимых таблиц - треки, журнал контроля,
+// @Description состояние мониторинга, окна, оповещения, правила, списки, группы, токены, история названий,
+// @Description сообщения подписок с попытками доставки, записи журнала изменений
+// @Accept      json
+// @Produce     json
+// @Param       id            path     uint64    true "ID Судна"
+// @Success     200           {object} domain.VesselArchive
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /admin/vessels/{id}/export [get]
+// @Security    BearerAuth
+func (h *Handler) ExportVessel() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.VesselID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.ExportVessel(ctx, id)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error export vessel", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// PurgeVessel
+// @Tags        Admin
+// @Summary     Полное удаление судна
+// @Description удаляет судно и все зависимые данные (треки, журнал контроля, состояние мониторинга и т.д.) в одной транзакции.
+// @Description С export=true в ответе - архив данных, выгруженный перед удалением в той же транзакции.
+// @Description Правило оповещений только для этого судна удаляется. Сообщения подписок о судне удаляются,
+// @Description из записей журнала изменений удаляются строки судна
+// @Accept      json
+// @Produce     json
+// @Param       id            path     uint64    true "ID Судна"
+// @Param       InputPurge    query    domain.InputPurge false "выгрузить архив"
+// @Success     200           {object} domain.VesselArchive "архив, если export=true, иначе Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /admin/vessels/{id} [delete]
+// @Security    BearerAuth
+func (h *Handler) PurgeVessel() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id    domain.VesselID
+			query domain.InputPurge
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		if err = c.QueryParser(&query); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Vessel.PurgeVessel(ctx, id, query.Export)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error purge vessel", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		h.log.Info("Vessel purged", zap.Any("id", id), zap.Any("userID", GetUserID(c)))
+		if result != nil {
+			return c.Status(http.StatusOK).JSON(result)
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
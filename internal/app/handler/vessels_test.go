@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -871,3 +874,478 @@ func (suite *HandlerTestSuite) TestUpdateVessel() {
 		})
 	}
 }
+
+// testIMO valid IMO number with check digit from six digits of n
+func testIMO(n int64) string {
+	s := fmt.Sprintf("%06d", n%1000000)
+	sum := 0
+	for i, r := range s {
+		sum += int(r-'0') * (7 - i)
+	}
+	return s + strconv.Itoa(sum%10)
+}
+
+func (suite *HandlerTestSuite) TestSearchVessels() {
+	t := suite.T()
+	now := time.Now()
+	timeID := "t" + strconv.FormatInt(now.UnixNano(), 36)
+	imo := testIMO(now.UnixNano() / 1000)
+	mmsi := fmt.Sprintf("%09d", now.UnixNano()%1000000000)
+
+	ctx := context.Background()
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Searchable Trawler "+timeID), domain.VesselName("Other Vessel "+timeID))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(vessels))
+
+	attributes := domain.VesselAttributes{
+		IMO:      &imo,
+		MMSI:     &mmsi,
+		CallSign: &[]string{"UBCD7"}[0],
+		Flag:     &[]string{"NO"}[0],
+		Type:     &[]string{"Fishing trawler"}[0],
+		Length:   &[]float64{64.5}[0],
+		Beam:     &[]float64{13.2}[0],
+	}
+	updated, err := suite.srv.Vessel.UpdateVessels(ctx, nil, domain.Vessel{ID: vessels[0].ID, Name: vessels[0].Name, VesselAttributes: attributes})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(updated))
+	assert.Equal(t, imo, *updated[0].IMO)
+
+	badIMO := imo[:6] + strconv.Itoa((int(imo[6]-'0')+1)%10)
+	_, err = suite.srv.Vessel.UpdateVessels(ctx, nil, domain.Vessel{ID: vessels[1].ID, Name: vessels[1].Name,
+		VesselAttributes: domain.VesselAttributes{IMO: &badIMO}})
+	assert.Error(t, err, "wrong IMO check digit")
+
+	type want struct {
+		code            int
+		responseContain string
+		notContain      string
+	}
+	tests := []struct {
+		name  string
+		query url.Values
+		jwt   string
+		want  want
+	}{
+		{
+			name:  "Search vessels. Wrong role in jwt",
+			query: url.Values{"q": {"Searchable"}},
+			jwt:   suite.cfg.jwtVessel,
+			want:  want{code: http.StatusForbidden},
+		},
+		{
+			name:  "Search vessels. Full-text by name and type prefixes",
+			query: url.Values{"q": {"searchab trawl " + timeID}},
+			jwt:   suite.cfg.jwtOperator,
+			want:  want{code: http.StatusOK, responseContain: imo, notContain: "Other Vessel " + timeID},
+		},
+		{
+			name:  "Search vessels. Full-text by MMSI",
+			query: url.Values{"q": {mmsi}},
+			jwt:   suite.cfg.jwtOperator,
+			want:  want{code: http.StatusOK, responseContain: "Searchable Trawler " + timeID},
+		},
+		{
+			name:  "Search vessels. Filters",
+			query: url.Values{"imo": {imo}, "flag": {"no"}, "callSign": {"ubcd7"}, "lengthMin": {"60"}, "lengthMax": {"70"}},
+			jwt:   suite.cfg.jwtOperator,
+			want:  want{code: http.StatusOK, responseContain: `"beam":13.2`},
+		},
+		{
+			name:  "Search vessels. Filters out of range",
+			query: url.Values{"imo": {imo}, "lengthMin": {"65"}},
+			jwt:   suite.cfg.jwtOperator,
+			want:  want{code: http.StatusOK, notContain: imo},
+		},
+		{
+			name:  "Search vessels. Bad length",
+			query: url.Values{"lengthMin": {"long"}},
+			jwt:   suite.cfg.jwtOperator,
+			want:  want{code: http.StatusBadRequest},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			request, err := http.NewRequest(http.MethodGet,
+				constant.RouteAPI+constant.RouteVessels+constant.RouteSearch+"?"+test.query.Encode(), nil)
+			require.NoError(t, err)
+			request.Header.Set("Authorization", "Bearer "+test.jwt)
+
+			res, err := suite.app.Test(request)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			assert.Equal(t, test.want.code, res.StatusCode)
+
+			resBody, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			if test.want.responseContain != "" {
+				assert.Contains(t, string(resBody), test.want.responseContain)
+			}
+			if test.want.notContain != "" {
+				assert.NotContains(t, string(resBody), test.want.notContain)
+			}
+		})
+	}
+
+	request, err := http.NewRequest(http.MethodPut, constant.RouteAPI+constant.RouteVessels,
+		strings.NewReader(fmt.Sprintf(`[{"id":%d,"name":%q,"imo":%q}]`, vessels[1].ID, vessels[1].Name, badIMO)))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+	res, err := suite.app.Test(request)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "wrong IMO check digit")
+
+	request, err = http.NewRequest(http.MethodPut, constant.RouteAPI+constant.RouteVessels,
+		strings.NewReader(fmt.Sprintf(`[{"id":%d,"name":%q,"mmsi":%q}]`, vessels[1].ID, vessels[1].Name, mmsi)))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+	res, err = suite.app.Test(request)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusConflict, res.StatusCode, "MMSI of other vessel")
+}
+
+func (suite *HandlerTestSuite) TestListVessels() {
+	t := suite.T()
+	uniq := "l" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	names := []domain.VesselName{
+		domain.VesselName("Pager " + uniq + " A"),
+		domain.VesselName("Pager " + uniq + " B"),
+		domain.VesselName("Pager " + uniq + " C"),
+	}
+
+	ctx := context.Background()
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, names...)
+	require.NoError(t, err)
+	require.Equal(t, len(names), len(vessels))
+	require.NoError(t, suite.srv.Vessel.SetDeleteVessels(ctx, true, vessels[2].ID))
+
+	list := func(query url.Values) (code int, page domain.VesselPage) {
+		request, err := http.NewRequest(http.MethodGet,
+			constant.RouteAPI+constant.RouteVessels+constant.RouteList+"?"+query.Encode(), nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		if res.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&page))
+		}
+		return res.StatusCode, page
+	}
+	pageNames := func(page domain.VesselPage) (names []domain.VesselName) {
+		for _, v := range page.Vessels {
+			names = append(names, v.Name)
+		}
+		return
+	}
+
+	code, page := list(url.Values{"name": {uniq}, "limit": {"1"}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, names[:1], pageNames(page))
+	require.NotEmpty(t, page.NextCursor, "more vessels")
+	assert.NotNil(t, page.Vessels[0].CreatedAt)
+	assert.False(t, page.Vessels[0].IsDeleted)
+	assert.False(t, page.Vessels[0].Monitored)
+
+	code, page = list(url.Values{"name": {uniq}, "limit": {"1"}, "cursor": {page.NextCursor}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, names[1:2], pageNames(page), "deleted vessel is skipped")
+	assert.Empty(t, page.NextCursor, "last page")
+
+	code, page = list(url.Values{"name": {"pager " + uniq}, "match": {"prefix"}, "sort": {"createdAt"}, "desc": {"true"}, "deleted": {"true"}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, names[2:], pageNames(page))
+	assert.True(t, page.Vessels[0].IsDeleted)
+
+	code, page = list(url.Values{"name": {uniq}, "match": {"prefix"}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, page.Vessels, "name is not prefix")
+
+	code, page = list(url.Values{"name": {uniq}, "sort": {"lastSeen"}, "monitored": {"false"}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, len(page.Vessels))
+	code, page = list(url.Values{"name": {uniq}, "monitored": {"true"}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, page.Vessels)
+
+	code, page = list(url.Values{"name": {uniq}, "limit": {"1"}})
+	require.Equal(t, http.StatusOK, code)
+	code, _ = list(url.Values{"name": {uniq}, "sort": {"createdAt"}, "cursor": {page.NextCursor}})
+	assert.Equal(t, http.StatusBadRequest, code, "cursor of other sort")
+	code, _ = list(url.Values{"cursor": {"not a cursor"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = list(url.Values{"sort": {"imo"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = list(url.Values{"limit": {"10000"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func (suite *HandlerTestSuite) TestVesselNames() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+	oldName, newName := domain.VesselName("Old name "+uniq), domain.VesselName("New name "+uniq)
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, oldName)
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+
+	renamedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, suite.srv.TrackAt(ctx, vesselID, domain.InputPoint{12.12, 12.12}, renamedAt.Add(-time.Hour)))
+
+	send := func(t *testing.T, method, route string, body interface{}) (code int, resBody []byte) {
+		bodyJSON, _ := json.Marshal(body)
+		request, err := http.NewRequest(method, constant.RouteAPI+constant.RouteVessels+route, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	names := func(t *testing.T) (names []domain.VesselNameRecord) {
+		code, resBody := send(t, http.MethodGet, "/"+vesselID.String()+constant.RouteNames, nil)
+		require.Equal(t, http.StatusOK, code)
+		require.NoError(t, json.Unmarshal(resBody, &names))
+		return
+	}
+
+	t.Run("Vessel names. Not renamed", func(t *testing.T) {
+		assert.Equal(t, []domain.VesselNameRecord{{Name: oldName}}, names(t))
+		code, _ := send(t, http.MethodGet, "/100500000"+constant.RouteNames, nil)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("Vessel names. Rename in future", func(t *testing.T) {
+		code, _ := send(t, http.MethodPut, "?renamedAt="+url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)),
+			domain.Vessels{{ID: vesselID, Name: newName}})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Vessel names. Rename", func(t *testing.T) {
+		code, _ := send(t, http.MethodPut, "?renamedAt="+url.QueryEscape(renamedAt.Format(time.RFC3339)),
+			domain.Vessels{{ID: vesselID, Name: newName}})
+		require.Equal(t, http.StatusOK, code)
+
+		history := names(t)
+		require.Equal(t, 2, len(history))
+		assert.Equal(t, oldName, history[0].Name)
+		assert.Nil(t, history[0].ValidFrom)
+		assert.Equal(t, newName, history[1].Name)
+		require.NotNil(t, history[1].ValidFrom)
+		assert.True(t, renamedAt.Equal(*history[1].ValidFrom))
+	})
+
+	t.Run("Vessel names. Track by name at point time", func(t *testing.T) {
+		require.NoError(t, suite.srv.Track(ctx, vesselID, domain.InputPoint{12.12, 12.12}))
+		start := renamedAt.Add(-2 * time.Hour)
+		tracks, err := suite.srv.GetTrack(ctx, nil, domain.InputVesselsInterval{
+			InputVessels: domain.InputVessels{VesselIDs: domain.VesselIDs{vesselID}},
+			DateInterval: domain.DateInterval{Start: &start},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, len(tracks))
+		for _, track := range tracks {
+			if track.Timestamp.Before(renamedAt) {
+				assert.Equal(t, oldName, track.Name)
+			} else {
+				assert.Equal(t, newName, track.Name)
+			}
+		}
+	})
+}
+
+func (suite *HandlerTestSuite) TestPurgeVessel() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Purge vessel "+uniq))
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+	require.NoError(t, suite.srv.Track(ctx, vesselID, domain.InputPoint{12.12, 12.12}))
+	require.NoError(t, suite.srv.Track(ctx, vesselID, domain.InputPoint{10, 40}))
+	// audited control change
+	bodyJSON, _ := json.Marshal([]domain.VesselID{vesselID})
+	request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+constant.RouteMonitor, bytes.NewReader(bodyJSON))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+	res, err := suite.app.Test(request)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	send := func(t *testing.T, method, route, jwt string) (code int, resBody []byte) {
+		request, err := http.NewRequest(method, constant.RouteAPI+constant.RouteAdmin+constant.RouteVessels+route, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request, -1)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	route := "/" + vesselID.String()
+
+	t.Run("Purge vessel. Operator", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, route, suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = send(t, http.MethodGet, route+constant.RouteExport, suite.cfg.jwtOperator)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Purge vessel. Export", func(t *testing.T) {
+		code, resBody := send(t, http.MethodGet, route+constant.RouteExport, suite.cfg.jwtAdmin)
+		require.Equal(t, http.StatusOK, code)
+		var archive domain.VesselArchive
+		require.NoError(t, json.Unmarshal(resBody, &archive))
+		assert.Equal(t, vesselID, archive.VesselID)
+		var tracks []json.RawMessage
+		require.NoError(t, json.Unmarshal(archive.Tables[constant.DBTracks], &tracks))
+		assert.Equal(t, 2, len(tracks))
+		var records []json.RawMessage
+		require.NoError(t, json.Unmarshal(archive.Tables[constant.DBAuditLog], &records))
+		assert.NotEmpty(t, records)
+		assert.Contains(t, archive.Tables, constant.DBWebhookOutbox)
+	})
+
+	t.Run("Purge vessel. Purge with export", func(t *testing.T) {
+		code, resBody := send(t, http.MethodDelete, route+"?export=true", suite.cfg.jwtAdmin)
+		require.Equal(t, http.StatusOK, code)
+		var archive domain.VesselArchive
+		require.NoError(t, json.Unmarshal(resBody, &archive))
+		var rows []json.RawMessage
+		require.NoError(t, json.Unmarshal(archive.Tables[constant.DBVessels], &rows))
+		assert.Equal(t, 1, len(rows))
+
+		_, err := suite.srv.Vessel.GetVessels(ctx, vesselID)
+		assert.ErrorIs(t, err, myErr.ErrNotExist)
+		start := time.Now().Add(-time.Hour)
+		tracks, err := suite.srv.GetTrack(ctx, nil, domain.InputVesselsInterval{
+			InputVessels: domain.InputVessels{VesselIDs: domain.VesselIDs{vesselID}},
+			DateInterval: domain.DateInterval{Start: &start},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(tracks))
+
+		page, err := suite.srv.Audit.Audit(ctx, domain.InputAudit{Target: constant.DBWatchlistVessels, TargetID: vesselID.String()})
+		require.NoError(t, err)
+		assert.Empty(t, page.Records)
+		page, err = suite.srv.Audit.Audit(ctx, domain.InputAudit{Target: constant.DBVessels, TargetID: vesselID.String()})
+		require.NoError(t, err)
+		require.Len(t, page.Records, 1)
+		assert.JSONEq(t, `[{"id": `+vesselID.String()+`}]`, string(page.Records[0].Before))
+	})
+
+	t.Run("Purge vessel. Not exist", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, route, suite.cfg.jwtAdmin)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, http.MethodGet, route+constant.RouteExport, suite.cfg.jwtAdmin)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
+
+func (suite *HandlerTestSuite) TestImportVessels() {
+	t := suite.T()
+	ctx := context.Background()
+	uniq := strconv.FormatInt(time.Now().UnixNano(), 36)
+	newID := domain.VesselID(900000000000 + time.Now().UnixNano()%1000000000)
+	nameA, nameB, nameB2 := "Import A "+uniq, "Import B "+uniq, "Import B2 "+uniq
+
+	csvFile := "id,name,comment\n" +
+		"," + nameA + ",new by name\n" +
+		"," + nameA + ",same name\n" +
+		newID.String() + "," + nameB + ",new with id\n" +
+		"abc,Bad id " + uniq + ",\n" +
+		",,no name\n" +
+		strconv.FormatInt(int64(newID)+1, 10) + "," + nameA + ",name of other vessel\n" +
+		newID.String() + "," + nameB2 + ",rename\n"
+	wantStatuses := []domain.VesselImportStatus{domain.VesselImportCreated, domain.VesselImportExisting,
+		domain.VesselImportCreated, domain.VesselImportInvalid, domain.VesselImportInvalid,
+		domain.VesselImportConflict, domain.VesselImportUpdated}
+
+	send := func(t *testing.T, query, jwt, content string) (code int, resBody []byte) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile(constant.VesselImportFile, "vessels.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+constant.RouteVessels+constant.RouteImport+query, body)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request, -1)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	check := func(t *testing.T, resBody []byte, dryRun bool) {
+		var report domain.VesselImport
+		require.NoError(t, json.Unmarshal(resBody, &report))
+		assert.Equal(t, dryRun, report.DryRun)
+		require.Equal(t, len(wantStatuses), len(report.Rows))
+		for i, row := range report.Rows {
+			assert.Equal(t, wantStatuses[i], row.Status, "line %d: %s", row.Line, row.Error)
+			assert.Equal(t, i+2, row.Line)
+		}
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Existing)
+		assert.Equal(t, 2, report.Invalid)
+		assert.Equal(t, 1, report.Conflict)
+	}
+
+	t.Run("Import vessels. Wrong role", func(t *testing.T) {
+		code, _ := send(t, "", suite.cfg.jwtVessel, csvFile)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Import vessels. No name column", func(t *testing.T) {
+		code, _ := send(t, "", suite.cfg.jwtOperator, "id,title\n1,"+nameA+"\n")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Import vessels. Dry run", func(t *testing.T) {
+		code, resBody := send(t, "?dryRun=true", suite.cfg.jwtOperator, csvFile)
+		require.Equal(t, http.StatusOK, code)
+		check(t, resBody, true)
+
+		_, err := suite.srv.Vessel.GetVessels(ctx, newID)
+		assert.ErrorIs(t, err, myErr.ErrNotExist)
+	})
+
+	t.Run("Import vessels. Import", func(t *testing.T) {
+		code, resBody := send(t, "", suite.cfg.jwtOperator, csvFile)
+		require.Equal(t, http.StatusOK, code)
+		check(t, resBody, false)
+
+		vessels, err := suite.srv.Vessel.GetVessels(ctx, newID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.VesselName(nameB2), vessels[0].Name)
+		names, err := suite.srv.Vessel.VesselNames(ctx, newID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(names))
+
+		added, err := suite.srv.Vessel.AddVessel(ctx, domain.VesselName("Import C "+uniq))
+		require.NoError(t, err)
+		assert.Greater(t, int64(added[0].ID), int64(newID))
+	})
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// Watchlists
+// @Tags        Watchlist
+// @Summary     Списки наблюдения оператора
+// @Description личный и командные, в которых оператор владелец или участник
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.Watchlist
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /watchlists [get]
+// @Security    BearerAuth
+func (h *Handler) Watchlists() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Watchlist.Watchlists(ctx, GetUserID(c))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get watchlists", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddWatchlist
+// @Tags        Watchlist
+// @Summary     Добавление командного списка наблюдения
+// @Description владелец - оператор из токена, members - ID операторов-участников.
+// @Description Суда добавляются через POST /monitor?watchlist=ID
+// @Accept      json
+// @Produce     json
+// @Param       Watchlist  body      domain.Watchlist    true "список"
+// @Success     201        {integer} domain.WatchlistID
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /watchlists [post]
+// @Security    BearerAuth
+func (h *Handler) AddWatchlist() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			watchlist domain.Watchlist
+		)
+		err = c.BodyParser(&watchlist)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		watchlist.OwnerID = GetUserID(c)
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Watchlist.AddWatchlist(ctx, &watchlist)
+		if err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add watchlist", zap.Error(err), zap.Any("watchlist", watchlist))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateWatchlist
+// @Tags        Watchlist
+// @Summary     Изменение командного списка наблюдения
+// @Description название и участники, только владельцем
+// @Accept      json
+// @Produce     json
+// @Param       Watchlist  body     domain.Watchlist    true "список"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /watchlists [put]
+// @Security    BearerAuth
+func (h *Handler) UpdateWatchlist() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			watchlist domain.Watchlist
+		)
+		err = c.BodyParser(&watchlist)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+		watchlist.OwnerID = GetUserID(c)
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Watchlist.UpdateWatchlist(ctx, &watchlist)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error update watchlist", zap.Error(err), zap.Any("watchlist", watchlist))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// DeleteWatchlists
+// @Tags        Watchlist
+// @Summary     Удаление командных списков наблюдения
+// @Description только владельцем. Суда снимаются с мониторинга, если их нет в других списках
+// @Accept      json
+// @Produce     json
+// @Param       WatchlistIDs   body     []domain.WatchlistID    true "список ID списков"
+// @Success     200            {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /watchlists [delete]
+// @Security    BearerAuth
+func (h *Handler) DeleteWatchlists() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			WatchlistIDs []domain.WatchlistID
+		)
+		err = c.BodyParser(&WatchlistIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(WatchlistIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Watchlist.DeleteWatchlists(ctx, GetUserID(c), WatchlistIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error delete watchlists", zap.Error(err), zap.Any("ids", WatchlistIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
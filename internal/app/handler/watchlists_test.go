@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func (suite *HandlerTestSuite) TestWatchlists() {
+	t := suite.T()
+	ctx := context.Background()
+
+	vessels, err := suite.srv.Vessel.AddVessel(ctx, "Watchlist vessel")
+	require.NoError(t, err)
+	vesselID := vessels[0].ID
+
+	jwtSecond, err := domain.NewClaimOperator(&suite.cfg.JWT, 13, "Second Operator").Token()
+	require.NoError(t, err)
+	jwtThird, err := domain.NewClaimOperator(&suite.cfg.JWT, 14, "Third Operator").Token()
+	require.NoError(t, err)
+
+	send := func(t *testing.T, method, route, jwt string, body interface{}) (code int, resBody []byte) {
+		var reader io.Reader
+		if body != nil {
+			bodyJSON, _ := json.Marshal(body)
+			reader = bytes.NewReader(bodyJSON)
+		}
+		request, err := http.NewRequest(method, constant.RouteAPI+route, reader)
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+jwt)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		resBody, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, resBody
+	}
+	monitored := func(t *testing.T, jwt, query string) bool {
+		code, resBody := send(t, http.MethodGet, constant.RouteMonitor+query, jwt, nil)
+		require.Equal(t, http.StatusOK, code)
+		var list []domain.MonitoredVessel
+		require.NoError(t, json.Unmarshal(resBody, &list))
+		for _, v := range list {
+			if v.ID == vesselID {
+				return true
+			}
+		}
+		return false
+	}
+	controlState := func(t *testing.T) bool {
+		states, err := suite.srv.GetStates(ctx, vesselID)
+		require.NoError(t, err)
+		return states[0].State
+	}
+
+	t.Run("Watchlist. Personal lists", func(t *testing.T) {
+		code, _ := send(t, http.MethodPost, constant.RouteMonitor, suite.cfg.jwtOperator, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+		code, _ = send(t, http.MethodPost, constant.RouteMonitor, jwtSecond, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, monitored(t, suite.cfg.jwtOperator, ""))
+		assert.True(t, monitored(t, jwtSecond, ""))
+		assert.False(t, monitored(t, jwtThird, ""))
+	})
+
+	t.Run("Watchlist. Monitoring continues while referenced", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, constant.RouteMonitor, suite.cfg.jwtOperator, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, monitored(t, suite.cfg.jwtOperator, ""))
+		assert.True(t, monitored(t, jwtSecond, ""))
+		assert.True(t, controlState(t))
+
+		code, _ = send(t, http.MethodDelete, constant.RouteMonitor, jwtSecond, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, controlState(t))
+	})
+
+	var teamID domain.WatchlistID
+	t.Run("Watchlist. Team list", func(t *testing.T) {
+		code, resBody := send(t, http.MethodPost, constant.RouteWatchlists, suite.cfg.jwtOperator,
+			domain.Watchlist{Name: "Team", Members: domain.UserIDs{13}})
+		require.Equal(t, http.StatusCreated, code)
+		require.NoError(t, json.Unmarshal(resBody, &teamID))
+		query := "?watchlist=" + strconv.FormatInt(int64(teamID), 10)
+
+		code, _ = send(t, http.MethodPost, constant.RouteMonitor+query, jwtSecond, []domain.VesselID{vesselID})
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, monitored(t, suite.cfg.jwtOperator, query))
+		assert.True(t, controlState(t))
+
+		code, _ = send(t, http.MethodGet, constant.RouteMonitor+query, jwtThird, nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = send(t, http.MethodPost, constant.RouteMonitor+query, jwtThird, []domain.VesselID{vesselID})
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, resBody = send(t, http.MethodGet, constant.RouteWatchlists, jwtSecond, nil)
+		require.Equal(t, http.StatusOK, code)
+		var watchlists []domain.Watchlist
+		require.NoError(t, json.Unmarshal(resBody, &watchlists))
+		found := false
+		for _, w := range watchlists {
+			if w.ID == teamID {
+				found = true
+				assert.Equal(t, domain.UserID(12), w.OwnerID)
+				assert.Contains(t, w.VesselIDs, vesselID)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Watchlist. Delete by member is ignored", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, constant.RouteWatchlists, jwtSecond, []domain.WatchlistID{teamID})
+		require.Equal(t, http.StatusOK, code)
+		assert.True(t, controlState(t))
+	})
+
+	t.Run("Watchlist. Delete releases vessels", func(t *testing.T) {
+		code, _ := send(t, http.MethodDelete, constant.RouteWatchlists, suite.cfg.jwtOperator, []domain.WatchlistID{teamID})
+		require.Equal(t, http.StatusOK, code)
+		assert.False(t, controlState(t))
+	})
+}
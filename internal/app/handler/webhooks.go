@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+// Webhooks
+// @Tags        Webhook
+// @Summary     Подписки на события
+// @Description
+// @Accept      json
+// @Produce     json
+// @Success     200           {object} []domain.Webhook
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /webhooks [get]
+// @Security    BearerAuth
+func (h *Handler) Webhooks() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Webhook.Webhooks(ctx)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get webhooks", zap.Error(err))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
+
+// AddWebhook
+// @Tags        Webhook
+// @Summary     Добавление подписки на события
+// @Description События events: controlSet, controlUnset - постановка, снятие с мониторинга, zoneEnter, zoneExit - вход, выход из карты,
+// @Description vesselLost - потеря связи с судном. Пустой events - все события.
+// @Description На url отправляется POST с телом события, заголовок X-Webhook-Signature: sha256=HMAC-SHA256(secret, X-Webhook-Timestamp + "." + тело).
+// @Description Если secret не задан, он генерируется. Secret возвращается только при создании
+// @Accept      json
+// @Produce     json
+// @Param       Webhook    body      domain.Webhook    true "подписка"
+// @Success     201        {object}  domain.Webhook
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /webhooks [post]
+// @Security    BearerAuth
+func (h *Handler) AddWebhook() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			webhook domain.Webhook
+		)
+		err = c.BodyParser(&webhook)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		if err = h.s.Webhook.AddWebhook(ctx, &webhook); err != nil {
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error add webhook", zap.Error(err), zap.String("url", webhook.URL))
+			return nil
+		}
+		return c.Status(http.StatusCreated).JSON(webhook)
+	}
+}
+
+// UpdateWebhook
+// @Tags        Webhook
+// @Summary     Изменение подписки на события
+// @Description Пустой secret - не изменяется
+// @Accept      json
+// @Produce     json
+// @Param       Webhook    body     domain.Webhook    true "подписка"
+// @Success     200        {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /webhooks [put]
+// @Security    BearerAuth
+func (h *Handler) UpdateWebhook() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			webhook domain.Webhook
+		)
+		err = c.BodyParser(&webhook)
+		if err != nil && !errors.Is(err, io.EOF) {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Webhook.UpdateWebhook(ctx, &webhook)
+		if err != nil {
+			if errors.Is(err, myErr.ErrNotExist) {
+				c.Status(http.StatusNotFound)
+				return nil
+			}
+			if errors.As(err, &validator.ValidationErrors{}) {
+				_, err = c.Status(http.StatusBadRequest).WriteString(err.Error())
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error update webhook", zap.Error(err), zap.Any("id", webhook.ID))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// DeleteWebhooks
+// @Tags        Webhook
+// @Summary     Удаление подписок на события
+// @Description недоставленные события удаляемых подписок не отправляются
+// @Accept      json
+// @Produce     json
+// @Param       WebhookIDs   body     []domain.WebhookID    true "список ID подписок"
+// @Success     200          {string} string "Ok"
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /webhooks [delete]
+// @Security    BearerAuth
+func (h *Handler) DeleteWebhooks() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			WebhookIDs []domain.WebhookID
+		)
+		err = c.BodyParser(&WebhookIDs)
+		if err != nil && !errors.Is(err, io.EOF) || len(WebhookIDs) == 0 {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		err = h.s.Webhook.DeleteWebhooks(ctx, WebhookIDs...)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error delete webhooks", zap.Error(err), zap.Any("ids", WebhookIDs))
+			return nil
+		}
+		_, err = c.Status(http.StatusOK).WriteString("Ok")
+		return
+	}
+}
+
+// WebhookDeliveries
+// @Tags        Webhook
+// @Summary     Попытки доставки событий подписки
+// @Description последние, код ответа, ошибка, длительность
+// @Accept      json
+// @Produce     json
+// @Param       id   path      int    true "ID подписки"
+// @Success     200  {object}  []domain.WebhookDelivery
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /webhooks/{id}/deliveries [get]
+// @Security    BearerAuth
+func (h *Handler) WebhookDeliveries() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		var (
+			id domain.WebhookID
+		)
+		if err = id.SetFromStr(c.Params("id")); err != nil {
+			c.Status(http.StatusBadRequest)
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(c.Context(), constant.ServerOperationTimeout)
+		defer cancel()
+
+		result, err := h.s.Webhook.WebhookDeliveries(ctx, id)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			h.log.Error("Error get webhook deliveries", zap.Error(err), zap.Any("id", id))
+			return nil
+		}
+		return c.Status(http.StatusOK).JSON(result)
+	}
+}
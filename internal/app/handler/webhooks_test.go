@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func (suite *HandlerTestSuite) TestWebhooks() {
+	t := suite.T()
+	ctx := context.Background()
+
+	type received struct {
+		headers http.Header
+		body    []byte
+	}
+	var (
+		mu       sync.Mutex
+		requests []received
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{headers: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	addWebhook := func(t *testing.T, body interface{}, headers map[string]string) (code int, webhook domain.Webhook) {
+		bodyJSON, _ := json.Marshal(body)
+		request, err := http.NewRequest(http.MethodPost, constant.RouteAPI+constant.RouteWebhooks, bytes.NewReader(bodyJSON))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		if res.StatusCode == http.StatusCreated {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&webhook))
+		}
+		return res.StatusCode, webhook
+	}
+	operator := map[string]string{"Authorization": "Bearer " + suite.cfg.jwtOperator}
+
+	t.Run("Add webhook. No jwt", func(t *testing.T) {
+		code, _ := addWebhook(t, domain.Webhook{URL: receiver.URL}, nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("Add webhook. Wrong role in jwt", func(t *testing.T) {
+		code, _ := addWebhook(t, domain.Webhook{URL: receiver.URL}, map[string]string{"Authorization": "Bearer " + suite.cfg.jwtVessel})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Add webhook. Validate. Unknown event", func(t *testing.T) {
+		code, _ := addWebhook(t, domain.Webhook{URL: receiver.URL, Events: domain.WebhookEvents{"pass"}}, operator)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Add webhook. Validate. Bad url", func(t *testing.T) {
+		code, _ := addWebhook(t, domain.Webhook{URL: "ftp://example.com"}, operator)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	var webhook domain.Webhook
+	t.Run("Add webhook. OK", func(t *testing.T) {
+		var code int
+		code, webhook = addWebhook(t, domain.Webhook{
+			URL:    receiver.URL,
+			Events: domain.WebhookEvents{domain.WebhookEventZoneEnter},
+		}, operator)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Greater(t, int64(webhook.ID), int64(0))
+		assert.NotEmpty(t, webhook.Secret)
+	})
+	require.NotZero(t, webhook.ID)
+
+	require.NoError(t, suite.srv.SetControl(ctx, suite.operatorAction(ctx), true, suite.cfg.VesselID))
+	// outside, then inside of test zone
+	require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{12.12, 12.12}))
+	require.NoError(t, suite.srv.Track(ctx, suite.cfg.VesselID, domain.InputPoint{10, 40}))
+
+	t.Run("Webhook. Zone enter delivered", func(t *testing.T) {
+		delivered, err := suite.srv.DeliverPending(ctx)
+		require.NoError(t, err)
+		require.Greater(t, delivered, 0)
+
+		mu.Lock()
+		defer mu.Unlock()
+		found := false
+		for _, r := range requests {
+			var payload domain.WebhookPayload
+			require.NoError(t, json.Unmarshal(r.body, &payload))
+			assert.Equal(t, domain.WebhookEventZoneEnter, payload.Event)
+			assert.Equal(t, string(payload.Event), r.headers.Get(constant.WebhookHeaderEvent))
+			assert.Equal(t, "sha256="+domain.WebhookSignature(webhook.Secret, r.headers.Get(constant.WebhookHeaderTimestamp), r.body),
+				r.headers.Get(constant.WebhookHeaderSignature))
+			if payload.Vessel.ID == suite.cfg.VesselID && payload.Zone != nil && *payload.Zone == suite.cfg.ZoneName {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Webhook. Deliveries", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet,
+			constant.RouteAPI+constant.RouteWebhooks+"/"+strconv.FormatInt(int64(webhook.ID), 10)+constant.RouteDeliveries, nil)
+		require.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer "+suite.cfg.jwtOperator)
+		res, err := suite.app.Test(request)
+		require.NoError(t, err)
+		defer func(Body io.ReadCloser) {
+			err := Body.Close()
+			require.NoError(t, err)
+		}(res.Body)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var deliveries []domain.WebhookDelivery
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&deliveries))
+		require.NotEmpty(t, deliveries)
+		for _, d := range deliveries {
+			assert.Nil(t, d.Error)
+			require.NotNil(t, d.StatusCode)
+			assert.Equal(t, http.StatusNoContent, *d.StatusCode)
+		}
+	})
+
+	require.NoError(t, suite.srv.DeleteWebhooks(ctx, webhook.ID))
+}
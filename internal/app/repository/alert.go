@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+type AlertRepo struct {
+	db *sqlx.DB
+}
+
+func NewAlertRepository(db *sqlx.DB) *AlertRepo {
+	return &AlertRepo{db: db}
+}
+
+func (r *AlertRepo) AlertRules(ctx context.Context) (rules []domain.AlertRule, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("id", "name", "vessel_ids", "zone_names", "event", "dwell_minutes", "created_at").
+		From(constant.DBAlertRules).
+		Where("is_deleted is not true").
+		OrderBy("id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &rules, sqlStr, args...)
+	if rules == nil {
+		rules = make([]domain.AlertRule, 0)
+	}
+	return
+}
+
+// VesselAlertRules rules applied to vessel: listed it or for any vessel
+func (r *AlertRepo) VesselAlertRules(ctx context.Context, vesselID domain.VesselID) (rules []domain.AlertRule, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("id", "name", "vessel_ids", "zone_names", "event", "dwell_minutes", "created_at").
+		From(constant.DBAlertRules).
+		Where("is_deleted is not true and (cardinality(vessel_ids) = 0 or $1 = any(vessel_ids))", vesselID).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &rules, sqlStr, args...)
+	return
+}
+
+func (r *AlertRepo) AddAlertRule(ctx context.Context, rule *domain.AlertRule) (id domain.AlertRuleID, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if rule.VesselIDs == nil {
+		rule.VesselIDs = domain.VesselIDs{}
+	}
+	if rule.ZoneNames == nil {
+		rule.ZoneNames = domain.ZoneNames{}
+	}
+	if sqlStr, args, err = sq.Insert(constant.DBAlertRules).
+		Columns("name", "vessel_ids", "zone_names", "event", "dwell_minutes", "created_at").
+		Values(rule.Name, rule.VesselIDs, rule.ZoneNames, rule.Event, rule.DwellMinutes, time.Now()).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAlertRules, "id", pq.Array([]domain.AlertRuleID{id}), nil)
+	})
+	return
+}
+
+func (r *AlertRepo) UpdateAlertRule(ctx context.Context, rule *domain.AlertRule) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if rule.VesselIDs == nil {
+		rule.VesselIDs = domain.VesselIDs{}
+	}
+	if rule.ZoneNames == nil {
+		rule.ZoneNames = domain.ZoneNames{}
+	}
+	if sqlStr, args, err = sq.Update(constant.DBAlertRules).
+		SetMap(map[string]interface{}{
+			"name":          rule.Name,
+			"vessel_ids":    rule.VesselIDs,
+			"zone_names":    rule.ZoneNames,
+			"event":         rule.Event,
+			"dwell_minutes": rule.DwellMinutes,
+		}).
+		Where(sqrl.Eq{"id": rule.ID}).
+		Where("is_deleted is not true").
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+
+	ids := pq.Array([]domain.AlertRuleID{rule.ID})
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBAlertRules, "id", ids); err != nil {
+			return
+		}
+		var updatedID domain.AlertRuleID
+		if err = tx.GetContext(ctx, &updatedID, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAlertRules, "id", ids, before)
+	})
+}
+
+func (r *AlertRepo) DeleteAlertRules(ctx context.Context, ruleIDs ...domain.AlertRuleID) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBAlertRules).
+		Set("is_deleted", true).
+		Where(sqrl.Eq{"id": ruleIDs}).
+		ToSql(); err != nil {
+		return
+	}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBAlertRules, "id", pq.Array(ruleIDs)); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAlertRules, "id", pq.Array(ruleIDs), before)
+	})
+}
+
+// AddAlerts already raised alerts (same rule, vessel, zone, event and zone entry time) are skipped
+func (r *AlertRepo) AddAlerts(ctx context.Context, alerts ...domain.Alert) (err error) {
+	if len(alerts) == 0 {
+		return
+	}
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var stmt *sqlx.Stmt
+	if stmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBAlerts+
+		" (rule_id, vessel_id, zone_name, event, zone_time_in, timestamp) VALUES($1, $2, $3, $4, $5, $6) "+
+		" on conflict do nothing"); err != nil {
+		return
+	}
+	for _, alert := range alerts {
+		if _, err = stmt.ExecContext(ctx,
+			alert.RuleID, alert.Vessel.ID, alert.ZoneName, alert.Event, alert.ZoneTimeIn, alert.Timestamp); err != nil {
+			return
+		}
+	}
+	err = tx.Commit()
+	return
+}
+
+// Alerts of vessels and zones in scope
+func (r *AlertRepo) Alerts(ctx context.Context, scope *domain.Scope, q domain.InputAlerts) (alerts []domain.Alert, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select("a.id", "a.rule_id", "ar.name as rule_name",
+		"a.vessel_id", "coalesce("+vesselNameAt("a.vessel_id", "a.timestamp")+", '') as vessel_name",
+		"a.zone_name", "a.event", "a.zone_time_in", "a.timestamp", "a.created_at",
+		"a.acknowledged_at", "a.acknowledged_by").
+		From(constant.DBAlerts+" a").
+		InnerJoin(constant.DBAlertRules+" ar on ar.id = a.rule_id").
+		LeftJoin(constant.DBVessels+" v on v.id = a.vessel_id").
+		Where(zoneInScope("a.zone_name", scope)).
+		OrderBy("a.timestamp desc", "a.id desc")
+	if q.Start != nil {
+		sqBuild = sqBuild.Where("a.timestamp >= ?", *q.Start)
+	}
+	if q.Finish != nil {
+		sqBuild = sqBuild.Where("a.timestamp <= ?", *q.Finish)
+	}
+	if len(q.VesselIDs) > 0 {
+		sqBuild = sqBuild.Where("a.vessel_id = any(?)", q.VesselIDs)
+	}
+	if scope != nil && scope.VesselIDs != nil {
+		sqBuild = sqBuild.Where("a.vessel_id = any(?)", scope.VesselIDs)
+	}
+	if q.Acknowledged != nil {
+		if *q.Acknowledged {
+			sqBuild = sqBuild.Where("a.acknowledged_at is not null")
+		} else {
+			sqBuild = sqBuild.Where("a.acknowledged_at is null")
+		}
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &alerts, sqlStr, args...)
+	if alerts == nil {
+		alerts = make([]domain.Alert, 0)
+	}
+	return
+}
+
+func (r *AlertRepo) AcknowledgeAlerts(ctx context.Context, userID domain.UserID, alertIDs ...domain.AlertID) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBAlerts).
+		Set("acknowledged_at", time.Now()).
+		Set("acknowledged_by", userID).
+		Where(sqrl.Eq{"id": alertIDs}).
+		Where("acknowledged_at is null").
+		ToSql(); err != nil {
+		return
+	}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBAlerts, "id", pq.Array(alertIDs)); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAlerts, "id", pq.Array(alertIDs), before)
+	})
+}
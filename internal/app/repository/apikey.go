@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+var apiKeyColumns = []string{"k.id", "k.name", "k.prefix", "k.role", "k.user_id", "k.zone_names", "k.vessel_ids", "k.group_ids",
+	"k.key_hash", "k.previous_hash", "k.previous_expires_at", "k.created_by", "k.created_at", "k.expires_at",
+	"k.rotated_at", "k.last_used_at", "k.revoked_at"}
+
+type APIKeyRepo struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+// APIKeys not revoked
+func (r *APIKeyRepo) APIKeys(ctx context.Context) (keys []domain.APIKey, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select(apiKeyColumns...).
+		From(constant.DBAPIKeys + " k").
+		Where("k.revoked_at is null").
+		OrderBy("k.created_at desc").
+		ToSql(); err != nil {
+		return
+	}
+	err = r.db.SelectContext(ctx, &keys, sqlStr, args...)
+	if keys == nil {
+		keys = make([]domain.APIKey, 0)
+	}
+	return
+}
+
+func (r *APIKeyRepo) AddAPIKey(ctx context.Context, key *domain.APIKey) (id domain.APIKeyID, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBAPIKeys).
+		Columns("name", "prefix", "role", "user_id", "zone_names", "vessel_ids", "group_ids", "key_hash", "created_by", "created_at", "expires_at").
+		Values(key.Name, key.Prefix, key.Role, key.UserID, key.ZoneNames, key.VesselIDs, key.GroupIDs, key.Hash, key.CreatedBy, key.CreatedAt, key.ExpiresAt).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAPIKeys, "id", pq.Int64Array{int64(id)}, nil)
+	})
+	return
+}
+
+// RotateAPIKey replaces key hash, previous key is accepted until graceUntil. sql.ErrNoRows if key is revoked or does not exist
+func (r *APIKeyRepo) RotateAPIKey(ctx context.Context, id domain.APIKeyID, prefix, hash string, graceUntil time.Time) (key *domain.APIKey, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBAPIKeys+" k").
+		Set("previous_hash", sqrl.Expr("k.key_hash")).
+		Set("previous_expires_at", graceUntil).
+		Set("key_hash", hash).
+		Set("prefix", prefix).
+		Set("rotated_at", time.Now()).
+		Where(sqrl.Eq{"k.id": id}).
+		Where("k.revoked_at is null").
+		Suffix("returning " + strings.Join(apiKeyColumns, ", ")).
+		ToSql(); err != nil {
+		return
+	}
+	key = new(domain.APIKey)
+	ids := pq.Int64Array{int64(id)}
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBAPIKeys, "id", ids); err != nil {
+			return
+		}
+		if err = tx.GetContext(ctx, key, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAPIKeys, "id", ids, before)
+	})
+	return
+}
+
+// RevokeAPIKeys not revoked yet, returns revoked
+func (r *APIKeyRepo) RevokeAPIKeys(ctx context.Context, ids ...domain.APIKeyID) (revoked []domain.APIKeyID, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBAPIKeys).
+		Set("revoked_at", time.Now()).
+		Where("id = any(?)", pq.Array(ids)).
+		Where("revoked_at is null").
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBAPIKeys, "id", pq.Array(ids)); err != nil {
+			return
+		}
+		if err = tx.SelectContext(ctx, &revoked, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBAPIKeys, "id", pq.Array(ids), before)
+	})
+	return
+}
+
+// APIKeyByHash active key by current or, in rotation grace period, previous hash, user of key is not deleted,
+// role of key within roles of user.
+// sql.ErrNoRows if there is no such key
+func (r *APIKeyRepo) APIKeyByHash(ctx context.Context, hash string, now time.Time) (key *domain.APIKey, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	// role of key is limited by current roles of user
+	columns := make([]string, 0, len(apiKeyColumns))
+	for _, column := range apiKeyColumns {
+		if column == "k.role" {
+			column = "k.role & u.role as role"
+		}
+		columns = append(columns, column)
+	}
+	if sqlStr, args, err = sq.Select(columns...).
+		From(constant.DBAPIKeys+" k").
+		InnerJoin(constant.DBUsers+" u on u.id = k.user_id and u.is_deleted is not true").
+		Where("k.revoked_at is null and (k.expires_at is null or k.expires_at > ?)", now).
+		Where(sqrl.Or{
+			sqrl.Eq{"k.key_hash": hash},
+			sqrl.And{sqrl.Eq{"k.previous_hash": hash}, sqrl.Gt{"k.previous_expires_at": now}},
+		}).
+		ToSql(); err != nil {
+		return
+	}
+	key = new(domain.APIKey)
+	err = r.db.GetContext(ctx, key, sqlStr, args...)
+	return
+}
+
+// TouchAPIKey sets last use time
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, id domain.APIKeyID, at time.Time) (err error) {
+	_, err = r.db.ExecContext(ctx, "UPDATE"+" "+constant.DBAPIKeys+" set last_used_at = $1 where id = $2", at, id)
+	return
+}
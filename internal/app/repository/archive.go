@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const (
+	// webhookOfVessel condition on outbox message about vessel, $1 - vessel id
+	webhookOfVessel = "payload->'vessel'->>'id' = $1::bigint::text"
+	// auditRowOfVessel condition on row r of audit record a: row of vessel or with vessel_id of vessel, $1 - vessel id
+	auditRowOfVessel = "(r->>'vessel_id' = $1::bigint::text or a.target = '" + constant.DBVessels + "' and r->>'id' = $1::bigint::text)"
+	// auditOfVessel condition on audit record a with rows of vessel
+	auditOfVessel = "exists(select 1 from jsonb_array_elements(coalesce(a.before, '[]') || coalesce(a.after, '[]')) r where " + auditRowOfVessel + ")"
+)
+
+// vesselTables rows of vessel in tables, $1 - vessel id
+var vesselTables = map[string]string{
+	constant.DBVessels:           "select * from " + constant.DBVessels + " where id = $1",
+	constant.DBVesselNames:       "select * from " + constant.DBVesselNames + " where vessel_id = $1 order by valid_from",
+	constant.DBTracks:            "select id, vessel_id, time, ST_AsGeoJSON(location)::json as location from " + constant.DBTracks + " where vessel_id = $1 order by time",
+	constant.DBControlLog:        "select * from " + constant.DBControlLog + " where vessel_id = $1 order by timestamp",
+	constant.DBControlDashboard:  "select vessel_id, state, timestamp, control_start, control_end, ST_AsGeoJSON(location)::json as location, current_zone, contact from " + constant.DBControlDashboard + " where vessel_id = $1",
+	constant.DBControlSchedule:   "select * from " + constant.DBControlSchedule + " where vessel_id = $1 order by id",
+	constant.DBAlerts:            "select * from " + constant.DBAlerts + " where vessel_id = $1 order by timestamp",
+	constant.DBAlertRules:        "select * from " + constant.DBAlertRules + " where $1 = any(vessel_ids) order by id",
+	constant.DBWatchlistVessels:  "select * from " + constant.DBWatchlistVessels + " where vessel_id = $1",
+	constant.DBGroupVessels:      "select * from " + constant.DBGroupVessels + " where vessel_id = $1",
+	constant.DBVesselCredentials: "select * from " + constant.DBVesselCredentials + " where vessel_id = $1 order by issued_at",
+	constant.DBWebhookOutbox:     "select id, webhook_id, event, payload, created_at, attempts, delivered_at, failed_at from " + constant.DBWebhookOutbox + " where " + webhookOfVessel + " order by id",
+	constant.DBWebhookDelivery: "select * from " + constant.DBWebhookDelivery + " where outbox_id in (select id from " + constant.DBWebhookOutbox +
+		" where " + webhookOfVessel + ") order by id",
+	constant.DBAuditLog: "select * from " + constant.DBAuditLog + " a where " + auditOfVessel + " order by id",
+}
+
+// purgeVessel statements removing vessel and dependent data, $1 - vessel id.
+// Alert rule only for the vessel is deleted, otherwise it would apply to any vessel.
+// Rows of vessel are removed from audit records, records stay: who changed what and when
+var purgeVessel = []string{
+	"DELETE FROM" + " " + constant.DBWebhookDelivery + " where outbox_id in (select id from " + constant.DBWebhookOutbox + " where " + webhookOfVessel + ")",
+	"DELETE FROM" + " " + constant.DBWebhookOutbox + " where " + webhookOfVessel,
+	"UPDATE" + " " + constant.DBAuditLog + " a set " +
+		" before = case when a.before is null then null else coalesce((select jsonb_agg(r) from jsonb_array_elements(a.before) r where not " + auditRowOfVessel + "), '[]') end, " +
+		" after = case when a.after is null then null else coalesce((select jsonb_agg(r) from jsonb_array_elements(a.after) r where not " + auditRowOfVessel + "), '[]') end, " +
+		" target_ids = case when a.target in ('" + constant.DBVessels + "', '" + constant.DBWatchlistVessels + "', '" + constant.DBControlDashboard + "') " +
+		"  then array_remove(a.target_ids, $1::bigint::text) else a.target_ids end " +
+		" where " + auditOfVessel,
+	"DELETE FROM" + " " + constant.DBTracks + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBControlLog + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBControlDashboard + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBControlSchedule + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBAlerts + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBWatchlistVessels + " where vessel_id = $1",
+	"DELETE FROM" + " " + constant.DBVesselCredentials + " where vessel_id = $1",
+	"UPDATE" + " " + constant.DBAlertRules + " set is_deleted = true where vessel_ids = array[$1::bigint]",
+	"UPDATE" + " " + constant.DBAlertRules + " set vessel_ids = array_remove(vessel_ids, $1::bigint) where $1 = any(vessel_ids)",
+	"DELETE FROM" + " " + constant.DBVessels + " where id = $1",
+}
+
+// ExportVessel archive of vessel data, sql.ErrNoRows if vessel does not exist
+func (r *VesselRepo) ExportVessel(ctx context.Context, vesselID domain.VesselID) (archive *domain.VesselArchive, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	if archive, err = exportVessel(ctx, tx, vesselID); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// PurgeVessel removes vessel and dependent data in one transaction, archive is exported before purge if export is set.
+// sql.ErrNoRows if vessel does not exist
+func (r *VesselRepo) PurgeVessel(ctx context.Context, vesselID domain.VesselID, export bool) (archive *domain.VesselArchive, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var exists bool
+	if err = tx.GetContext(ctx, &exists, "select true from "+constant.DBVessels+" where id = $1 for update", vesselID); err != nil {
+		return
+	}
+	ids := domain.VesselIDs{vesselID}
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBVessels, "id", ids); err != nil {
+		return
+	}
+	if before != nil {
+		// purged vessel is recorded only by id
+		before = domain.AuditRows(`[{"id": ` + vesselID.String() + `}]`)
+	}
+	if export {
+		if archive, err = exportVessel(ctx, tx, vesselID); err != nil {
+			return
+		}
+	}
+	for _, stmt := range purgeVessel {
+		if _, err = tx.ExecContext(ctx, stmt, vesselID); err != nil {
+			return
+		}
+	}
+	if err = writeAudit(ctx, tx, constant.DBVessels, "id", ids, before); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func exportVessel(ctx context.Context, tx *sqlx.Tx, vesselID domain.VesselID) (archive *domain.VesselArchive, err error) {
+	archive = &domain.VesselArchive{
+		VesselID:   vesselID,
+		ExportedAt: time.Now(),
+		Tables:     make(map[string]json.RawMessage, len(vesselTables)),
+	}
+	for table, query := range vesselTables {
+		var rows []byte
+		if err = tx.GetContext(ctx, &rows, "select coalesce(json_agg(t), '[]') from ("+query+") t", vesselID); err != nil {
+			return nil, err
+		}
+		archive.Tables[table] = rows
+	}
+	if string(archive.Tables[constant.DBVessels]) == "[]" {
+		return nil, sql.ErrNoRows
+	}
+	return
+}
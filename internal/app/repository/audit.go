@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// auditHiddenColumns secrets are not copied to audit
+var auditHiddenColumns = pq.StringArray{"hash", "key_hash", "previous_hash", "secret"}
+
+// auditRowSQL jsonb of row t of table with its child rows, to_jsonb(t) for others
+var auditRowSQL = map[string]string{
+	constant.DBWatchlists: "to_jsonb(t) || jsonb_build_object(" +
+		" 'member_ids', array(select m.user_id from " + constant.DBWatchlistMembers + " m where m.watchlist_id = t.id order by m.user_id), " +
+		" 'vessel_ids', array(select w.vessel_id from " + constant.DBWatchlistVessels + " w where w.watchlist_id = t.id order by w.vessel_id))",
+	constant.DBVesselGroups: "to_jsonb(t) || jsonb_build_object(" +
+		" 'vessel_ids', array(select g.vessel_id from " + constant.DBGroupVessels + " g where g.group_id = t.id order by g.vessel_id))",
+}
+
+var auditColumns = []string{"a.id", "a.timestamp", "a.user_id", "a.api_key_id", "a.method", "a.path",
+	"a.target", "a.target_ids", "a.before", "a.after"}
+
+type AuditRepo struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// auditRows of table with key in ids for audit of request in ctx, nil out of request.
+// Key may be not unique (vessel_id of list entries), rows of the same key are ordered by content
+func auditRows(ctx context.Context, q sqlx.QueryerContext, table, key string, ids interface{}) (rows domain.AuditRows, err error) {
+	if domain.AuditRequestFrom(ctx) == nil {
+		return
+	}
+	row, ok := auditRowSQL[table]
+	if !ok {
+		row = "to_jsonb(t)"
+	}
+	err = sqlx.GetContext(ctx, q, &rows, "select coalesce(jsonb_agg(("+row+") - $2::text[] order by t."+key+", ("+row+")::text), '[]') "+
+		" from "+table+" t where t."+key+" = any($1)", ids, auditHiddenColumns)
+	return
+}
+
+// filterAuditRows of rows with key in ids
+func filterAuditRows(ctx context.Context, q sqlx.QueryerContext, rows domain.AuditRows, key string, ids interface{}) (filtered domain.AuditRows, err error) {
+	if rows == nil {
+		return
+	}
+	err = sqlx.GetContext(ctx, q, &filtered, "select coalesce(jsonb_agg(r), '[]') from jsonb_array_elements($1::jsonb) r "+
+		" where r->>$2::text = any($3::text[])", rows, key, ids)
+	return
+}
+
+// writeAudit records change of rows of table with key in ids by request in ctx: rows before and their current state.
+// Nothing is recorded out of request or if rows are not changed
+func writeAudit(ctx context.Context, q sqlx.ExtContext, table, key string, ids interface{}, before domain.AuditRows) (err error) {
+	req := domain.AuditRequestFrom(ctx)
+	if req == nil {
+		return
+	}
+	var after domain.AuditRows
+	if after, err = auditRows(ctx, q, table, key, ids); err != nil {
+		return
+	}
+	if bytes.Equal(before, after) || before == nil && string(after) == "[]" {
+		return
+	}
+	if _, err = q.ExecContext(ctx, "INSERT INTO"+" "+constant.DBAuditLog+
+		" (user_id, api_key_id, method, path, target, target_ids, before, after) "+
+		" VALUES ($1, $2, $3, $4, $5, "+
+		" array(select distinct r->>$6::text from jsonb_array_elements(coalesce($7::jsonb, '[]') || coalesce($8::jsonb, '[]')) r order by 1), "+
+		" $7, $8)",
+		req.UserID, req.APIKeyID, req.Method, req.Path, table, key, before, after); err != nil {
+		return
+	}
+	req.Recorded = true
+	return
+}
+
+// AddAudit record of request without target, for call which changed no rows
+func (r *AuditRepo) AddAudit(ctx context.Context, req *domain.AuditRequest) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBAuditLog).
+		Columns("user_id", "api_key_id", "method", "path").
+		Values(req.UserID, req.APIKeyID, req.Method, req.Path).
+		ToSql(); err != nil {
+		return
+	}
+	_, err = r.db.ExecContext(ctx, sqlStr, args...)
+	return
+}
+
+// Audit records the newest first, after cursor
+func (r *AuditRepo) Audit(ctx context.Context, q domain.InputAudit, cursor *domain.AuditCursor, limit uint64) (records []domain.Audit, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select(auditColumns...).
+		From(constant.DBAuditLog + " a").
+		OrderBy("a.id desc").
+		Limit(limit)
+	if cursor != nil {
+		sqBuild = sqBuild.Where("a.id < ?", cursor.ID)
+	}
+	if q.Start != nil {
+		sqBuild = sqBuild.Where("a.timestamp >= ?", *q.Start)
+	}
+	if q.Finish != nil {
+		sqBuild = sqBuild.Where("a.timestamp <= ?", *q.Finish)
+	}
+	if len(q.UserIDs) > 0 {
+		sqBuild = sqBuild.Where("a.user_id = any(?)", q.UserIDs)
+	}
+	if q.APIKeyID > 0 {
+		sqBuild = sqBuild.Where("a.api_key_id = ?", q.APIKeyID)
+	}
+	if q.Method != "" {
+		sqBuild = sqBuild.Where("a.method = ?", q.Method)
+	}
+	if q.Path != "" {
+		sqBuild = sqBuild.Where("starts_with(a.path, ?)", q.Path)
+	}
+	if q.Target != "" {
+		sqBuild = sqBuild.Where("a.target = ?", q.Target)
+	}
+	if q.TargetID != "" {
+		sqBuild = sqBuild.Where("a.target_ids @> array[?]::text[]", q.TargetID)
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &records, sqlStr, args...)
+	if records == nil {
+		records = make([]domain.Audit, 0)
+	}
+	return
+}
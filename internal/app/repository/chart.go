@@ -108,16 +108,20 @@ func (r *ChartRepo) Track(ctx context.Context, track *domain.Track) (err error)
 	return
 }
 
-func (r *ChartRepo) GetTrack(ctx context.Context, q domain.InputVesselsInterval) (tracks []domain.Track, err error) {
+// GetTrack of vessels, only points in zones if they are set
+func (r *ChartRepo) GetTrack(ctx context.Context, q domain.InputVesselsInterval, zones domain.ZoneNames) (tracks []domain.Track, err error) {
 	var (
 		sqlStr string
 		args   []interface{}
 	)
-	if sqlStr, args, err = sq.Select("time", "ST_AsGeoJSON(location)::json->>'coordinates' as location", "vessel_id", "v.name as vessel_name").
+	sqBuild := sq.Select("time", "ST_AsGeoJSON(location)::json->>'coordinates' as location", "vessel_id", vesselNameAt("t.vessel_id", "t.time")+" as vessel_name").
 		From(constant.DBTracks+" t").
 		LeftJoin(constant.DBVessels+" v on v.id = t.vessel_id ").
-		Where("time between $1 and $2 and vessel_id = any ($3)", q.StartOrLastPeriod(), q.FinishOrNow(), pq.Array(q.VesselIDs)).
-		ToSql(); err != nil {
+		Where("time between ? and ? and vessel_id = any (?)", q.StartOrLastPeriod(), q.FinishOrNow(), pq.Array(q.VesselIDs))
+	if zones != nil {
+		sqBuild = sqBuild.Where("exists(select 1 from "+constant.DBZones+" z where z.name = any(?) and st_contains(z.geometry, t.location))", zones)
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
 		return
 	}
 
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+type CredentialRepo struct {
+	db *sqlx.DB
+}
+
+func NewCredentialRepository(db *sqlx.DB) *CredentialRepo {
+	return &CredentialRepo{db: db}
+}
+
+func (r *CredentialRepo) AddVesselCredential(ctx context.Context, credential *domain.VesselCredential) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBVesselCredentials).
+		Columns("jti", "vessel_id", "comment", "issued_by", "issued_at", "expires_at").
+		Values(credential.JTI, credential.VesselID, credential.Comment, credential.IssuedBy, credential.IssuedAt, credential.ExpiresAt).
+		ToSql(); err != nil {
+		return
+	}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBVesselCredentials, "jti", pq.StringArray{credential.JTI}, nil)
+	})
+}
+
+// VesselCredentials not revoked and not expired, of vesselIDs or all
+func (r *CredentialRepo) VesselCredentials(ctx context.Context, vesselIDs ...domain.VesselID) (credentials []domain.VesselCredential, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select("c.jti", "c.vessel_id", "coalesce(v.name, '') as vessel_name", "c.comment",
+		"c.issued_by", "c.issued_at", "c.expires_at", "c.revoked_at", "c.revoked_by").
+		From(constant.DBVesselCredentials+" c").
+		LeftJoin(constant.DBVessels+" v on v.id = c.vessel_id").
+		Where("c.revoked_at is null and c.expires_at > ?", time.Now()).
+		OrderBy("c.issued_at desc")
+	if len(vesselIDs) > 0 {
+		sqBuild = sqBuild.Where("c.vessel_id = any(?)", pq.Array(vesselIDs))
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &credentials, sqlStr, args...)
+	if credentials == nil {
+		credentials = make([]domain.VesselCredential, 0)
+	}
+	return
+}
+
+// RevokeVesselCredentials not revoked yet, returns revoked
+func (r *CredentialRepo) RevokeVesselCredentials(ctx context.Context, userID domain.UserID, jtis ...string) (revoked []string, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBVesselCredentials).
+		Set("revoked_at", time.Now()).
+		Set("revoked_by", userID).
+		Where("jti = any(?)", pq.Array(jtis)).
+		Where("revoked_at is null").
+		Suffix("returning jti").
+		ToSql(); err != nil {
+		return
+	}
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBVesselCredentials, "jti", pq.StringArray(jtis)); err != nil {
+			return
+		}
+		if err = tx.SelectContext(ctx, &revoked, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBVesselCredentials, "jti", pq.StringArray(jtis), before)
+	})
+	return
+}
+
+// IsCredentialActive issued, not revoked and not expired
+func (r *CredentialRepo) IsCredentialActive(ctx context.Context, jti string) (ok bool, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("count(*) > 0").
+		From(constant.DBVesselCredentials).
+		Where(sqrl.Eq{"jti": jti}).
+		Where("revoked_at is null and expires_at > ?", time.Now()).
+		ToSql(); err != nil {
+		return
+	}
+	err = r.db.GetContext(ctx, &ok, sqlStr, args...)
+	return
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type VesselGroupRepo struct {
+	db *sqlx.DB
+}
+
+func NewVesselGroupRepository(db *sqlx.DB) *VesselGroupRepo {
+	return &VesselGroupRepo{db: db}
+}
+
+// VesselGroups with not deleted vessels, all if groupIDs are empty
+func (r *VesselGroupRepo) VesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) (groups []domain.VesselGroup, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select("g.id", "g.name", "g.created_by", "g.created_at",
+		"array(select gv.vessel_id from "+constant.DBGroupVessels+" gv "+
+			" join "+constant.DBVessels+" v on v.id = gv.vessel_id and v.is_deleted is not true "+
+			" where gv.group_id = g.id order by gv.vessel_id) as vessel_ids").
+		From(constant.DBVesselGroups + " g").
+		OrderBy("g.name")
+	if len(groupIDs) > 0 {
+		sqBuild = sqBuild.Where("g.id = any(?)", domain.VesselGroupIDs(groupIDs))
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &groups, sqlStr, args...)
+	if groups == nil {
+		groups = make([]domain.VesselGroup, 0)
+	}
+	return
+}
+
+func (r *VesselGroupRepo) AddVesselGroup(ctx context.Context, group *domain.VesselGroup) (id domain.VesselGroupID, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBVesselGroups).
+		Columns("name", "created_by", "created_at").
+		Values(group.Name, group.CreatedBy, time.Now()).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+		return
+	}
+	if err = setGroupVessels(ctx, tx, id, group.VesselIDs); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBVesselGroups, "id", domain.VesselGroupIDs{id}, nil); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// UpdateVesselGroup name and vessels
+func (r *VesselGroupRepo) UpdateVesselGroup(ctx context.Context, group *domain.VesselGroup) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBVesselGroups).
+		Set("name", group.Name).
+		Where(sqrl.Eq{"id": group.ID}).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	ids := domain.VesselGroupIDs{group.ID}
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBVesselGroups, "id", ids); err != nil {
+		return
+	}
+	var updatedID domain.VesselGroupID
+	if err = tx.GetContext(ctx, &updatedID, sqlStr, args...); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBGroupVessels+" where group_id = $1", group.ID); err != nil {
+		return
+	}
+	if err = setGroupVessels(ctx, tx, group.ID, group.VesselIDs); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBVesselGroups, "id", ids, before); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// setGroupVessels existing vessels of vesselIDs, unknown are skipped
+func setGroupVessels(ctx context.Context, tx *sqlx.Tx, groupID domain.VesselGroupID, vesselIDs domain.VesselIDs) (err error) {
+	if len(vesselIDs) == 0 {
+		return
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO"+" "+constant.DBGroupVessels+" (group_id, vessel_id) "+
+		" select $1, id from "+constant.DBVessels+" where id = any($2) on conflict do nothing", groupID, vesselIDs)
+	return
+}
+
+func (r *VesselGroupRepo) DeleteVesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) (err error) {
+	ids := domain.VesselGroupIDs(groupIDs)
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBVesselGroups, "id", ids); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBVesselGroups+" where id = any($1)", ids); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBVesselGroups, "id", ids, before)
+	})
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// ImportVessels rows in one transaction, status of each row is set in place, invalid rows are skipped.
+// Vessel with id is created with the id or renamed, without id - created by name or existing is returned.
+// Name of other (also deleted) vessel is a conflict. On dry run transaction is rolled back
+func (r *VesselRepo) ImportVessels(ctx context.Context, dryRun bool, rows []domain.VesselImportRow) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	// rows are checked before write, concurrent changes of vessels must wait
+	if _, err = tx.ExecContext(ctx, "LOCK TABLE"+" "+constant.DBVessels+" IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return
+	}
+	var (
+		now     = time.Now()
+		created bool
+		before  domain.AuditRows
+		ids     = make(domain.VesselIDs, 0)
+	)
+	if !dryRun {
+		for _, row := range rows {
+			if row.ID != nil && row.Status != domain.VesselImportInvalid {
+				ids = append(ids, *row.ID)
+			}
+		}
+		if before, err = auditRows(ctx, tx, constant.DBVessels, "id", ids); err != nil {
+			return
+		}
+	}
+	for i := range rows {
+		row := &rows[i]
+		if row.Status == domain.VesselImportInvalid {
+			continue
+		}
+		var byName, byID *importVessel
+		if byName, err = importVesselBy(ctx, tx, "name", row.Name); err != nil {
+			return
+		}
+		if row.ID == nil {
+			switch {
+			case byName == nil:
+				row.ID = new(domain.VesselID)
+				if err = tx.GetContext(ctx, row.ID, "INSERT INTO"+" "+constant.DBVessels+" (name) VALUES ($1) returning id", row.Name); err != nil {
+					return
+				}
+				row.Status = domain.VesselImportCreated
+			case byName.IsDeleted:
+				row.Status, row.Error = domain.VesselImportConflict, "name of deleted vessel "+byName.ID.String()
+			default:
+				row.ID, row.Status = &byName.ID, domain.VesselImportExisting
+			}
+			continue
+		}
+
+		if byID, err = importVesselBy(ctx, tx, "id", *row.ID); err != nil {
+			return
+		}
+		switch {
+		case byName != nil && byName.ID != *row.ID:
+			row.Status, row.Error = domain.VesselImportConflict, "name of vessel "+byName.ID.String()
+		case byID == nil:
+			if _, err = tx.ExecContext(ctx, "INSERT INTO"+" "+constant.DBVessels+" (id, name) VALUES ($1, $2)", *row.ID, row.Name); err != nil {
+				return
+			}
+			row.Status, created = domain.VesselImportCreated, true
+		case byID.IsDeleted:
+			row.Status, row.Error = domain.VesselImportConflict, "vessel is deleted"
+		case byID.Name == row.Name:
+			row.Status = domain.VesselImportExisting
+		default:
+			if _, err = tx.ExecContext(ctx, "UPDATE"+" "+constant.DBVessels+" set name = $2 where id = $1", *row.ID, row.Name); err != nil {
+				return
+			}
+			if _, err = tx.ExecContext(ctx, vesselRenameSQL, *row.ID, byID.Name, row.Name, now); err != nil {
+				return
+			}
+			row.Status = domain.VesselImportUpdated
+		}
+	}
+	if dryRun {
+		return
+	}
+	changed := make(domain.VesselIDs, 0)
+	for _, row := range rows {
+		if row.Status == domain.VesselImportCreated || row.Status == domain.VesselImportUpdated {
+			changed = append(changed, *row.ID)
+		}
+	}
+	// rows before are taken for all ids, only changed are recorded
+	if before, err = filterAuditRows(ctx, tx, before, "id", changed); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBVessels, "id", changed, before); err != nil {
+		return
+	}
+	if created {
+		// vessels created with explicit id, next generated id must not collide. Sequence is not transactional
+		if _, err = tx.ExecContext(ctx, "select setval(s.seq, greatest((select max(id) from "+constant.DBVessels+"), nextval(s.seq))) "+
+			" from (select pg_get_serial_sequence('"+constant.DBVessels+"', 'id') as seq) s"); err != nil {
+			return
+		}
+	}
+	err = tx.Commit()
+	return
+}
+
+type importVessel struct {
+	ID        domain.VesselID   `db:"id"`
+	Name      domain.VesselName `db:"name"`
+	IsDeleted bool              `db:"is_deleted"`
+}
+
+// importVesselBy column value, nil if not exists
+func importVesselBy(ctx context.Context, tx *sqlx.Tx, column string, value interface{}) (v *importVessel, err error) {
+	v = &importVessel{}
+	if err = tx.GetContext(ctx, v, "select id, name, is_deleted from "+constant.DBVessels+" where "+column+" = $1", value); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return
+}
@@ -4,8 +4,6 @@ import (
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	"context"
-	"database/sql"
-	"errors"
 	"github.com/jmoiron/sqlx"
 	"time"
 )
@@ -22,20 +20,19 @@ func (r *LogRepo) ControlLogAdd(ctx context.Context, logs ...domain.ControlLog)
 	if len(logs) == 0 {
 		return
 	}
-	var tx *sqlx.Tx
-	if tx, err = r.db.Beginx(); err != nil {
+	return inTx(r.db, func(tx *sqlx.Tx) error {
+		return controlLogAdd(ctx, tx, logs...)
+	})
+}
+
+// controlLogAdd in transaction of control change
+func controlLogAdd(ctx context.Context, tx *sqlx.Tx, logs ...domain.ControlLog) (err error) {
+	if len(logs) == 0 {
 		return
 	}
-	defer func() {
-		rErr := tx.Rollback()
-		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
-			err = errors.Join(err, rErr)
-		}
-	}()
-
 	var stmt *sqlx.Stmt
 	if stmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBControlLog+
-		" (vessel_id, timestamp, control, comment) VALUES($1, $2, $3, $4)"); err != nil {
+		" (vessel_id, timestamp, control, user_id, watchlist_id, comment) VALUES($1, $2, $3, $4, $5, $6)"); err != nil {
 		return
 	}
 
@@ -44,10 +41,44 @@ func (r *LogRepo) ControlLogAdd(ctx context.Context, logs ...domain.ControlLog)
 			log.Timestamp = time.Now()
 		}
 		if _, err = stmt.ExecContext(ctx,
-			log.Vessel.ID, log.Timestamp, log.Control, log.Comment); err != nil {
+			log.Vessel.ID, log.Timestamp, log.Control, log.UserID, log.WatchlistID, log.Comment); err != nil {
 			return
 		}
 	}
-	err = tx.Commit()
+	return
+}
+
+// ControlLogs of vessels in scope, now in one of its zones if restricted by zones
+func (r *LogRepo) ControlLogs(ctx context.Context, scope *domain.Scope, q domain.InputControlLog) (logs []domain.ControlLog, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select("l.id", "l.vessel_id", "coalesce("+vesselNameAt("l.vessel_id", "l.timestamp")+", '') as vessel_name",
+		"l.timestamp", "l.control", "l.user_id", "l.watchlist_id", "l.comment").
+		From(constant.DBControlLog+" l").
+		LeftJoin(constant.DBVessels+" v on v.id = l.vessel_id").
+		Where(vesselInScope("l.vessel_id", scope)).
+		OrderBy("l.timestamp desc", "l.id desc")
+	if q.Start != nil {
+		sqBuild = sqBuild.Where("l.timestamp >= ?", *q.Start)
+	}
+	if q.Finish != nil {
+		sqBuild = sqBuild.Where("l.timestamp <= ?", *q.Finish)
+	}
+	if len(q.VesselIDs) > 0 {
+		sqBuild = sqBuild.Where("l.vessel_id = any(?)", q.VesselIDs)
+	}
+	if len(q.UserIDs) > 0 {
+		sqBuild = sqBuild.Where("l.user_id = any(?)", q.UserIDs)
+	}
+	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &logs, sqlStr, args...)
+	if logs == nil {
+		logs = make([]domain.ControlLog, 0)
+	}
 	return
 }
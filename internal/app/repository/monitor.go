@@ -8,6 +8,7 @@ import (
 	"errors"
 	sqrl "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"time"
 )
 
@@ -19,50 +20,138 @@ func NewMonitorDBRepository(db *sqlx.DB) *MonitorDBCache {
 	return &MonitorDBCache{db: db}
 }
 
-func (r *MonitorDBCache) SetControl(ctx context.Context, control bool, vesselsItems ...*domain.Vessel) (err error) {
-	if len(vesselsItems) == 0 {
-		return errors.New("no vessels for control")
-	}
+// SetControl add (remove) vessels to watchlist of action, vessel is on control while any watchlist has it.
+// Action is logged and change of control is enqueued to webhooks in the same transaction.
+// Returns vessels with changed control state
+func (r *MonitorDBCache) SetControl(ctx context.Context, action domain.ControlAction, control bool, vessels domain.Vessels) (changed []domain.VesselID, err error) {
+	now := time.Now()
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if changed, err = setControl(ctx, tx, action.WatchlistID, control, nil, vessels.IDs(), now); err != nil {
+			return
+		}
+		return logControl(ctx, tx, action, control, vessels, changed, now)
+	})
+	return
+}
 
-	var tx *sqlx.Tx
-	if tx, err = r.db.Beginx(); err != nil {
+// logControl log action on every vessel and enqueue webhooks of changed control state in tx of change
+func logControl(ctx context.Context, tx *sqlx.Tx, action domain.ControlAction, control bool, vessels domain.Vessels, changed domain.VesselIDs, now time.Time) (err error) {
+	logs, payloads := action.Events(control, vessels, changed, now)
+	if err = controlLogAdd(ctx, tx, logs...); err != nil {
 		return
 	}
-	defer func() {
-		rErr := tx.Rollback()
-		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
-			err = errors.Join(err, rErr)
-		}
-	}()
+	return webhookEnqueue(ctx, tx, payloads...)
+}
 
-	var stmt *sqlx.Stmt
+// setControl add (remove) vessels to watchlist in tx, returns vessels with changed control state.
+// Entries added by window (windowID is set) are owned by it: window does not take over entries already in list,
+// manual add takes over entries of windows, end of window removes only owned entries.
+// List entries and dashboard rows of vessels are audited
+func setControl(ctx context.Context, tx *sqlx.Tx, watchlistID domain.WatchlistID, control bool, windowID *int64, vesselIDs []domain.VesselID, now time.Time) (changed []domain.VesselID, err error) {
+	if len(vesselIDs) == 0 {
+		return nil, errors.New("no vessels for control")
+	}
+
+	ids := domain.VesselIDs(vesselIDs)
+	var beforeList, beforeState domain.AuditRows
+	if beforeList, err = auditRows(ctx, tx, constant.DBWatchlistVessels, "vessel_id", ids); err != nil {
+		return
+	}
+	if beforeState, err = auditRows(ctx, tx, constant.DBControlDashboard, "vessel_id", ids); err != nil {
+		return
+	}
 	if control {
-		if stmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBControlDashboard+
-			" (vessel_id, state, control_start) "+
-			" VALUES($1, $2, $3 ) "+
-			" on conflict (vessel_id) do update set state = $2, control_start = $3 "); err != nil {
+		changed, err = addControl(ctx, tx, watchlistID, windowID, vesselIDs, now)
+	} else {
+		changed, err = removeControl(ctx, tx, watchlistID, windowID, vesselIDs, now)
+	}
+	if err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBWatchlistVessels, "vessel_id", ids, beforeList); err != nil {
+		return
+	}
+	err = writeAudit(ctx, tx, constant.DBControlDashboard, "vessel_id", ids, beforeState)
+	return
+}
+
+// addControl add vessels to watchlist and set them on control, returns vessels set on control
+func addControl(ctx context.Context, tx *sqlx.Tx, watchlistID domain.WatchlistID, windowID *int64, vesselIDs []domain.VesselID, now time.Time) (changed []domain.VesselID, err error) {
+	onConflict := " on conflict (watchlist_id, vessel_id) do update set window_id = null " +
+		" where " + constant.DBWatchlistVessels + ".window_id is not null"
+	if windowID != nil {
+		onConflict = " on conflict do nothing"
+	}
+	var listStmt, stateStmt *sqlx.Stmt
+	if listStmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBWatchlistVessels+
+		" (watchlist_id, vessel_id, added_at, window_id) "+
+		" VALUES($1, $2, $3, $4) "+onConflict); err != nil {
+		return
+	}
+	if stateStmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBControlDashboard+" as d "+
+		" (vessel_id, state, control_start, contact) "+
+		" VALUES($1, true, $2, '"+string(domain.ContactOK)+"') "+
+		" on conflict (vessel_id) do update set state = true, control_start = $2, contact = excluded.contact "+
+		" where d.state is false "+
+		" returning d.vessel_id"); err != nil {
+		return
+	}
+	for _, vesselID := range vesselIDs {
+		if _, err = listStmt.ExecContext(ctx, watchlistID, vesselID, now, windowID); err != nil {
 			return
 		}
-	} else {
-		if stmt, err = tx.PreparexContext(ctx, "INSERT INTO"+" "+constant.DBControlDashboard+
-			" (vessel_id, state,  control_end) "+
-			" VALUES($1, $2, $3 ) "+
-			" on conflict (vessel_id) do update set state = $2, control_end = $3 "); err != nil {
+		var id domain.VesselID
+		if err = stateStmt.GetContext(ctx, &id, vesselID, now); err == nil {
+			changed = append(changed, id)
+		} else if !errors.Is(err, sql.ErrNoRows) {
 			return
 		}
-
+		err = nil
 	}
-	for _, vesselItem := range vesselsItems {
-		if _, err = stmt.ExecContext(ctx, vesselItem.ID, control, time.Now()); err != nil {
+	return
+}
+
+// removeControl remove vessels from watchlist (only entries of window if it is set), returns vessels released from control
+func removeControl(ctx context.Context, tx *sqlx.Tx, watchlistID domain.WatchlistID, windowID *int64, vesselIDs []domain.VesselID, now time.Time) (changed []domain.VesselID, err error) {
+	if windowID != nil {
+		// entry passes to another running window of list
+		if _, err = tx.ExecContext(ctx, "UPDATE"+" "+constant.DBWatchlistVessels+" w set window_id = ( "+
+			" select s.id from "+constant.DBControlSchedule+" s "+
+			" where s.watchlist_id = w.watchlist_id and s.vessel_id = w.vessel_id and s.id <> $3 "+
+			" and s.started_at is not null and s.ended_at is null and s.canceled_at is null "+
+			" order by s.end_at desc nulls first limit 1) "+
+			" where w.watchlist_id = $1 and w.vessel_id = any($2) and w.window_id = $3",
+			watchlistID, domain.VesselIDs(vesselIDs), *windowID); err != nil {
 			return
 		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBWatchlistVessels+
+			" where watchlist_id = $1 and vessel_id = any($2) and window_id = $3",
+			watchlistID, domain.VesselIDs(vesselIDs), *windowID)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBWatchlistVessels+
+			" where watchlist_id = $1 and vessel_id = any($2)",
+			watchlistID, domain.VesselIDs(vesselIDs))
+	}
+	if err != nil {
+		return
 	}
-	err = tx.Commit()
+	return releaseControl(ctx, tx, vesselIDs, now)
+}
 
+// releaseControl unset control of vessels not referenced by any watchlist, returns released
+func releaseControl(ctx context.Context, tx *sqlx.Tx, vesselIDs []domain.VesselID, now time.Time) (released []domain.VesselID, err error) {
+	err = tx.SelectContext(ctx, &released, "UPDATE"+" "+constant.DBControlDashboard+" d set state = false, control_end = $2 "+
+		" where d.vessel_id = any($1) and d.state is true "+
+		" and not exists (select 1 from "+constant.DBWatchlistVessels+" w where w.vessel_id = d.vessel_id) "+
+		" returning d.vessel_id", domain.VesselIDs(vesselIDs), now)
 	return
 }
 
 func (r *MonitorDBCache) GetStates(ctx context.Context, vesselIDs ...domain.VesselID) (vStates []*domain.VesselState, err error) {
+	return getStates(ctx, r.db, vesselIDs...)
+}
+
+func getStates(ctx context.Context, q sqlx.QueryerContext, vesselIDs ...domain.VesselID) (vStates []*domain.VesselState, err error) {
 	var (
 		sqlStr string
 		args   []interface{}
@@ -75,7 +164,9 @@ func (r *MonitorDBCache) GetStates(ctx context.Context, vesselIDs ...domain.Vess
 		"control_end",
 		"ST_AsGeoJSON(location)::json->>'coordinates' as location",
 		"current_zone",
+		"contact",
 		"extract(epoch from age(timestamp, (current_zone::jsonb->>'timeIn')::timestamptz))::real as zone_duration",
+		zoneDurations+" as zone_durations",
 	).
 		From(constant.DBControlDashboard + " d").
 		LeftJoin(constant.DBVessels + " v on v.id = d.vessel_id ").
@@ -84,12 +175,13 @@ func (r *MonitorDBCache) GetStates(ctx context.Context, vesselIDs ...domain.Vess
 		return
 	}
 
-	err = r.db.SelectContext(ctx, &vStates, sqlStr, args...)
+	err = sqlx.SelectContext(ctx, q, &vStates, sqlStr, args...)
 
 	return
 }
 
-func (r *MonitorDBCache) UpdateState(ctx context.Context, vesselID domain.VesselID, v *domain.VesselState) (err error) {
+// UpdateState of vessel, webhooks of track events are enqueued in the same transaction
+func (r *MonitorDBCache) UpdateState(ctx context.Context, vesselID domain.VesselID, v *domain.VesselState, payloads ...domain.WebhookPayload) (err error) {
 	if v == nil {
 		err = errors.New("updateState: input data nil")
 		return
@@ -103,28 +195,57 @@ func (r *MonitorDBCache) UpdateState(ctx context.Context, vesselID domain.Vessel
 		Columns(
 			"vessel_id", "state", "timestamp",
 			"control_start", "control_end", "location",
-			"current_zone").
+			"current_zone", "contact").
 		Values(vesselID, v.State, v.Timestamp,
-			v.ControlStart, v.ControlEnd, v.Location, v.CurrentZone).
-		Suffix("on conflict (vessel_id) do update set state = $2, timestamp = $3, control_start = $4,control_end = $5, location = $6, current_zone = $7").
+			v.ControlStart, v.ControlEnd, v.Location, v.CurrentZone, v.Contact).
+		Suffix("on conflict (vessel_id) do update set state = $2, timestamp = $3, control_start = $4,control_end = $5, location = $6, current_zone = $7, contact = $8").
 		ToSql(); err != nil {
 		return
 	}
-	_, err = r.db.ExecContext(ctx, sqlStr, args...)
-	return
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return webhookEnqueue(ctx, tx, payloads...)
+	})
+}
 
+// monitoredIn condition on dashboard of vessels on control from watchlist or, if not set, from all lists of user
+func monitoredIn(userID domain.UserID, watchlistID domain.WatchlistID) sqrl.Sqlizer {
+	var lists sqrl.Sqlizer = userWatchlists("watchlist_id", userID)
+	if watchlistID > 0 {
+		lists = sqrl.Eq{"watchlist_id": watchlistID}
+	}
+	return sqrl.And{
+		sqrl.Expr("d.state is true"),
+		sqrl.Expr("d.vessel_id in (select vessel_id from "+constant.DBWatchlistVessels+" where ?)", lists),
+	}
 }
 
-func (r *MonitorDBCache) MonitoredVessels(ctx context.Context) (vessels domain.Vessels, err error) {
+const (
+	// currentZones on dashboard as jsonb array, empty if not set
+	currentZones = "(case when jsonb_typeof(d.current_zone::jsonb->'zones') = 'array' then d.current_zone::jsonb->'zones' else '[]'::jsonb end)"
+	// inZone condition on dashboard of vessels in any zone
+	inZone = "jsonb_array_length(" + currentZones + ") > 0"
+	// zoneEntries on dashboard as jsonb object zone: entry time, empty if not set
+	zoneEntries = "(case when jsonb_typeof(d.current_zone::jsonb->'entries') = 'object' then d.current_zone::jsonb->'entries' else '{}'::jsonb end)"
+	// zoneDurations on dashboard as json object zone: seconds since entry
+	zoneDurations = "(select jsonb_object_agg(e.key, extract(epoch from age(d.timestamp, e.value::timestamptz))) from jsonb_each_text(" + zoneEntries + ") e)"
+	// longestZoneDuration on dashboard seconds in the earliest entered of current zones
+	longestZoneDuration = "(select extract(epoch from age(d.timestamp, min(e.value::timestamptz)))::real from jsonb_each_text(" + zoneEntries + ") e)"
+)
+
+// MonitoredVessels on control from watchlist or, if not set, from all lists of user, in scope
+func (r *MonitorDBCache) MonitoredVessels(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) (vessels []domain.MonitoredVessel, err error) {
 	var (
 		sqlStr string
 		args   []interface{}
 	)
-
-	if sqlStr, args, err = sq.Select("v.id as vessel_id", "v.name as vessel_name").
+	if sqlStr, args, err = sq.Select("v.id as vessel_id", "v.name as vessel_name", "d.contact", "d.timestamp").
 		From(constant.DBControlDashboard + " d").
 		LeftJoin(constant.DBVessels + " v on v.id = d.vessel_id ").
-		Where("d.state is true").
+		Where(monitoredIn(userID, watchlistID)).
+		Where(inScope(scope)).
 		ToSql(); err != nil {
 		return
 	}
@@ -132,3 +253,303 @@ func (r *MonitorDBCache) MonitoredVessels(ctx context.Context) (vessels domain.V
 	err = r.db.SelectContext(ctx, &vessels, sqlStr, args...)
 	return
 }
+
+// MonitorSummary of vessels on control from watchlist or, if not set, from all lists of user, in scope,
+// dwellTop vessels with the longest time in current zones
+func (r *MonitorDBCache) MonitorSummary(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, dwellTop uint64) (summary domain.MonitorSummary, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("count(*) as monitored",
+		"count(*) filter (where not "+inZone+") as no_zone",
+		"count(*) filter (where d.contact = '"+string(domain.ContactStale)+"') as stale",
+		"count(*) filter (where d.contact = '"+string(domain.ContactLost)+"') as lost").
+		From(constant.DBControlDashboard + " d").
+		Where(monitoredIn(userID, watchlistID)).
+		Where(inScope(scope)).
+		ToSql(); err != nil {
+		return
+	}
+	if err = r.db.GetContext(ctx, &summary, sqlStr, args...); err != nil {
+		return
+	}
+
+	if sqlStr, args, err = sq.Select("z.zone_name", "count(*) as vessels").
+		From(constant.DBControlDashboard+" d").
+		CrossJoin("jsonb_array_elements_text("+currentZones+") as z(zone_name)").
+		Where(monitoredIn(userID, watchlistID)).
+		Where(inScope(scope)).
+		Where(zoneInScope("z.zone_name", scope)).
+		GroupBy("z.zone_name").
+		OrderBy("vessels desc", "z.zone_name").
+		ToSql(); err != nil {
+		return
+	}
+	summary.Zones = make([]domain.ZoneCount, 0)
+	if err = r.db.SelectContext(ctx, &summary.Zones, sqlStr, args...); err != nil {
+		return
+	}
+
+	if sqlStr, args, err = sq.Select("v.id as vessel_id", "v.name as vessel_name", "d.current_zone",
+		longestZoneDuration+" as zone_duration").
+		From(constant.DBControlDashboard+" d").
+		LeftJoin(constant.DBVessels+" v on v.id = d.vessel_id ").
+		Where(monitoredIn(userID, watchlistID)).
+		Where(inScope(scope)).
+		Where(inZone).
+		OrderBy("zone_duration desc nulls last", "d.vessel_id").
+		Limit(dwellTop).
+		ToSql(); err != nil {
+		return
+	}
+	summary.LongestDwell = make([]domain.ZoneDwell, 0)
+	err = r.db.SelectContext(ctx, &summary.LongestDwell, sqlStr, args...)
+	return
+}
+
+// ZoneEntries predicted within horizon for vessels on control from watchlist or, if not set, from all lists of user.
+// Path is extrapolated from the last track point by course and speed since the earliest point of track window,
+// vessels with lost contact are skipped. Only vessels and zones in scope
+func (r *MonitorDBCache) ZoneEntries(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, horizon time.Duration) (entries []domain.ZoneEntry, err error) {
+	motion := sq.Select("d.vessel_id", "last.time", "last.location",
+		"ST_Distance(prev.location::geography, last.location::geography) / extract(epoch from last.time - prev.time) as speed",
+		"ST_Azimuth(prev.location::geography, last.location::geography) as azimuth").
+		From(constant.DBControlDashboard+" d").
+		JoinClause("cross join lateral (select time, location from "+constant.DBTracks+" t "+
+			" where t.vessel_id = d.vessel_id order by time desc limit 1) last").
+		JoinClause("cross join lateral (select time, location from "+constant.DBTracks+" t "+
+			" where t.vessel_id = d.vessel_id and t.time < last.time and t.time >= last.time - ? * interval '1 second' "+
+			" order by time limit 1) prev", constant.PredictionTrackWindow.Seconds()).
+		Where(monitoredIn(userID, watchlistID)).
+		Where(inScope(scope)).
+		Where("d.contact <> ?", domain.ContactLost)
+
+	path := sq.Select("m.vessel_id", "m.time", "m.location", "m.speed", "m.azimuth").
+		Column(sqrl.Expr("ST_MakeLine(m.location, ST_Project(m.location::geography, m.speed * ?, m.azimuth)::geometry) as path", horizon.Seconds())).
+		FromSelect(motion, "m").
+		Where("m.speed >= ? and m.azimuth is not null", constant.PredictionMinSpeed)
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("p.vessel_id", "coalesce(v.name, '') as vessel_name", "z.name as zone_name",
+		"p.time as timestamp", "ST_AsGeoJSON(p.location)::json->>'coordinates' as location",
+		"p.speed", "degrees(p.azimuth) as course").
+		Column(sqrl.Expr("p.time + make_interval(secs => ? * "+
+			"ST_LineLocatePoint(p.path, ST_ClosestPoint(ST_Intersection(p.path, z.geometry), p.location))) as eta", horizon.Seconds())).
+		FromSelect(path, "p").
+		Join(constant.DBZones+" z on ST_Intersects(p.path, z.geometry) and not ST_Contains(z.geometry, p.location)").
+		LeftJoin(constant.DBVessels+" v on v.id = p.vessel_id").
+		Where(zoneInScope("z.name", scope)).
+		OrderBy("eta", "p.vessel_id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &entries, sqlStr, args...)
+	return
+}
+
+// CheckContact set stale or lost contact of monitored vessels silent since staleSince or lostSince
+// (last track or control start), returns states with changed status. Loss of contact is enqueued to webhooks
+// in the same transaction. Ok status is restored by track
+func (r *MonitorDBCache) CheckContact(ctx context.Context, staleSince, lostSince, now time.Time) (states []*domain.VesselState, err error) {
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if states, err = checkContact(ctx, tx, staleSince, lostSince); err != nil || len(states) == 0 {
+			return
+		}
+		var payloads []domain.WebhookPayload
+		for _, state := range states {
+			if state.Contact == domain.ContactLost {
+				payloads = append(payloads, domain.WebhookPayload{
+					Event:     domain.WebhookEventVesselLost,
+					Timestamp: now,
+					Vessel:    state.Vessel,
+					Location:  state.Location,
+				})
+			}
+		}
+		return webhookEnqueue(ctx, tx, payloads...)
+	})
+	return
+}
+
+func checkContact(ctx context.Context, tx *sqlx.Tx, staleSince, lostSince time.Time) (states []*domain.VesselState, err error) {
+	var vesselIDs []domain.VesselID
+	if err = tx.SelectContext(ctx, &vesselIDs, "UPDATE"+" "+constant.DBControlDashboard+" d set contact = c.contact from ("+
+		" select vessel_id, case "+
+		"  when greatest(timestamp, control_start) < $2 then '"+string(domain.ContactLost)+"' "+
+		"  when greatest(timestamp, control_start) < $1 then '"+string(domain.ContactStale)+"' "+
+		"  else '"+string(domain.ContactOK)+"' end as contact "+
+		" from "+constant.DBControlDashboard+" where state is true) c "+
+		" where c.vessel_id = d.vessel_id and c.contact <> d.contact and c.contact <> '"+string(domain.ContactOK)+"' "+
+		" returning d.vessel_id",
+		staleSince, lostSince); err != nil || len(vesselIDs) == 0 {
+		return
+	}
+	return getStates(ctx, tx, vesselIDs...)
+}
+
+// ScheduleControl save windows and set vessels on control now in one transaction: manual vessels without window
+// and vessels of started windows, entries of the latter are owned by windows. Action on immediate vessels is logged
+// in the same transaction. Returns vessels with changed control state
+func (r *MonitorDBCache) ScheduleControl(ctx context.Context, action domain.ControlAction, manual, immediate domain.Vessels, windows ...domain.ControlWindow) (changed []domain.VesselID, err error) {
+	now := time.Now()
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var (
+			sqlStr string
+			args   []interface{}
+			ids    = make(pq.Int64Array, 0, len(windows))
+		)
+		for _, w := range windows {
+			if sqlStr, args, err = sq.Insert(constant.DBControlSchedule).
+				Columns("watchlist_id", "vessel_id", "start_at", "end_at", "started_at", "user_id", "comment", "created_at").
+				Values(w.WatchlistID, w.Vessel.ID, w.Start, w.End, w.StartedAt, w.UserID, w.Comment, now).
+				Suffix("returning id").
+				ToSql(); err != nil {
+				return
+			}
+			var id int64
+			if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+				return
+			}
+			ids = append(ids, id)
+			if w.StartedAt == nil {
+				continue
+			}
+			var started []domain.VesselID
+			if started, err = setControl(ctx, tx, w.WatchlistID, true, &id, []domain.VesselID{w.Vessel.ID}, now); err != nil {
+				return
+			}
+			changed = append(changed, started...)
+		}
+		if len(manual) > 0 {
+			var started []domain.VesselID
+			if started, err = setControl(ctx, tx, action.WatchlistID, true, nil, manual.IDs(), now); err != nil {
+				return
+			}
+			changed = append(changed, started...)
+		}
+		if err = logControl(ctx, tx, action, true, immediate, changed, now); err != nil {
+			return
+		}
+		if len(ids) == 0 {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBControlSchedule, "id", ids, nil)
+	})
+	return
+}
+
+// ControlWindows not ended and not canceled of user watchlists
+func (r *MonitorDBCache) ControlWindows(ctx context.Context, userID domain.UserID) (windows []domain.ControlWindow, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("s.id", "s.watchlist_id", "s.vessel_id", "v.name as vessel_name",
+		"s.start_at", "s.end_at", "s.started_at", "s.user_id", "s.comment", "s.created_at").
+		From(constant.DBControlSchedule+" s").
+		Join(constant.DBVessels+" v on v.id = s.vessel_id").
+		Where("s.ended_at is null and s.canceled_at is null").
+		Where(userWatchlists("s.watchlist_id", userID)).
+		OrderBy("coalesce(s.start_at, s.end_at)", "s.id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &windows, sqlStr, args...)
+	return
+}
+
+// DueControlWindows not canceled windows due to start or, if started, due to end at now
+func (r *MonitorDBCache) DueControlWindows(ctx context.Context, now time.Time) (windows []domain.ControlWindow, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("id", "watchlist_id", "vessel_id", "start_at", "end_at", "started_at",
+		"user_id", "comment", "created_at").
+		From(constant.DBControlSchedule).
+		Where("ended_at is null and canceled_at is null").
+		Where("(started_at is null and start_at <= ? or started_at is not null and end_at <= ?)", now, now).
+		OrderBy("id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &windows, sqlStr, args...)
+	return
+}
+
+// ApplyControlWindow mark window started (control is true) or ended, set its vessel on (off) control and log it
+// in one transaction, sql.ErrNoRows if window is not due any more. Returns vessels with changed control state
+func (r *MonitorDBCache) ApplyControlWindow(ctx context.Context, w *domain.ControlWindow, control bool, vessels domain.Vessels, now time.Time) (changed []domain.VesselID, err error) {
+	sqlStr := "UPDATE" + " " + constant.DBControlSchedule + " set started_at = $2 " +
+		" where id = $1 and start_at <= $2 and started_at is null and canceled_at is null "
+	if !control {
+		sqlStr = "UPDATE" + " " + constant.DBControlSchedule + " set ended_at = $2 " +
+			" where id = $1 and end_at <= $2 and started_at is not null and ended_at is null and canceled_at is null "
+	}
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		ids := pq.Int64Array{w.ID}
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBControlSchedule, "id", ids); err != nil {
+			return
+		}
+		var window domain.ControlWindow
+		if err = tx.GetContext(ctx, &window, sqlStr+" returning id, watchlist_id, vessel_id", w.ID, now); err != nil {
+			return
+		}
+		if changed, err = setControl(ctx, tx, window.WatchlistID, control, &window.ID, []domain.VesselID{window.Vessel.ID}, now); err != nil {
+			return
+		}
+		if err = logControl(ctx, tx, w.Action(), control, vessels, changed, now); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBControlSchedule, "id", ids, before)
+	})
+	return
+}
+
+// CancelControl remove vessels from watchlist of action, cancel not ended windows of vessels in watchlist
+// and log it in one transaction. Returns vessels with changed control state
+func (r *MonitorDBCache) CancelControl(ctx context.Context, action domain.ControlAction, vessels domain.Vessels) (changed []domain.VesselID, err error) {
+	var (
+		sqlStr    string
+		args      []interface{}
+		vesselIDs = vessels.IDs()
+	)
+	if sqlStr, args, err = sq.Select("id").
+		From(constant.DBControlSchedule).
+		Where(sqrl.Eq{"watchlist_id": action.WatchlistID, "vessel_id": vesselIDs}).
+		Where("ended_at is null and canceled_at is null").
+		Suffix("for update").
+		ToSql(); err != nil {
+		return
+	}
+	now := time.Now()
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var ids pq.Int64Array
+		if err = tx.SelectContext(ctx, &ids, sqlStr, args...); err != nil {
+			return
+		}
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBControlSchedule, "id", ids); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE"+" "+constant.DBControlSchedule+" set canceled_at = $2 where id = any($1)", ids, now); err != nil {
+			return
+		}
+		if changed, err = setControl(ctx, tx, action.WatchlistID, false, nil, vesselIDs, now); err != nil {
+			return
+		}
+		if err = logControl(ctx, tx, action, false, vessels, changed, now); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBControlSchedule, "id", ids, before)
+	})
+	return
+}
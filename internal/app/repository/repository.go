@@ -1,29 +1,71 @@
 package repository
 
 import (
+	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	"context"
+	"database/sql"
+	"errors"
 	sqrl "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 var sq = sqrl.StatementBuilder.PlaceholderFormat(sqrl.Dollar)
 
+// inTx runs fn in transaction, committed if fn succeeds
+func inTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return
+	}
+	return tx.Commit()
+}
+
 type Repository struct {
 	Chart
 	Monitor
 	Vessels
 	Log
 	User
+	Alert
+	Webhook
+	Watchlist
+	VesselGroup
+	Credential
+	Session
+	Role
+	Scope
+	APIKey
+	Audit
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
-		Chart:   NewChartRepository(db),
-		Monitor: NewMonitorDBRepository(db),
-		Vessels: NewVesselRepository(db),
-		Log:     NewLogRepository(db),
-		User:    NewUserRepository(db),
+		Chart:       NewChartRepository(db),
+		Monitor:     NewMonitorDBRepository(db),
+		Vessels:     NewVesselRepository(db),
+		Log:         NewLogRepository(db),
+		User:        NewUserRepository(db),
+		Alert:       NewAlertRepository(db),
+		Webhook:     NewWebhookRepository(db),
+		Watchlist:   NewWatchlistRepository(db),
+		VesselGroup: NewVesselGroupRepository(db),
+		Credential:  NewCredentialRepository(db),
+		Session:     NewSessionRepository(db),
+		Role:        NewRoleRepository(db),
+		Scope:       NewScopeRepository(db),
+		APIKey:      NewAPIKeyRepository(db),
+		Audit:       NewAuditRepository(db),
 	}
 }
 
@@ -32,30 +74,124 @@ type Chart interface {
 	Vessels(ctx context.Context, query domain.InputZones) (vesselIDs []domain.VesselID, err error)
 	ZonesByLocation(ctx context.Context, location domain.Point) (zones []domain.ZoneName, err error)
 	Track(ctx context.Context, track *domain.Track) (err error)
-	GetTrack(ctx context.Context, query domain.InputVesselsInterval) (tracks []domain.Track, err error)
+	GetTrack(ctx context.Context, query domain.InputVesselsInterval, zones domain.ZoneNames) (tracks []domain.Track, err error)
 }
 
 type Vessels interface {
 	GetVessels(ctx context.Context, vesselIDs ...domain.VesselID) (domain.Vessels, error)
 	AddVessel(ctx context.Context, vesselNames ...domain.VesselName) (vessels domain.Vessels, err error)
 	SetDeleteVessels(ctx context.Context, delete bool, vesselIDS ...domain.VesselID) error
-	UpdateVessels(ctx context.Context, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error)
+	UpdateVessels(ctx context.Context, renamedAt time.Time, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error)
+	VesselNames(ctx context.Context, vesselID domain.VesselID) ([]domain.VesselNameRecord, error)
+	ExportVessel(ctx context.Context, vesselID domain.VesselID) (*domain.VesselArchive, error)
+	PurgeVessel(ctx context.Context, vesselID domain.VesselID, export bool) (*domain.VesselArchive, error)
+	ImportVessels(ctx context.Context, dryRun bool, rows []domain.VesselImportRow) error
+	SearchVessels(ctx context.Context, q domain.InputVesselSearch) (domain.Vessels, error)
+	ListVessels(ctx context.Context, q domain.InputVesselList, cursor *domain.VesselCursor, limit uint64) ([]domain.VesselInfo, error)
 }
 
 type User interface {
 	GetUserByLogin(ctx context.Context, login domain.UserLogin) (user *domain.UserDB, err error)
+	GetUserByID(ctx context.Context, id domain.UserID) (user *domain.UserDB, err error)
+	ListUsers(ctx context.Context, q domain.InputUserList, cursor *domain.UserCursor, limit uint64) ([]domain.UserDB, error)
 	AddUser(ctx context.Context, user *domain.UserDB) (id domain.UserID, err error)
 	UpdateUser(ctx context.Context, user *domain.UserDB) (err error)
 	SetDeletedUser(ctx context.Context, delete bool, userIDs ...domain.UserID) (err error)
 }
 
 type Monitor interface {
-	SetControl(ctx context.Context, status bool, vessels ...*domain.Vessel) error
+	SetControl(ctx context.Context, action domain.ControlAction, status bool, vessels domain.Vessels) ([]domain.VesselID, error)
 	GetStates(ctx context.Context, vesselID ...domain.VesselID) ([]*domain.VesselState, error)
-	UpdateState(ctx context.Context, vesselID domain.VesselID, v *domain.VesselState) error
-	MonitoredVessels(ctx context.Context) (domain.Vessels, error)
+	UpdateState(ctx context.Context, vesselID domain.VesselID, v *domain.VesselState, payloads ...domain.WebhookPayload) error
+	MonitoredVessels(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) ([]domain.MonitoredVessel, error)
+	MonitorSummary(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, dwellTop uint64) (domain.MonitorSummary, error)
+	ZoneEntries(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, horizon time.Duration) ([]domain.ZoneEntry, error)
+	CheckContact(ctx context.Context, staleSince, lostSince, now time.Time) ([]*domain.VesselState, error)
+	ScheduleControl(ctx context.Context, action domain.ControlAction, manual, immediate domain.Vessels, windows ...domain.ControlWindow) ([]domain.VesselID, error)
+	CancelControl(ctx context.Context, action domain.ControlAction, vessels domain.Vessels) ([]domain.VesselID, error)
+	ControlWindows(ctx context.Context, userID domain.UserID) ([]domain.ControlWindow, error)
+	DueControlWindows(ctx context.Context, now time.Time) ([]domain.ControlWindow, error)
+	ApplyControlWindow(ctx context.Context, window *domain.ControlWindow, control bool, vessels domain.Vessels, now time.Time) ([]domain.VesselID, error)
 }
 
 type Log interface {
 	ControlLogAdd(ctx context.Context, log ...domain.ControlLog) error
+	ControlLogs(ctx context.Context, scope *domain.Scope, q domain.InputControlLog) ([]domain.ControlLog, error)
+}
+
+type Alert interface {
+	AlertRules(ctx context.Context) ([]domain.AlertRule, error)
+	VesselAlertRules(ctx context.Context, vesselID domain.VesselID) ([]domain.AlertRule, error)
+	AddAlertRule(ctx context.Context, rule *domain.AlertRule) (domain.AlertRuleID, error)
+	UpdateAlertRule(ctx context.Context, rule *domain.AlertRule) error
+	DeleteAlertRules(ctx context.Context, ruleIDs ...domain.AlertRuleID) error
+	AddAlerts(ctx context.Context, alerts ...domain.Alert) error
+	Alerts(ctx context.Context, scope *domain.Scope, query domain.InputAlerts) ([]domain.Alert, error)
+	AcknowledgeAlerts(ctx context.Context, userID domain.UserID, alertIDs ...domain.AlertID) error
+}
+
+type Webhook interface {
+	Webhooks(ctx context.Context) ([]domain.Webhook, error)
+	AddWebhook(ctx context.Context, webhook *domain.Webhook) (domain.WebhookID, error)
+	UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	DeleteWebhooks(ctx context.Context, webhookIDs ...domain.WebhookID) error
+	WebhookClaim(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookMessage, error)
+	WebhookAttempt(ctx context.Context, delivery *domain.WebhookDelivery, delivered bool, retryAt *time.Time) error
+	WebhookDeliveries(ctx context.Context, webhookID domain.WebhookID, limit uint64) ([]domain.WebhookDelivery, error)
+}
+
+type Watchlist interface {
+	PersonalWatchlist(ctx context.Context, userID domain.UserID) (domain.WatchlistID, error)
+	IsWatchlistMember(ctx context.Context, userID domain.UserID, watchlistID domain.WatchlistID) (bool, error)
+	Watchlists(ctx context.Context, userID domain.UserID) ([]domain.Watchlist, error)
+	AddWatchlist(ctx context.Context, watchlist *domain.Watchlist) (domain.WatchlistID, error)
+	UpdateWatchlist(ctx context.Context, watchlist *domain.Watchlist) error
+	OwnWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) ([]domain.Watchlist, error)
+	DeleteWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) error
+}
+
+type VesselGroup interface {
+	VesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) ([]domain.VesselGroup, error)
+	AddVesselGroup(ctx context.Context, group *domain.VesselGroup) (domain.VesselGroupID, error)
+	UpdateVesselGroup(ctx context.Context, group *domain.VesselGroup) error
+	DeleteVesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) error
+}
+
+type Credential interface {
+	AddVesselCredential(ctx context.Context, credential *domain.VesselCredential) error
+	VesselCredentials(ctx context.Context, vesselIDs ...domain.VesselID) ([]domain.VesselCredential, error)
+	RevokeVesselCredentials(ctx context.Context, userID domain.UserID, jtis ...string) ([]string, error)
+	IsCredentialActive(ctx context.Context, jti string) (bool, error)
+}
+
+type Session interface {
+	AddRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, hash string, next *domain.RefreshToken) (*domain.RefreshToken, error)
+	RevokeRefreshFamily(ctx context.Context, hash string) (domain.UserID, error)
+}
+
+type Role interface {
+	Roles(ctx context.Context) ([]domain.Role, error)
+	AddRole(ctx context.Context, role *domain.Role) (constant.Role, error)
+	UpdateRole(ctx context.Context, role *domain.Role) error
+	DeleteRoles(ctx context.Context, roleIDs ...constant.Role) (inUse bool, err error)
+}
+
+type Scope interface {
+	UserScope(ctx context.Context, userID domain.UserID) (*domain.UserScope, error)
+	SetUserScope(ctx context.Context, scope *domain.UserScope) error
+}
+
+type APIKey interface {
+	APIKeys(ctx context.Context) ([]domain.APIKey, error)
+	AddAPIKey(ctx context.Context, key *domain.APIKey) (domain.APIKeyID, error)
+	RotateAPIKey(ctx context.Context, id domain.APIKeyID, prefix, hash string, graceUntil time.Time) (*domain.APIKey, error)
+	RevokeAPIKeys(ctx context.Context, ids ...domain.APIKeyID) ([]domain.APIKeyID, error)
+	APIKeyByHash(ctx context.Context, hash string, now time.Time) (*domain.APIKey, error)
+	TouchAPIKey(ctx context.Context, id domain.APIKeyID, at time.Time) error
+}
+
+type Audit interface {
+	AddAudit(ctx context.Context, req *domain.AuditRequest) error
+	Audit(ctx context.Context, q domain.InputAudit, cursor *domain.AuditCursor, limit uint64) ([]domain.Audit, error)
 }
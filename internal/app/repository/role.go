@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var errNoRoleBit = errors.New("no free role bit")
+
+type RoleRepo struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepo {
+	return &RoleRepo{db: db}
+}
+
+func (r *RoleRepo) Roles(ctx context.Context) (roles []domain.Role, err error) {
+	err = r.db.SelectContext(ctx, &roles, "select id, name, permissions, is_builtin from "+constant.DBRoles+" order by id")
+	if roles == nil {
+		roles = make([]domain.Role, 0)
+	}
+	return
+}
+
+// AddRole with the lowest bit not used by roles
+func (r *RoleRepo) AddRole(ctx context.Context, role *domain.Role) (id constant.Role, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, "LOCK TABLE "+constant.DBRoles+" IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return
+	}
+	var used constant.Role
+	if err = tx.GetContext(ctx, &used, "select coalesce(bit_or(id), 0) from "+constant.DBRoles); err != nil {
+		return
+	}
+	for i := 0; i < constant.RoleMaxBits && id == 0; i++ {
+		if bit := constant.Role(1) << i; !used.CheckIsRole(bit) {
+			id = bit
+		}
+	}
+	if id == 0 {
+		return 0, errNoRoleBit
+	}
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBRoles).
+		Columns("id", "name", "permissions").
+		Values(id, role.Name, role.Permissions).
+		ToSql(); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBRoles, "id", pq.Int64Array{int64(id)}, nil); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// UpdateRole name and permissions, sql.ErrNoRows if role does not exist
+func (r *RoleRepo) UpdateRole(ctx context.Context, role *domain.Role) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBRoles).
+		Set("name", role.Name).
+		Set("permissions", role.Permissions).
+		Where(sqrl.Eq{"id": role.ID}).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	ids := pq.Int64Array{int64(role.ID)}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBRoles, "id", ids); err != nil {
+			return
+		}
+		var id constant.Role
+		if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBRoles, "id", ids, before)
+	})
+}
+
+// DeleteRoles not builtin and not assigned to any user, deleted too, or to not revoked api key:
+// role bit of user or key would be granted by a new role. Nothing is deleted if some role is in use
+func (r *RoleRepo) DeleteRoles(ctx context.Context, roleIDs ...constant.Role) (inUse bool, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	ids := make(pq.Int64Array, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		ids = append(ids, int64(id))
+	}
+	if _, err = tx.ExecContext(ctx, "LOCK TABLE "+constant.DBUsers+", "+constant.DBAPIKeys+" IN SHARE MODE"); err != nil {
+		return
+	}
+	if err = tx.GetContext(ctx, &inUse, "select exists(select 1 from "+constant.DBRoles+" where id = any($1) and is_builtin) "+
+		" or exists(select 1 from "+constant.DBUsers+" u where exists(select 1 from unnest($1::integer[]) r(id) where u.role & r.id <> 0)) "+
+		" or exists(select 1 from "+constant.DBAPIKeys+" k where k.revoked_at is null "+
+		"  and exists(select 1 from unnest($1::integer[]) r(id) where k.role & r.id <> 0))", ids); err != nil || inUse {
+		return
+	}
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBRoles, "id", ids); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBRoles+" where id = any($1)", ids); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBRoles, "id", ids, before); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+type ScopeRepo struct {
+	db *sqlx.DB
+}
+
+func NewScopeRepository(db *sqlx.DB) *ScopeRepo {
+	return &ScopeRepo{db: db}
+}
+
+// UserScope restriction of user, sql.ErrNoRows if user is not restricted
+func (r *ScopeRepo) UserScope(ctx context.Context, userID domain.UserID) (scope *domain.UserScope, err error) {
+	scope = new(domain.UserScope)
+	err = r.db.GetContext(ctx, scope, "select user_id, zone_names, vessel_ids, group_ids from "+constant.DBUserScopes+" where user_id = $1", userID)
+	return
+}
+
+// SetUserScope replaces restriction of user, empty scope removes it
+func (r *ScopeRepo) SetUserScope(ctx context.Context, scope *domain.UserScope) (err error) {
+	var (
+		sqlStr = "DELETE FROM" + " " + constant.DBUserScopes + " where user_id = $1"
+		args   = []interface{}{scope.UserID}
+	)
+	if !scope.IsEmpty() {
+		if sqlStr, args, err = sq.Insert(constant.DBUserScopes).
+			Columns("user_id", "zone_names", "vessel_ids", "group_ids").
+			Values(scope.UserID, scope.ZoneNames, scope.VesselIDs, scope.GroupIDs).
+			Suffix("on conflict (user_id) do update set zone_names = excluded.zone_names, " +
+				" vessel_ids = excluded.vessel_ids, group_ids = excluded.group_ids").
+			ToSql(); err != nil {
+			return
+		}
+	}
+	ids := domain.UserIDs{scope.UserID}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBUserScopes, "user_id", ids); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBUserScopes, "user_id", ids, before)
+	})
+}
+
+// inScope condition on dashboard of vessels in scope, now in one of its zones if restricted by zones
+func inScope(scope *domain.Scope) sqrl.Sqlizer {
+	cond := sqrl.And{}
+	if scope == nil {
+		return cond
+	}
+	if scope.VesselIDs != nil {
+		cond = append(cond, sqrl.Expr("d.vessel_id = any(?)", scope.VesselIDs))
+	}
+	if scope.ZoneNames != nil {
+		cond = append(cond, sqrl.Expr("exists(select 1 from jsonb_array_elements_text("+currentZones+") z(zone_name) where z.zone_name = any(?))", scope.ZoneNames))
+	}
+	return cond
+}
+
+// vesselInScope condition on column of vessel id: vessel in scope, now in one of its zones if restricted by zones
+func vesselInScope(column string, scope *domain.Scope) sqrl.Sqlizer {
+	if scope == nil {
+		return sqrl.And{}
+	}
+	return sqrl.Expr("exists(select 1 from "+constant.DBControlDashboard+" d where d.vessel_id = "+column+" and ?)", inScope(scope))
+}
+
+// zoneInScope condition on column of zone name: zone in scope
+func zoneInScope(column string, scope *domain.Scope) sqrl.Sqlizer {
+	if scope == nil || scope.ZoneNames == nil {
+		return sqrl.And{}
+	}
+	return sqrl.Expr(column+" = any(?)", scope.ZoneNames)
+}
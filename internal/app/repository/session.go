@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type SessionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+func (r *SessionRepo) AddRefreshToken(ctx context.Context, token *domain.RefreshToken) (err error) {
+	return addRefreshToken(ctx, r.db, token)
+}
+
+func addRefreshToken(ctx context.Context, db sqlx.ExecerContext, token *domain.RefreshToken) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBRefreshTokens).
+		Columns("token_hash", "family_id", "user_id", "issued_at", "expires_at").
+		Values(token.Hash, token.FamilyID, token.UserID, token.IssuedAt, token.ExpiresAt).
+		ToSql(); err != nil {
+		return
+	}
+	_, err = db.ExecContext(ctx, sqlStr, args...)
+	return
+}
+
+// RotateRefreshToken marks token by hash as used and saves next token of the same family and user.
+// Used token is returned as it was before: if it is not active nothing is saved, reuse of used token revokes the family.
+// sql.ErrNoRows if token not exists
+func (r *SessionRepo) RotateRefreshToken(ctx context.Context, hash string, next *domain.RefreshToken) (used *domain.RefreshToken, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+		now    = time.Now()
+	)
+	if sqlStr, args, err = sq.Select("token_hash", "family_id", "user_id", "issued_at", "expires_at", "used_at", "revoked_at").
+		From(constant.DBRefreshTokens).
+		Where(sqrl.Eq{"token_hash": hash}).
+		Suffix("for update").
+		ToSql(); err != nil {
+		return
+	}
+	used = new(domain.RefreshToken)
+	if err = tx.GetContext(ctx, used, sqlStr, args...); err != nil {
+		return nil, err
+	}
+	switch {
+	case used.RevokedAt != nil:
+		return
+	case used.UsedAt != nil:
+		if err = revokeRefreshFamily(ctx, tx, used.FamilyID, now); err != nil {
+			return
+		}
+	case !used.IsActive(now):
+		return
+	default:
+		if _, err = tx.ExecContext(ctx, "UPDATE"+" "+constant.DBRefreshTokens+" set used_at = $2 where token_hash = $1", hash, now); err != nil {
+			return
+		}
+		next.FamilyID, next.UserID = used.FamilyID, used.UserID
+		if err = addRefreshToken(ctx, tx, next); err != nil {
+			return
+		}
+	}
+	err = tx.Commit()
+	return
+}
+
+// RevokeRefreshFamily of token by hash, returns user of token. sql.ErrNoRows if token not exists
+func (r *SessionRepo) RevokeRefreshFamily(ctx context.Context, hash string) (userID domain.UserID, err error) {
+	var token domain.RefreshToken
+	if err = r.db.GetContext(ctx, &token, "select family_id, user_id from "+constant.DBRefreshTokens+" where token_hash = $1", hash); err != nil {
+		return
+	}
+	return token.UserID, revokeRefreshFamily(ctx, r.db, token.FamilyID, time.Now())
+}
+
+func revokeRefreshFamily(ctx context.Context, db sqlx.ExecerContext, familyID string, now time.Time) (err error) {
+	_, err = db.ExecContext(ctx, "UPDATE"+" "+constant.DBRefreshTokens+" set revoked_at = $2 where family_id = $1 and revoked_at is null", familyID, now)
+	return
+}
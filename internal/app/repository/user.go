@@ -35,6 +35,59 @@ func (r *UserRepo) GetUserByLogin(ctx context.Context, login domain.UserLogin) (
 	return
 }
 
+// GetUserByID deleted too
+func (r *UserRepo) GetUserByID(ctx context.Context, id domain.UserID) (user *domain.UserDB, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	user = new(domain.UserDB)
+	if sqlStr, args, err = sq.Select("id", "login", "hash", "created_at", "modified_at", "is_deleted", "role").
+		From(constant.DBUsers).
+		Where(sqrl.Eq{"id": id}).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.GetContext(ctx, user, sqlStr, args...)
+	return
+}
+
+func (r *UserRepo) ListUsers(ctx context.Context, q domain.InputUserList, cursor *domain.UserCursor, limit uint64) (users []domain.UserDB, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select("id", "login", "created_at", "modified_at", "is_deleted", "role").
+		From(constant.DBUsers)
+	if q.Deleted {
+		sqBuild = sqBuild.Where("is_deleted")
+	} else {
+		sqBuild = sqBuild.Where("is_deleted is not true")
+	}
+	if q.Role != 0 {
+		sqBuild = sqBuild.Where("role & ? <> 0", q.Role)
+	}
+	if q.Login != "" {
+		sqBuild = sqBuild.Where("login ilike ?", "%"+likeEscaper.Replace(q.Login)+"%")
+	}
+	if cursor != nil {
+		sqBuild = sqBuild.Where("login > ?", cursor.Login)
+	}
+	if sqlStr, args, err = sqBuild.
+		OrderBy("login").
+		Limit(limit).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &users, sqlStr, args...)
+	if users == nil {
+		users = make([]domain.UserDB, 0)
+	}
+	return
+}
+
 func (r *UserRepo) AddUser(ctx context.Context, user *domain.UserDB) (id domain.UserID, err error) {
 	var (
 		sqlStr string
@@ -62,7 +115,12 @@ func (r *UserRepo) AddUser(ctx context.Context, user *domain.UserDB) (id domain.
 		return
 	}
 
-	err = r.db.GetContext(ctx, &id, sqlStr, args...)
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBUsers, "id", domain.UserIDs{id}, nil)
+	})
 	return
 }
 
@@ -89,10 +147,18 @@ func (r *UserRepo) UpdateUser(ctx context.Context, user *domain.UserDB) (err err
 		return
 	}
 
-	var updatedID int
-	err = r.db.GetContext(ctx, &updatedID, sqlStr, args...)
-
-	return
+	ids := domain.UserIDs{user.ID}
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBUsers, "id", ids); err != nil {
+			return
+		}
+		var updatedID int
+		if err = tx.GetContext(ctx, &updatedID, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBUsers, "id", ids, before)
+	})
 }
 
 func (r *UserRepo) SetDeletedUser(ctx context.Context, delete bool, userIDs ...domain.UserID) (err error) {
@@ -106,6 +172,14 @@ func (r *UserRepo) SetDeletedUser(ctx context.Context, delete bool, userIDs ...d
 		ToSql(); err != nil {
 		return
 	}
-	_, err = r.db.ExecContext(ctx, sqlStr, args...)
-	return
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBUsers, "id", domain.UserIDs(userIDs)); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBUsers, "id", domain.UserIDs(userIDs), before)
+	})
 }
@@ -9,8 +9,25 @@ import (
 	sqrl "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"strings"
+	"time"
 )
 
+// vesselColumns of vessel with attributes
+var vesselColumns = []string{"id as vessel_id", "name as vessel_name",
+	"imo", "mmsi", "call_sign", "flag", "vessel_type", "length", "beam"}
+
+// vesselNameAt sql expression: name of vessel valid at time, current name if vessel was not renamed
+func vesselNameAt(vesselColumn, timeColumn string) string {
+	return "coalesce((select n.name from " + constant.DBVesselNames + " n where n.vessel_id = " + vesselColumn +
+		" and n.valid_from <= " + timeColumn + " order by n.valid_from desc limit 1), v.name)"
+}
+
+// vesselRenameSQL records rename of vessel $1 from name $2 to $3 at $4, previous name is valid before the first rename
+var vesselRenameSQL = "INSERT INTO" + " " + constant.DBVesselNames + " (vessel_id, name, valid_from) " +
+	" VALUES ($1, $2, '-infinity'), ($1, $3, $4) " +
+	" on conflict (vessel_id, valid_from) do update set name = excluded.name where excluded.valid_from <> '-infinity'"
+
 type VesselRepo struct {
 	db *sqlx.DB
 }
@@ -24,7 +41,7 @@ func (r *VesselRepo) GetVessels(ctx context.Context, vesselIDs ...domain.VesselI
 		sqlStr string
 		args   []interface{}
 	)
-	if sqlStr, args, err = sq.Select("id as vessel_id", "name as vessel_name").
+	if sqlStr, args, err = sq.Select(vesselColumns...).
 		From(constant.DBVessels).
 		Where("id = any($1) and is_deleted is not true", pq.Array(vesselIDs)).
 		ToSql(); err != nil {
@@ -52,20 +69,31 @@ func (r *VesselRepo) AddVessel(ctx context.Context, vesselNames ...domain.Vessel
 		}
 	}
 	// Имена уникальные, при совпадении добавляемого имени вернем уже существующий
-	sqBuild = sqBuild.Suffix("on CONFLICT (name) DO UPDATE SET name=EXCLUDED.name where t.is_deleted is not true returning id as vessel_id, name as vessel_name")
+	sqBuild = sqBuild.Suffix("on CONFLICT (name) DO UPDATE SET name=EXCLUDED.name where t.is_deleted is not true returning " + strings.Join(vesselColumns, ", "))
 
 	if sqlStr, args, err = sqBuild.ToSql(); err != nil {
 		return
 	}
 
-	err = r.db.SelectContext(ctx, &vessels, sqlStr, args...)
-	if vessels == nil {
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBVessels, "name", pq.Array(vesselNames)); err != nil {
+			return
+		}
+		if err = tx.SelectContext(ctx, &vessels, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBVessels, "id", domain.VesselIDs(vessels.IDs()), before)
+	})
+	if vessels == nil || err != nil {
 		vessels = make([]*domain.Vessel, 0)
 	}
 	return
 }
 
-func (r *VesselRepo) UpdateVessels(ctx context.Context, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error) {
+// UpdateVessels name and attributes. Rename is recorded in history from renamedAt, the first rename also records
+// the previous name as valid before
+func (r *VesselRepo) UpdateVessels(ctx context.Context, renamedAt time.Time, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error) {
 	var tx *sqlx.Tx
 	if tx, err = r.db.Beginx(); err != nil {
 		return
@@ -80,23 +108,45 @@ func (r *VesselRepo) UpdateVessels(ctx context.Context, vessels ...domain.Vessel
 
 	var (
 		stmt   *sqlx.Stmt
-		sqlStr = "UPDATE" + " " + constant.DBVessels + " set name = $2 " +
-			" where is_deleted is not true and id = $1 and (select count(name) from " + constant.DBVessels + " where id <> $1 and name = $2) = 0 " +
-			" returning id as vessel_id, name as vessel_name"
+		sqlStr = "UPDATE" + " " + constant.DBVessels + " set name = $2, " +
+			" imo = $3, mmsi = $4, call_sign = $5, flag = $6, vessel_type = $7, length = $8, beam = $9 " +
+			" from (select id as old_id, name as old_name from " + constant.DBVessels + " where id = $1) o " +
+			" where id = o.old_id and is_deleted is not true and (select count(name) from " + constant.DBVessels + " where id <> $1 and name = $2) = 0 " +
+			" returning " + strings.Join(vesselColumns, ", ") + ", o.old_name"
 	)
+	ids := make(domain.VesselIDs, 0, len(vessels))
+	for _, vessel := range vessels {
+		ids = append(ids, vessel.ID)
+	}
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBVessels, "id", ids); err != nil {
+		return
+	}
 	if stmt, err = tx.PreparexContext(ctx, sqlStr); err != nil {
 		return
 	}
 	for _, vessel := range vessels {
-		var v domain.Vessel
-		if er := stmt.GetContext(ctx, &v, vessel.ID, vessel.Name); er != nil {
+		var v struct {
+			domain.Vessel
+			OldName domain.VesselName `db:"old_name"`
+		}
+		if er := stmt.GetContext(ctx, &v, vessel.ID, vessel.Name, vessel.IMO, vessel.MMSI, vessel.CallSign,
+			vessel.Flag, vessel.Type, vessel.Length, vessel.Beam); er != nil {
 			if errors.Is(er, sql.ErrNoRows) {
 				continue
 			}
 			err = errors.Join(err, er)
 			return
 		}
-		savedVessels = append(savedVessels, &v)
+		if v.OldName != v.Name {
+			if _, err = tx.ExecContext(ctx, vesselRenameSQL, v.ID, v.OldName, v.Name, renamedAt); err != nil {
+				return
+			}
+		}
+		savedVessels = append(savedVessels, &v.Vessel)
+	}
+	if err = writeAudit(ctx, tx, constant.DBVessels, "id", ids, before); err != nil {
+		return
 	}
 	err = tx.Commit()
 	if savedVessels == nil {
@@ -105,6 +155,135 @@ func (r *VesselRepo) UpdateVessels(ctx context.Context, vessels ...domain.Vessel
 	return
 }
 
+// VesselNames history of renames, empty if vessel was not renamed
+func (r *VesselRepo) VesselNames(ctx context.Context, vesselID domain.VesselID) (names []domain.VesselNameRecord, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("name", "case when valid_from = '-infinity' then null else valid_from end as valid_from").
+		From(constant.DBVesselNames).
+		Where(sqrl.Eq{"vessel_id": vesselID}).
+		OrderBy("valid_from").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &names, sqlStr, args...)
+	return
+}
+
+// SearchVessels not deleted by full-text query and attributes, the most relevant first
+func (r *VesselRepo) SearchVessels(ctx context.Context, q domain.InputVesselSearch) (vessels domain.Vessels, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqBuild := sq.Select(vesselColumns...).
+		From(constant.DBVessels).
+		Where("is_deleted is not true")
+	if tsQuery := q.TSQuery(); tsQuery != "" {
+		sqBuild = sqBuild.Where("search @@ to_tsquery('simple', ?)", tsQuery).
+			OrderByClause("ts_rank(search, to_tsquery('simple', ?)) desc", tsQuery)
+	}
+	if q.IMO != "" {
+		sqBuild = sqBuild.Where(sqrl.Eq{"imo": q.IMO})
+	}
+	if q.MMSI != "" {
+		sqBuild = sqBuild.Where(sqrl.Eq{"mmsi": q.MMSI})
+	}
+	if q.CallSign != "" {
+		sqBuild = sqBuild.Where("upper(call_sign) = upper(?)", q.CallSign)
+	}
+	if q.Flag != "" {
+		sqBuild = sqBuild.Where("flag = upper(?)", q.Flag)
+	}
+	if q.Type != "" {
+		sqBuild = sqBuild.Where("lower(vessel_type) = lower(?)", q.Type)
+	}
+	if q.LengthMin != nil {
+		sqBuild = sqBuild.Where("length >= ?", *q.LengthMin)
+	}
+	if q.LengthMax != nil {
+		sqBuild = sqBuild.Where("length <= ?", *q.LengthMax)
+	}
+	if sqlStr, args, err = sqBuild.OrderBy("name").Limit(constant.VesselSearchLimit).ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &vessels, sqlStr, args...)
+	if vessels == nil {
+		vessels = make(domain.Vessels, 0)
+	}
+	return
+}
+
+// vesselSortKeys not null sort expressions of ListVessels columns
+var vesselSortKeys = map[string]string{
+	domain.VesselSortName:     "coalesce(vessel_name, '')",
+	domain.VesselSortCreated:  "created_at",
+	domain.VesselSortLastSeen: "coalesce(last_seen, '-infinity')",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListVessels page of vessels ordered by sort key and id, after cursor if set
+func (r *VesselRepo) ListVessels(ctx context.Context, q domain.InputVesselList, cursor *domain.VesselCursor, limit uint64) (vessels []domain.VesselInfo, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	sqInner := sq.Select(vesselColumns...).
+		Columns("v.created_at", "v.is_deleted", "coalesce(d.state, false) as monitored",
+			"(select max(time) from "+constant.DBTracks+" t where t.vessel_id = v.id) as last_seen").
+		From(constant.DBVessels + " v").
+		LeftJoin(constant.DBControlDashboard + " d on d.vessel_id = v.id")
+	if q.Deleted {
+		sqInner = sqInner.Where("v.is_deleted")
+	} else {
+		sqInner = sqInner.Where("v.is_deleted is not true")
+	}
+	if q.Monitored != nil {
+		sqInner = sqInner.Where("coalesce(d.state, false) = ?", *q.Monitored)
+	}
+	if q.Name != "" {
+		pattern := likeEscaper.Replace(q.Name) + "%"
+		if q.Match != domain.VesselMatchPrefix {
+			pattern = "%" + pattern
+		}
+		sqInner = sqInner.Where("v.name ilike ?", pattern)
+	}
+
+	sortKey, ok := vesselSortKeys[q.Sort]
+	if !ok {
+		sortKey = vesselSortKeys[domain.VesselSortName]
+	}
+	order, compare := " asc", " > "
+	if q.Desc {
+		order, compare = " desc", " < "
+	}
+	sqBuild := sq.Select("*").FromSelect(sqInner, "s")
+	if cursor != nil {
+		var key interface{} = cursor.Name
+		if q.Sort == domain.VesselSortCreated || q.Sort == domain.VesselSortLastSeen {
+			key = sqrl.Expr("coalesce(?::timestamptz, '-infinity')", cursor.Time)
+		}
+		sqBuild = sqBuild.Where(sqrl.Expr("("+sortKey+", vessel_id)"+compare+"(?, ?)", key, cursor.ID))
+	}
+	if sqlStr, args, err = sqBuild.
+		OrderBy(sortKey+order, "vessel_id"+order).
+		Limit(limit).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &vessels, sqlStr, args...)
+	if vessels == nil {
+		vessels = make([]domain.VesselInfo, 0)
+	}
+	return
+}
+
 func (r *VesselRepo) SetDeleteVessels(ctx context.Context, delete bool, vesselIDs ...domain.VesselID) (err error) {
 	var (
 		sqlStr string
@@ -116,6 +295,14 @@ func (r *VesselRepo) SetDeleteVessels(ctx context.Context, delete bool, vesselID
 		ToSql(); err != nil {
 		return
 	}
-	_, err = r.db.ExecContext(ctx, sqlStr, args...)
-	return
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBVessels, "id", domain.VesselIDs(vesselIDs)); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBVessels, "id", domain.VesselIDs(vesselIDs), before)
+	})
 }
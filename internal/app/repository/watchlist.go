@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+// personalWatchlistName of list created on first use
+const personalWatchlistName = "personal"
+
+type WatchlistRepo struct {
+	db *sqlx.DB
+}
+
+func NewWatchlistRepository(db *sqlx.DB) *WatchlistRepo {
+	return &WatchlistRepo{db: db}
+}
+
+// userWatchlists condition on watchlist_id column of lists user owns or is member of
+func userWatchlists(column string, userID domain.UserID) sqrl.Sqlizer {
+	return sqrl.Expr(column+" in (select id from "+constant.DBWatchlists+" where owner_id = ?"+
+		" union select watchlist_id from "+constant.DBWatchlistMembers+" where user_id = ?)", userID, userID)
+}
+
+// PersonalWatchlist of user, created if not exist
+func (r *WatchlistRepo) PersonalWatchlist(ctx context.Context, userID domain.UserID) (id domain.WatchlistID, err error) {
+	err = r.db.GetContext(ctx, &id, "INSERT INTO"+" "+constant.DBWatchlists+" (name, owner_id, personal, created_at) "+
+		" VALUES($1, $2, true, $3) "+
+		" on conflict (owner_id) where personal do update set owner_id = excluded.owner_id "+
+		" returning id", personalWatchlistName, userID, time.Now())
+	return
+}
+
+// IsWatchlistMember user is owner or member of watchlist
+func (r *WatchlistRepo) IsWatchlistMember(ctx context.Context, userID domain.UserID, watchlistID domain.WatchlistID) (ok bool, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("count(*) > 0").
+		From(constant.DBWatchlists).
+		Where(sqrl.Eq{"id": watchlistID}).
+		Where(userWatchlists("id", userID)).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.GetContext(ctx, &ok, sqlStr, args...)
+	return
+}
+
+func (r *WatchlistRepo) Watchlists(ctx context.Context, userID domain.UserID) (watchlists []domain.Watchlist, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("w.id", "w.name", "w.owner_id", "w.personal", "w.created_at",
+		"array(select user_id from "+constant.DBWatchlistMembers+" m where m.watchlist_id = w.id order by user_id) as members",
+		"array(select vessel_id from "+constant.DBWatchlistVessels+" v where v.watchlist_id = w.id order by vessel_id) as vessel_ids").
+		From(constant.DBWatchlists+" w").
+		Where(userWatchlists("w.id", userID)).
+		OrderBy("w.personal desc", "w.id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &watchlists, sqlStr, args...)
+	if watchlists == nil {
+		watchlists = make([]domain.Watchlist, 0)
+	}
+	return
+}
+
+func (r *WatchlistRepo) AddWatchlist(ctx context.Context, watchlist *domain.Watchlist) (id domain.WatchlistID, err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBWatchlists).
+		Columns("name", "owner_id", "created_at").
+		Values(watchlist.Name, watchlist.OwnerID, time.Now()).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+		return
+	}
+	if err = setWatchlistMembers(ctx, tx, id, watchlist.Members); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBWatchlists, "id", pq.Array([]domain.WatchlistID{id}), nil); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// UpdateWatchlist name and members of team list by owner
+func (r *WatchlistRepo) UpdateWatchlist(ctx context.Context, watchlist *domain.Watchlist) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBWatchlists).
+		Set("name", watchlist.Name).
+		Where(sqrl.Eq{"id": watchlist.ID, "owner_id": watchlist.OwnerID}).
+		Where("personal is not true").
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+	ids := pq.Array([]domain.WatchlistID{watchlist.ID})
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBWatchlists, "id", ids); err != nil {
+		return
+	}
+	var updatedID domain.WatchlistID
+	if err = tx.GetContext(ctx, &updatedID, sqlStr, args...); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBWatchlistMembers+" where watchlist_id = $1", watchlist.ID); err != nil {
+		return
+	}
+	if err = setWatchlistMembers(ctx, tx, watchlist.ID, watchlist.Members); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBWatchlists, "id", ids, before); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func setWatchlistMembers(ctx context.Context, tx *sqlx.Tx, watchlistID domain.WatchlistID, members domain.UserIDs) (err error) {
+	if len(members) == 0 {
+		return
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO"+" "+constant.DBWatchlistMembers+" (watchlist_id, user_id) "+
+		" select $1, unnest($2::bigint[]) on conflict do nothing", watchlistID, members)
+	return
+}
+
+// OwnWatchlists team lists of owner from watchlistIDs
+func (r *WatchlistRepo) OwnWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) (watchlists []domain.Watchlist, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("w.id", "w.name", "w.owner_id", "w.personal", "w.created_at",
+		"array(select vessel_id from "+constant.DBWatchlistVessels+" v where v.watchlist_id = w.id order by vessel_id) as vessel_ids").
+		From(constant.DBWatchlists+" w").
+		Where("w.id = any(?)", pq.Array(watchlistIDs)).
+		Where(sqrl.Eq{"w.owner_id": ownerID}).
+		Where("w.personal is not true").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &watchlists, sqlStr, args...)
+	return
+}
+
+func (r *WatchlistRepo) DeleteWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) (err error) {
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var deleted []domain.WatchlistID
+		if err = tx.SelectContext(ctx, &deleted, "select id from "+constant.DBWatchlists+
+			" where id = any($1) and owner_id = $2 and personal is not true for update", pq.Array(watchlistIDs), ownerID); err != nil {
+			return
+		}
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBWatchlists, "id", pq.Array(deleted)); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, "DELETE FROM"+" "+constant.DBWatchlists+" where id = any($1)", pq.Array(deleted)); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBWatchlists, "id", pq.Array(deleted), before)
+	})
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	sqrl "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+type WebhookRepo struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) Webhooks(ctx context.Context) (webhooks []domain.Webhook, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("id", "url", "events", "created_at").
+		From(constant.DBWebhooks).
+		Where("is_deleted is not true").
+		OrderBy("id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &webhooks, sqlStr, args...)
+	if webhooks == nil {
+		webhooks = make([]domain.Webhook, 0)
+	}
+	return
+}
+
+func (r *WebhookRepo) AddWebhook(ctx context.Context, webhook *domain.Webhook) (id domain.WebhookID, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if webhook.Events == nil {
+		webhook.Events = domain.WebhookEvents{}
+	}
+	if sqlStr, args, err = sq.Insert(constant.DBWebhooks).
+		Columns("url", "secret", "events", "created_at").
+		Values(webhook.URL, webhook.Secret, webhook.Events, time.Now()).
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+
+	err = inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		if err = tx.GetContext(ctx, &id, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBWebhooks, "id", pq.Array([]domain.WebhookID{id}), nil)
+	})
+	return
+}
+
+func (r *WebhookRepo) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) (err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if webhook.Events == nil {
+		webhook.Events = domain.WebhookEvents{}
+	}
+	updMap := map[string]interface{}{
+		"url":    webhook.URL,
+		"events": webhook.Events,
+	}
+	if len(webhook.Secret) > 0 {
+		updMap["secret"] = webhook.Secret
+	}
+	if sqlStr, args, err = sq.Update(constant.DBWebhooks).
+		SetMap(updMap).
+		Where(sqrl.Eq{"id": webhook.ID}).
+		Where("is_deleted is not true").
+		Suffix("returning id").
+		ToSql(); err != nil {
+		return
+	}
+
+	ids := pq.Array([]domain.WebhookID{webhook.ID})
+	return inTx(r.db, func(tx *sqlx.Tx) (err error) {
+		var before domain.AuditRows
+		if before, err = auditRows(ctx, tx, constant.DBWebhooks, "id", ids); err != nil {
+			return
+		}
+		var updatedID domain.WebhookID
+		if err = tx.GetContext(ctx, &updatedID, sqlStr, args...); err != nil {
+			return
+		}
+		return writeAudit(ctx, tx, constant.DBWebhooks, "id", ids, before)
+	})
+}
+
+// DeleteWebhooks soft delete, not delivered messages are dropped
+func (r *WebhookRepo) DeleteWebhooks(ctx context.Context, webhookIDs ...domain.WebhookID) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Update(constant.DBWebhooks).
+		Set("is_deleted", true).
+		Where(sqrl.Eq{"id": webhookIDs}).
+		ToSql(); err != nil {
+		return
+	}
+	var before domain.AuditRows
+	if before, err = auditRows(ctx, tx, constant.DBWebhooks, "id", pq.Array(webhookIDs)); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return
+	}
+	if err = writeAudit(ctx, tx, constant.DBWebhooks, "id", pq.Array(webhookIDs), before); err != nil {
+		return
+	}
+	if sqlStr, args, err = sq.Update(constant.DBWebhookOutbox).
+		Set("failed_at", time.Now()).
+		Where(sqrl.Eq{"webhook_id": webhookIDs}).
+		Where("delivered_at is null and failed_at is null").
+		ToSql(); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+// webhookEnqueue add messages to outbox for every subscription of their events,
+// in transaction of change which caused events
+func webhookEnqueue(ctx context.Context, q sqlx.ExecerContext, payloads ...domain.WebhookPayload) (err error) {
+	for _, payload := range payloads {
+		var body []byte
+		if body, err = json.Marshal(payload); err != nil {
+			return
+		}
+		if _, err = q.ExecContext(ctx, "INSERT INTO"+" "+constant.DBWebhookOutbox+" (webhook_id, event, payload) "+
+			" select id, $1::varchar, $2::jsonb from "+constant.DBWebhooks+
+			" where is_deleted is not true and (cardinality(events) = 0 or $1::varchar = any(events))",
+			payload.Event, string(body)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// WebhookClaim take due messages for delivery, they are not due again until lease end
+func (r *WebhookRepo) WebhookClaim(ctx context.Context, limit int, lease time.Duration) (messages []domain.WebhookMessage, err error) {
+	err = r.db.SelectContext(ctx, &messages, "UPDATE"+" "+constant.DBWebhookOutbox+" o set next_attempt_at = $1 "+
+		" from "+constant.DBWebhooks+" w "+
+		" where w.id = o.webhook_id and o.id in ("+
+		"  select id from "+constant.DBWebhookOutbox+
+		"  where delivered_at is null and failed_at is null and next_attempt_at <= now() "+
+		"  order by next_attempt_at, id limit $2 for update skip locked) "+
+		" returning o.id, o.webhook_id, w.url, w.secret, o.event, o.payload, o.attempts",
+		time.Now().Add(lease), limit)
+	return
+}
+
+// WebhookAttempt log delivery attempt and set message delivered, failed (retryAt nil) or due to retryAt
+func (r *WebhookRepo) WebhookAttempt(ctx context.Context, delivery *domain.WebhookDelivery, delivered bool, retryAt *time.Time) (err error) {
+	var tx *sqlx.Tx
+	if tx, err = r.db.Beginx(); err != nil {
+		return
+	}
+	defer func() {
+		rErr := tx.Rollback()
+		if rErr != nil && !errors.Is(rErr, sql.ErrTxDone) {
+			err = errors.Join(err, rErr)
+		}
+	}()
+
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Insert(constant.DBWebhookDelivery).
+		Columns("outbox_id", "webhook_id", "attempt", "timestamp", "status_code", "error", "duration_ms").
+		Values(delivery.OutboxID, delivery.WebhookID, delivery.Attempt, delivery.Timestamp,
+			delivery.StatusCode, delivery.Error, delivery.DurationMs).
+		ToSql(); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return
+	}
+
+	updMap := map[string]interface{}{
+		"attempts": delivery.Attempt,
+	}
+	switch {
+	case delivered:
+		updMap["delivered_at"] = delivery.Timestamp
+	case retryAt == nil:
+		updMap["failed_at"] = delivery.Timestamp
+	default:
+		updMap["next_attempt_at"] = *retryAt
+	}
+	if sqlStr, args, err = sq.Update(constant.DBWebhookOutbox).
+		SetMap(updMap).
+		Where(sqrl.Eq{"id": delivery.OutboxID}).
+		ToSql(); err != nil {
+		return
+	}
+	if _, err = tx.ExecContext(ctx, sqlStr, args...); err != nil {
+		return
+	}
+	err = tx.Commit()
+	return
+}
+
+func (r *WebhookRepo) WebhookDeliveries(ctx context.Context, webhookID domain.WebhookID, limit uint64) (deliveries []domain.WebhookDelivery, err error) {
+	var (
+		sqlStr string
+		args   []interface{}
+	)
+	if sqlStr, args, err = sq.Select("d.id", "d.outbox_id", "d.webhook_id", "o.event", "d.attempt",
+		"d.timestamp", "d.status_code", "d.error", "d.duration_ms").
+		From(constant.DBWebhookDelivery + " d").
+		InnerJoin(constant.DBWebhookOutbox + " o on o.id = d.outbox_id").
+		Where(sqrl.Eq{"d.webhook_id": webhookID}).
+		OrderBy("d.id desc").
+		Limit(limit).
+		ToSql(); err != nil {
+		return
+	}
+
+	err = r.db.SelectContext(ctx, &deliveries, sqlStr, args...)
+	if deliveries == nil {
+		deliveries = make([]domain.WebhookDelivery, 0)
+	}
+	return
+}
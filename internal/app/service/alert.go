@@ -0,0 +1,110 @@
+package service
+
+import (
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+func NewAlertService(r *repository.Repository) *AlertService {
+	return &AlertService{r: r, validate: validator.New()}
+}
+
+type AlertService struct {
+	r        *repository.Repository
+	validate *validator.Validate
+}
+
+func (s *AlertService) AlertRules(ctx context.Context) ([]domain.AlertRule, error) {
+	return s.r.Alert.AlertRules(ctx)
+}
+
+func (s *AlertService) AddAlertRule(ctx context.Context, rule *domain.AlertRule) (id domain.AlertRuleID, err error) {
+	if err = s.validate.Struct(rule); err != nil {
+		return
+	}
+	return s.r.Alert.AddAlertRule(ctx, rule)
+}
+
+func (s *AlertService) UpdateAlertRule(ctx context.Context, rule *domain.AlertRule) (err error) {
+	if err = s.validate.VarCtx(ctx, rule.ID, "required,gt=0"); err != nil {
+		return fmt.Errorf("field 'id' required%w", validator.ValidationErrors{})
+	}
+	if err = s.validate.Struct(rule); err != nil {
+		return
+	}
+	if err = s.r.Alert.UpdateAlertRule(ctx, rule); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+func (s *AlertService) DeleteAlertRules(ctx context.Context, ruleIDs ...domain.AlertRuleID) error {
+	return s.r.Alert.DeleteAlertRules(ctx, ruleIDs...)
+}
+
+// Alerts of vessels and zones in scope
+func (s *AlertService) Alerts(ctx context.Context, scope *domain.Scope, query domain.InputAlerts) ([]domain.Alert, error) {
+	return s.r.Alert.Alerts(ctx, scope, query)
+}
+
+func (s *AlertService) AcknowledgeAlerts(ctx context.Context, userID domain.UserID, alertIDs ...domain.AlertID) error {
+	return s.r.Alert.AcknowledgeAlerts(ctx, userID, alertIDs...)
+}
+
+// Evaluate raise alerts of vessel rules by zone change from prev to cur at point time.
+// Without prev zone (first point on monitoring) there is nothing to compare for enter/exit.
+func (s *AlertService) Evaluate(ctx context.Context, vessel domain.Vessel, prev, cur *domain.CurrentZone, timestamp time.Time) (err error) {
+	if cur == nil {
+		return
+	}
+	var rules []domain.AlertRule
+	if rules, err = s.r.Alert.VesselAlertRules(ctx, vessel.ID); err != nil || len(rules) == 0 {
+		return
+	}
+
+	entered, exited := domain.ZoneChanges(prev, cur)
+
+	var alerts []domain.Alert
+	newAlert := func(rule domain.AlertRule, zone domain.ZoneName, timeIn time.Time) domain.Alert {
+		return domain.Alert{
+			RuleID:     rule.ID,
+			Vessel:     vessel,
+			ZoneName:   zone,
+			Event:      rule.Event,
+			ZoneTimeIn: &timeIn,
+			Timestamp:  timestamp,
+		}
+	}
+	for _, rule := range rules {
+		switch rule.Event {
+		case domain.AlertEventEnter:
+			for _, zone := range entered {
+				if rule.MatchZone(zone) {
+					alerts = append(alerts, newAlert(rule, zone, cur.EnteredAt(zone)))
+				}
+			}
+		case domain.AlertEventExit:
+			for _, zone := range exited {
+				if rule.MatchZone(zone) {
+					alerts = append(alerts, newAlert(rule, zone, prev.EnteredAt(zone)))
+				}
+			}
+		case domain.AlertEventDwell:
+			for _, zone := range cur.Zones {
+				if !rule.MatchZone(zone) || timestamp.Sub(cur.EnteredAt(zone)) < rule.Dwell() {
+					continue
+				}
+				// one alert per stay: repeated are skipped by zone entry time
+				alerts = append(alerts, newAlert(rule, zone, cur.EnteredAt(zone)))
+			}
+		}
+	}
+	return s.r.Alert.AddAlerts(ctx, alerts...)
+}
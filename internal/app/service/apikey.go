@@ -0,0 +1,117 @@
+package service
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+func NewAPIKeyService(r *repository.Repository, roles Role) *APIKeyService {
+	return &APIKeyService{r: r, roles: roles, validate: validator.New()}
+}
+
+type APIKeyService struct {
+	r        *repository.Repository
+	roles    Role
+	validate *validator.Validate
+}
+
+func (s *APIKeyService) APIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.r.APIKey.APIKeys(ctx)
+}
+
+// AddAPIKey of user with role, key is returned only once. ErrNotExist if user does not exist or is deleted.
+// Role of key must be within roles of user, scope of key is limited by scope of user
+func (s *APIKeyService) AddAPIKey(ctx context.Context, key domain.APIKey) (secret domain.APIKeySecret, err error) {
+	if err = s.validate.StructCtx(ctx, &key); err != nil {
+		return
+	}
+	if err = s.validate.VarCtx(ctx, key.UserID, "required,gt=0"); err != nil {
+		return secret, fmt.Errorf("field 'userID' required%w", validator.ValidationErrors{})
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return secret, fmt.Errorf("expiresAt is in past%w", validator.ValidationErrors{})
+	}
+	if err = s.roles.CheckUserRole(ctx, key.Role); err != nil {
+		return
+	}
+	var user *domain.UserDB
+	if user, err = s.r.User.GetUserByID(ctx, key.UserID); errors.Is(err, sql.ErrNoRows) || err == nil && user.IsDeleted {
+		return secret, myErr.ErrNotExist
+	} else if err != nil {
+		return
+	}
+	if key.Role&^user.Role != 0 {
+		return secret, fmt.Errorf("field 'Role' must not exceed roles of user%w", validator.ValidationErrors{})
+	}
+	var userScope *domain.UserScope
+	if userScope, err = s.r.Scope.UserScope(ctx, key.UserID); errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	} else if err != nil {
+		return
+	}
+	if !key.UserScope.Within(userScope) {
+		return secret, fmt.Errorf("field 'zoneNames' has no zones of user%w", validator.ValidationErrors{})
+	}
+
+	if secret.Key, key.Prefix, key.Hash, err = domain.NewAPIKeySecret(constant.APIKeyLen); err != nil {
+		return
+	}
+	key.CreatedAt = now
+	if key.ID, err = s.r.APIKey.AddAPIKey(ctx, &key); err != nil {
+		return
+	}
+	secret.APIKey = key
+	return
+}
+
+// RotateAPIKey new key instead of the current, which is accepted for constant.APIKeyRotateGrace more.
+// ErrNotExist if key is revoked or does not exist
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id domain.APIKeyID) (secret domain.APIKeySecret, err error) {
+	var prefix, hash string
+	if secret.Key, prefix, hash, err = domain.NewAPIKeySecret(constant.APIKeyLen); err != nil {
+		return
+	}
+	var key *domain.APIKey
+	graceUntil := time.Now().Add(constant.APIKeyRotateGrace * time.Second)
+	if key, err = s.r.APIKey.RotateAPIKey(ctx, id, prefix, hash, graceUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = myErr.ErrNotExist
+		}
+		return
+	}
+	secret.APIKey = *key
+	return
+}
+
+// RevokeAPIKeys keys are rejected from now on, previous keys of rotation too. ErrNotExist if no key was active
+func (s *APIKeyService) RevokeAPIKeys(ctx context.Context, ids ...domain.APIKeyID) (err error) {
+	var revoked []domain.APIKeyID
+	if revoked, err = s.r.APIKey.RevokeAPIKeys(ctx, ids...); err == nil && len(revoked) == 0 {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+// Authenticate active key, ErrAPIKey if key is unknown, expired or revoked
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (apiKey *domain.APIKey, err error) {
+	now := time.Now()
+	if apiKey, err = s.r.APIKey.APIKeyByHash(ctx, domain.HashAPIKey(key), now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = myErr.ErrAPIKey
+		}
+		return nil, err
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > constant.APIKeyTouchInterval*time.Second {
+		err = s.r.APIKey.TouchAPIKey(ctx, apiKey.ID, now)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+func NewAuditService(r *repository.Repository) *AuditService {
+	return &AuditService{r: r, validate: validator.New()}
+}
+
+type AuditService struct {
+	r        *repository.Repository
+	validate *validator.Validate
+}
+
+// AddAudit of call which changed no rows, changes are recorded by repository with the change itself
+func (s *AuditService) AddAudit(ctx context.Context, req *domain.AuditRequest) error {
+	return s.r.Audit.AddAudit(ctx, req)
+}
+
+func (s *AuditService) Audit(ctx context.Context, q domain.InputAudit) (page domain.AuditPage, err error) {
+	if err = s.validate.StructCtx(ctx, &q); err != nil {
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = constant.AuditListLimit
+	}
+	var cursor *domain.AuditCursor
+	if q.Cursor != "" {
+		cursor = new(domain.AuditCursor)
+		if er := cursor.FromString(q.Cursor); er != nil {
+			return page, fmt.Errorf("wrong cursor%w", validator.ValidationErrors{})
+		}
+	}
+	if page.Records, err = s.r.Audit.Audit(ctx, q, cursor, q.Limit+1); err != nil {
+		return
+	}
+	if uint64(len(page.Records)) > q.Limit {
+		page.Records = page.Records[:q.Limit]
+		page.NextCursor = (&domain.AuditCursor{ID: page.Records[q.Limit-1].ID}).String()
+	}
+	return
+}
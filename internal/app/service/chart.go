@@ -1,6 +1,7 @@
 package service
 
 import (
+	"charts_analyser/internal/app/config"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	myErr "charts_analyser/internal/app/error"
@@ -12,23 +13,45 @@ import (
 	"time"
 )
 
-func NewChartService(r *repository.Repository) *ChartService {
-	return &ChartService{r: r}
+func NewChartService(r *repository.Repository, conf *config.Contact, stream Stream, alert Alert) *ChartService {
+	return &ChartService{r: r, conf: conf, stream: stream, alert: alert}
 }
 
 type ChartService struct {
-	r *repository.Repository
+	r      *repository.Repository
+	conf   *config.Contact
+	stream Stream
+	alert  Alert
 }
 
-func (s *ChartService) Zones(ctx context.Context, query domain.InputVesselsInterval) (zones []domain.ZoneName, err error) {
-	return s.r.Chart.Zones(ctx, query)
+// Zones in scope crossed by vessels in scope
+func (s *ChartService) Zones(ctx context.Context, scope *domain.Scope, query domain.InputVesselsInterval) (zones []domain.ZoneName, err error) {
+	if query.VesselIDs = scope.Vessels(query.VesselIDs); len(query.VesselIDs) == 0 {
+		return []domain.ZoneName{}, nil
+	}
+	if zones, err = s.r.Chart.Zones(ctx, query); err != nil {
+		return
+	}
+	return scope.Zones(zones), nil
+}
+
+// Vessels in scope crossed zones in scope
+func (s *ChartService) Vessels(ctx context.Context, scope *domain.Scope, query domain.InputZones) (vesselIDs []domain.VesselID, err error) {
+	if query.ZoneNames = scope.Zones(query.ZoneNames); len(query.ZoneNames) == 0 {
+		return []domain.VesselID{}, nil
+	}
+	if vesselIDs, err = s.r.Chart.Vessels(ctx, query); err != nil {
+		return
+	}
+	return scope.Vessels(vesselIDs), nil
 }
 
-func (s *ChartService) Vessels(ctx context.Context, query domain.InputZones) (vesselIDs []domain.VesselID, err error) {
-	return s.r.Chart.Vessels(ctx, query)
+func (s *ChartService) Track(ctx context.Context, vesselID domain.VesselID, loc domain.InputPoint) error {
+	return s.TrackAt(ctx, vesselID, loc, time.Now())
 }
 
-func (s *ChartService) Track(ctx context.Context, vesselID domain.VesselID, loc domain.InputPoint) (err error) {
+// TrackAt record point of vessel at timestamp, monitoring state follows point time
+func (s *ChartService) TrackAt(ctx context.Context, vesselID domain.VesselID, loc domain.InputPoint, timestamp time.Time) (err error) {
 	var (
 		track   = new(domain.Track)
 		vessels domain.Vessels
@@ -38,6 +61,10 @@ func (s *ChartService) Track(ctx context.Context, vesselID domain.VesselID, loc
 		err = myErr.ErrLocationOutOfRange
 		return
 	}
+	if timestamp.After(time.Now().Add(constant.TrackClockSkew)) {
+		err = myErr.ErrTimestampInFuture
+		return
+	}
 	track.Location = domain.Point(loc)
 	vessels, err = s.r.GetVessels(ctx, vesselID)
 	if errors.Is(err, sql.ErrNoRows) || len(vessels) == 0 {
@@ -47,9 +74,7 @@ func (s *ChartService) Track(ctx context.Context, vesselID domain.VesselID, loc
 		return
 	}
 	track.Vessel = *vessels[0]
-	if track.Timestamp.IsZero() {
-		track.Timestamp = time.Now()
-	}
+	track.Timestamp = timestamp
 	// may be set in bg ?
 	//go func(ctx context.Context) {
 	if er := s.MaybeUpdateState(ctx, vesselID, track); er != nil {
@@ -72,26 +97,73 @@ func (s *ChartService) MaybeUpdateState(ctx context.Context, vesselID domain.Ves
 		return
 	}
 	state = states[0]
+	// replayed point older than current state stays in track history only
+	if state.Timestamp != nil && track.Timestamp.Before(*state.Timestamp) {
+		return
+	}
 	state.Location = &track.Location
 	state.Vessel = track.Vessel
 	state.Timestamp = &track.Timestamp
+	// contact by point time as watchdog does: buffered point restores contact only if it is recent enough
+	since := track.Timestamp
+	if state.ControlStart != nil && state.ControlStart.After(since) {
+		since = *state.ControlStart
+	}
+	staleSince, lostSince := s.conf.Since(time.Now())
+	state.Contact = domain.ContactOf(since, staleSince, lostSince)
 	var newZones []domain.ZoneName
 	newZones, err = s.r.Chart.ZonesByLocation(ctx, track.Location)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
 	}
-	if state.CurrentZone == nil || len(sliceutils.Difference(state.CurrentZone.Zones, newZones)) > 0 {
-		state.CurrentZone = &domain.CurrentZone{
-			Zones:  newZones,
-			TimeIn: time.Now(),
-		}
+	prevZone := state.CurrentZone
+	zoneChanged := state.CurrentZone == nil || len(sliceutils.Difference(state.CurrentZone.Zones, newZones)) > 0
+	if zoneChanged {
+		state.CurrentZone = domain.NewCurrentZone(prevZone, newZones, track.Timestamp)
+	}
+	// zone changes are enqueued to webhooks with the state
+	payloads := zoneChangePayloads(state.Vessel, prevZone, state.CurrentZone, track)
+	if er := s.r.Monitor.UpdateState(ctx, vesselID, state, payloads...); er != nil {
+		err = errors.Join(err, er)
+		return
 	}
-	if er := s.r.Monitor.UpdateState(ctx, vesselID, state); err != nil {
+	s.stream.Publish(*state, zoneChanged)
+	if er := s.alert.Evaluate(ctx, state.Vessel, prevZone, state.CurrentZone, track.Timestamp); er != nil {
 		err = errors.Join(err, er)
 	}
 	return
 }
 
-func (s *ChartService) GetTrack(ctx context.Context, query domain.InputVesselsInterval) (tracks []domain.Track, err error) {
-	return s.r.Chart.GetTrack(ctx, query)
+// zoneChangePayloads webhook payloads of zones entered and exited by track point
+func zoneChangePayloads(vessel domain.Vessel, prev, cur *domain.CurrentZone, track *domain.Track) []domain.WebhookPayload {
+	entered, exited := domain.ZoneChanges(prev, cur)
+	payloads := make([]domain.WebhookPayload, 0, len(entered)+len(exited))
+	for _, events := range []struct {
+		event domain.WebhookEvent
+		zones []domain.ZoneName
+	}{{domain.WebhookEventZoneEnter, entered}, {domain.WebhookEventZoneExit, exited}} {
+		for _, zone := range events.zones {
+			zone := zone
+			payloads = append(payloads, domain.WebhookPayload{
+				Event:     events.event,
+				Timestamp: track.Timestamp,
+				Vessel:    vessel,
+				Zone:      &zone,
+				Location:  &track.Location,
+			})
+		}
+	}
+	return payloads
+}
+
+// GetTrack of vessels in scope, only points in scope zones. ErrNotExist if no vessel is in scope
+func (s *ChartService) GetTrack(ctx context.Context, scope *domain.Scope, query domain.InputVesselsInterval) (tracks []domain.Track, err error) {
+	if query.VesselIDs = scope.Vessels(query.VesselIDs); len(query.VesselIDs) == 0 {
+		return nil, myErr.ErrNotExist
+	}
+	var zones domain.ZoneNames
+	if scope != nil {
+		zones = scope.ZoneNames
+	}
+	return s.r.Chart.GetTrack(ctx, query, zones)
 }
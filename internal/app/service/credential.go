@@ -0,0 +1,75 @@
+package service
+
+import (
+	"charts_analyser/internal/app/config"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+func NewCredentialService(r *repository.Repository, conf *config.JWT) *CredentialService {
+	return &CredentialService{r: r, conf: conf, validate: validator.New()}
+}
+
+type CredentialService struct {
+	r        *repository.Repository
+	conf     *config.JWT
+	validate *validator.Validate
+}
+
+// IssueVesselToken signs vessel token with new jti and saves the credential
+func (s *CredentialService) IssueVesselToken(ctx context.Context, credential domain.VesselCredential) (token domain.VesselToken, err error) {
+	if err = s.validate.StructCtx(ctx, &credential); err != nil {
+		return
+	}
+	var vessels domain.Vessels
+	if vessels, err = s.r.GetVessels(ctx, credential.VesselID); err != nil {
+		return
+	}
+	if len(vessels) == 0 {
+		return token, myErr.ErrNotExist
+	}
+
+	jti := make([]byte, constant.TokenJTILen)
+	if _, err = rand.Read(jti); err != nil {
+		return
+	}
+	claims := domain.NewClaimVessels(s.conf, vessels[0].ID, vessels[0].Name).WithID(hex.EncodeToString(jti))
+	if token.Token, err = claims.Token(); err != nil {
+		return
+	}
+
+	credential.JTI = claims.ID
+	credential.VesselName = vessels[0].Name
+	credential.IssuedAt = time.Now()
+	credential.ExpiresAt = claims.ExpiresAt.Time
+	if err = s.r.Credential.AddVesselCredential(ctx, &credential); err != nil {
+		return
+	}
+	token.VesselCredential = credential
+	return
+}
+
+func (s *CredentialService) VesselCredentials(ctx context.Context, vesselIDs ...domain.VesselID) ([]domain.VesselCredential, error) {
+	return s.r.Credential.VesselCredentials(ctx, vesselIDs...)
+}
+
+// RevokeVesselCredentials by user, tokens are rejected from now on. ErrNotExist if no credential was active
+func (s *CredentialService) RevokeVesselCredentials(ctx context.Context, userID domain.UserID, jtis ...string) (err error) {
+	var revoked []string
+	if revoked, err = s.r.Credential.RevokeVesselCredentials(ctx, userID, jtis...); err == nil && len(revoked) == 0 {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+// IsCredentialActive token with jti is issued, not revoked and not expired
+func (s *CredentialService) IsCredentialActive(ctx context.Context, jti string) (bool, error) {
+	return s.r.Credential.IsCredentialActive(ctx, jti)
+}
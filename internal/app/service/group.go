@@ -0,0 +1,77 @@
+package service
+
+import (
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+func NewVesselGroupService(r *repository.Repository) *VesselGroupService {
+	return &VesselGroupService{r: r, validate: validator.New()}
+}
+
+type VesselGroupService struct {
+	r        *repository.Repository
+	validate *validator.Validate
+}
+
+func (s *VesselGroupService) VesselGroups(ctx context.Context) ([]domain.VesselGroup, error) {
+	return s.r.VesselGroup.VesselGroups(ctx)
+}
+
+func (s *VesselGroupService) AddVesselGroup(ctx context.Context, group *domain.VesselGroup) (id domain.VesselGroupID, err error) {
+	if err = s.validate.Struct(group); err != nil {
+		return
+	}
+	if id, err = s.r.VesselGroup.AddVesselGroup(ctx, group); isUniqueViolation(err) {
+		err = myErr.ErrDuplicateRecord
+	}
+	return
+}
+
+func (s *VesselGroupService) UpdateVesselGroup(ctx context.Context, group *domain.VesselGroup) (err error) {
+	if err = s.validate.VarCtx(ctx, group.ID, "required,gt=0"); err != nil {
+		return fmt.Errorf("field 'id' required%w", validator.ValidationErrors{})
+	}
+	if err = s.validate.Struct(group); err != nil {
+		return
+	}
+	err = s.r.VesselGroup.UpdateVesselGroup(ctx, group)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	} else if isUniqueViolation(err) {
+		err = myErr.ErrDuplicateRecord
+	}
+	return
+}
+
+func (s *VesselGroupService) DeleteVesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) error {
+	return s.r.VesselGroup.DeleteVesselGroups(ctx, groupIDs...)
+}
+
+// ExpandGroups adds current vessels of in.GroupIDs to in.VesselIDs, ErrNotExist if some group does not exist
+func (s *VesselGroupService) ExpandGroups(ctx context.Context, in *domain.InputVessels) (err error) {
+	if len(in.GroupIDs) == 0 {
+		return
+	}
+	var groups []domain.VesselGroup
+	if groups, err = s.r.VesselGroup.VesselGroups(ctx, in.GroupIDs...); err != nil {
+		return
+	}
+	found := make(map[domain.VesselGroupID]struct{}, len(groups))
+	for _, group := range groups {
+		found[group.ID] = struct{}{}
+		in.AddVessels(group.VesselIDs...)
+	}
+	for _, id := range in.GroupIDs {
+		if _, ok := found[id]; !ok {
+			return myErr.ErrNotExist
+		}
+	}
+	return
+}
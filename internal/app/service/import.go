@@ -0,0 +1,97 @@
+package service
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ImportVessels from csv with header, columns id (optional) and name, other columns are ignored.
+// Invalid rows are reported and skipped, nothing is saved on dry run
+func (s *VesselService) ImportVessels(ctx context.Context, file io.Reader, dryRun bool) (result *domain.VesselImport, err error) {
+	var rows []domain.VesselImportRow
+	if rows, err = readVesselImport(file); err != nil {
+		return
+	}
+	if err = s.r.ImportVessels(ctx, dryRun, rows); err != nil {
+		return
+	}
+	result = &domain.VesselImport{DryRun: dryRun, Rows: rows}
+	result.Count()
+	return
+}
+
+func readVesselImport(file io.Reader) (rows []domain.VesselImportRow, err error) {
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var header []string
+	if header, err = r.Read(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty file%w", validator.ValidationErrors{})
+		}
+		return nil, fmt.Errorf("header: %s%w", err.Error(), validator.ValidationErrors{})
+	}
+	idCol, nameCol := -1, -1
+	for i, col := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))) {
+		case "id":
+			idCol = i
+		case "name":
+			nameCol = i
+		}
+	}
+	if nameCol < 0 {
+		return nil, fmt.Errorf("column 'name' required%w", validator.ValidationErrors{})
+	}
+
+	rows = make([]domain.VesselImportRow, 0)
+	for {
+		record, er := r.Read()
+		if errors.Is(er, io.EOF) {
+			break
+		}
+		if len(rows) == constant.VesselImportLimit {
+			return nil, fmt.Errorf("at most %d rows%w", constant.VesselImportLimit, validator.ValidationErrors{})
+		}
+		if er != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(er, &parseErr) {
+				return nil, er
+			}
+			rows = append(rows, domain.VesselImportRow{Line: parseErr.Line, Status: domain.VesselImportInvalid, Error: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, vesselImportRow(domain.VesselImportRow{Line: line}, record, idCol, nameCol))
+	}
+	return
+}
+
+func vesselImportRow(row domain.VesselImportRow, record []string, idCol, nameCol int) domain.VesselImportRow {
+	if nameCol < len(record) {
+		row.Name = domain.VesselName(strings.TrimSpace(record[nameCol]))
+	}
+	if idCol >= 0 && idCol < len(record) && strings.TrimSpace(record[idCol]) != "" {
+		row.ID = new(domain.VesselID)
+		if err := row.ID.SetFromStr(strings.TrimSpace(record[idCol])); err != nil || *row.ID <= 0 {
+			row.Status, row.Error = domain.VesselImportInvalid, "id must be positive integer"
+			return row
+		}
+	}
+	switch {
+	case row.Name == "":
+		row.Status, row.Error = domain.VesselImportInvalid, "name required"
+	case utf8.RuneCountInString(string(row.Name)) > constant.VesselNameMaxLen:
+		row.Status, row.Error = domain.VesselImportInvalid, fmt.Sprintf("name must be at most %d characters", constant.VesselNameMaxLen)
+	}
+	return row
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"charts_analyser/internal/app/config"
 	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	myErr "charts_analyser/internal/app/error"
@@ -8,57 +9,184 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"time"
 )
 
-func NewMonitorService(r *repository.Repository, log *zap.Logger) *MonitorService {
-	return &MonitorService{r: r, log: log}
+func NewMonitorService(r *repository.Repository, conf *config.Contact, log *zap.Logger, stream Stream) *MonitorService {
+	return &MonitorService{r: r, conf: conf, log: log, stream: stream}
 }
 
 type MonitorService struct {
-	r   *repository.Repository
-	log *zap.Logger
+	r      *repository.Repository
+	conf   *config.Contact
+	log    *zap.Logger
+	stream Stream
 }
 
-func (s *MonitorService) SetControl(ctx context.Context, status bool, vesselIDs ...domain.VesselID) (err error) {
+// SetControl add (remove) vessels to watchlist. Vessel is monitored while any watchlist has it,
+// action on every vessel is logged, change of monitoring is notified in the same transaction
+func (s *MonitorService) SetControl(ctx context.Context, action domain.ControlAction, status bool, vesselIDs ...domain.VesselID) (err error) {
 	var vessels domain.Vessels
-	if vessels, err = s.r.Vessels.GetVessels(ctx, vesselIDs...); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			err = myErr.ErrNotExist
-		}
+	if vessels, err = s.controlVessels(ctx, action, vesselIDs...); err != nil {
 		return
 	}
-	if len(vessels) == 0 {
+	_, err = s.r.Monitor.SetControl(ctx, action, status, vessels)
+	return
+}
+
+// controlVessels existing vessels for action, ErrNotExist if there are none
+func (s *MonitorService) controlVessels(ctx context.Context, action domain.ControlAction, vesselIDs ...domain.VesselID) (vessels domain.Vessels, err error) {
+	if action.Comment != nil && len(*action.Comment) > constant.ControlCommentMaxLen {
+		return nil, fmt.Errorf("field 'comment' must be at most %d characters%w", constant.ControlCommentMaxLen, validator.ValidationErrors{})
+	}
+	if vessels, err = s.r.Vessels.GetVessels(ctx, vesselIDs...); errors.Is(err, sql.ErrNoRows) || err == nil && len(vessels) == 0 {
 		err = myErr.ErrNotExist
+	}
+	return
+}
+
+// ScheduleControl set vessels on monitoring: items without window or with passed start - now,
+// windows with future start or with end are saved for scheduler in the same transaction.
+// Vessel of window is removed from watchlist at its end only if the window added it
+func (s *MonitorService) ScheduleControl(ctx context.Context, action domain.ControlAction, items ...domain.ControlItem) (err error) {
+	var (
+		now       = time.Now()
+		vesselIDs = make([]domain.VesselID, 0, len(items))
+		windows   []domain.ControlWindow
+	)
+	for _, item := range items {
+		if item.End != nil && !item.End.After(now) {
+			return fmt.Errorf("vessel %d: field 'end' must be in future%w", item.VesselID, validator.ValidationErrors{})
+		}
+		if item.Start != nil && item.End != nil && !item.End.After(*item.Start) {
+			return fmt.Errorf("vessel %d: field 'end' must be after 'start'%w", item.VesselID, validator.ValidationErrors{})
+		}
+		vesselIDs = append(vesselIDs, item.VesselID)
+	}
+	var vessels domain.Vessels
+	if vessels, err = s.controlVessels(ctx, action, vesselIDs...); err != nil {
 		return
 	}
-	err = s.r.Monitor.SetControl(ctx, status, []*domain.Vessel(vessels)...)
-	if err != nil {
+	exist := make(map[domain.VesselID]*domain.Vessel, len(vessels))
+	for _, v := range vessels {
+		exist[v.ID] = v
+	}
+
+	var manual, immediate domain.Vessels
+	for _, item := range items {
+		vessel, ok := exist[item.VesselID]
+		if !ok {
+			continue
+		}
+		started := item.Start == nil || !item.Start.After(now)
+		if started {
+			immediate = append(immediate, vessel)
+		}
+		if item.End == nil && started {
+			manual = append(manual, vessel)
+			continue
+		}
+		window := domain.ControlWindow{
+			WatchlistID: action.WatchlistID,
+			Vessel:      domain.Vessel{ID: item.VesselID},
+			Start:       item.Start,
+			End:         item.End,
+			UserID:      action.UserID,
+			Comment:     action.Comment,
+		}
+		if started {
+			window.StartedAt = &now
+		}
+		windows = append(windows, window)
+	}
+	_, err = s.r.Monitor.ScheduleControl(ctx, action, manual, immediate, windows...)
+	return
+}
+
+// CancelControl remove vessels from watchlist now, scheduled windows of vessels in watchlist are canceled
+func (s *MonitorService) CancelControl(ctx context.Context, action domain.ControlAction, vesselIDs ...domain.VesselID) (err error) {
+	var vessels domain.Vessels
+	if vessels, err = s.controlVessels(ctx, action, vesselIDs...); err != nil {
 		return
 	}
+	_, err = s.r.Monitor.CancelControl(ctx, action, vessels)
+	return
+}
+
+func (s *MonitorService) ControlWindows(ctx context.Context, userID domain.UserID) (windows []domain.ControlWindow, err error) {
+	if windows, err = s.r.Monitor.ControlWindows(ctx, userID); windows == nil {
+		windows = []domain.ControlWindow{}
+	}
+	return
+}
 
-	/* log to postgres */
-	go func(vessels domain.Vessels, status bool) {
-		ctx, cancel := context.WithTimeout(context.Background(), constant.ServerOperationTimeout)
-		defer cancel()
-		var cLogs []domain.ControlLog
-		for _, v := range vessels {
-			cLogs = append(cLogs, domain.ControlLog{
-				Vessel:    v,
-				Timestamp: time.Now(),
-				Control:   status,
-				Comment:   nil,
-			})
+// ApplyControlSchedule set on and off monitoring by due windows, every window is claimed and applied
+// in own transaction, so failed one is retried next time
+func (s *MonitorService) ApplyControlSchedule(ctx context.Context) (err error) {
+	now := time.Now()
+	var windows []domain.ControlWindow
+	if windows, err = s.r.Monitor.DueControlWindows(ctx, now); err != nil || len(windows) == 0 {
+		return
+	}
+	ids := make([]domain.VesselID, 0, len(windows))
+	for _, w := range windows {
+		ids = append(ids, w.Vessel.ID)
+	}
+	var vessels domain.Vessels
+	if vessels, err = s.r.Vessels.GetVessels(ctx, ids...); err != nil {
+		return
+	}
+	byID := make(map[domain.VesselID]*domain.Vessel, len(vessels))
+	for _, v := range vessels {
+		byID[v.ID] = v
+	}
+	for _, w := range windows {
+		var vessel domain.Vessels
+		if v, ok := byID[w.Vessel.ID]; ok {
+			vessel = domain.Vessels{v}
 		}
-		if err := s.r.ControlLogAdd(ctx, cLogs...); err != nil {
-			s.log.Error("Background ControlLogAdd", zap.Error(err))
+		if w.StartedAt == nil {
+			if er := s.applyControlWindow(ctx, &w, true, vessel, now); er != nil {
+				err = errors.Join(err, er)
+				continue
+			}
 		}
-	}(vessels, status)
+		if w.End != nil && !w.End.After(now) {
+			if er := s.applyControlWindow(ctx, &w, false, vessel, now); er != nil {
+				err = errors.Join(err, er)
+			}
+		}
+	}
+	return
+}
 
+// applyControlWindow start (end) window, window taken by other instance or canceled meanwhile is skipped
+func (s *MonitorService) applyControlWindow(ctx context.Context, w *domain.ControlWindow, status bool, vessels domain.Vessels, now time.Time) (err error) {
+	if _, err = s.r.Monitor.ApplyControlWindow(ctx, w, status, vessels, now); errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
 	return
 }
 
+// RunScheduler apply monitoring windows until ctx is done
+func (s *MonitorService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(constant.ControlScheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ApplyControlSchedule(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				s.log.Error("Control scheduler", zap.Error(err))
+			}
+		}
+	}
+}
+
 func (s *MonitorService) GetStates(ctx context.Context, vesselIDs ...domain.VesselID) (states []*domain.VesselState, err error) {
 	states, err = s.r.Monitor.GetStates(ctx, vesselIDs...)
 	if len(states) == 0 {
@@ -68,6 +196,51 @@ func (s *MonitorService) GetStates(ctx context.Context, vesselIDs ...domain.Vess
 	return
 }
 
-func (s *MonitorService) MonitoredVessels(ctx context.Context) (vessels domain.Vessels, err error) {
-	return s.r.Monitor.MonitoredVessels(ctx)
+// ControlLog of vessels in scope, in its zones now if restricted by zones
+func (s *MonitorService) ControlLog(ctx context.Context, scope *domain.Scope, q domain.InputControlLog) ([]domain.ControlLog, error) {
+	return s.r.ControlLogs(ctx, scope, q)
+}
+
+// MonitoredVessels of user lists in scope, in its zones now if restricted by zones
+func (s *MonitorService) MonitoredVessels(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) (vessels []domain.MonitoredVessel, err error) {
+	if vessels, err = s.r.Monitor.MonitoredVessels(ctx, scope, userID, watchlistID); vessels == nil {
+		vessels = []domain.MonitoredVessel{}
+	}
+	return
+}
+
+// MonitorSummary of monitoring board for watchlist or, if not set, for all lists of user, in scope
+func (s *MonitorService) MonitorSummary(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) (domain.MonitorSummary, error) {
+	return s.r.Monitor.MonitorSummary(ctx, scope, userID, watchlistID, constant.SummaryDwellTop)
+}
+
+// CheckContact mark monitored vessels silent longer than thresholds as stale or lost,
+// changed states are published to stream, lost ones are enqueued to webhooks with the change
+func (s *MonitorService) CheckContact(ctx context.Context) (err error) {
+	now := time.Now()
+	staleSince, lostSince := s.conf.Since(now)
+	var states []*domain.VesselState
+	if states, err = s.r.Monitor.CheckContact(ctx, staleSince, lostSince, now); err != nil {
+		return
+	}
+	for _, state := range states {
+		s.stream.Publish(*state, false)
+	}
+	return
+}
+
+// RunWatchdog check contact of monitored vessels until ctx is done
+func (s *MonitorService) RunWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(constant.ContactCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckContact(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				s.log.Error("Contact watchdog", zap.Error(err))
+			}
+		}
+	}
 }
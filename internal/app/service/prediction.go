@@ -0,0 +1,36 @@
+package service
+
+import (
+	"charts_analyser/internal/app/config"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+func NewPredictionService(r *repository.Repository, conf *config.Prediction) *PredictionService {
+	return &PredictionService{r: r, conf: conf}
+}
+
+type PredictionService struct {
+	r    *repository.Repository
+	conf *config.Prediction
+}
+
+// ZoneEntries predicted for monitored vessels of watchlist or, if not set, of all lists of user, into zones in scope,
+// within horizon, sec (PREDICTION_HORIZON if empty)
+func (s *PredictionService) ZoneEntries(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, horizon uint64) (entries []domain.ZoneEntry, err error) {
+	if horizon == 0 {
+		horizon = s.conf.PredictionHorizon
+	}
+	if horizon > constant.PredictionHorizonMax {
+		return nil, fmt.Errorf("field 'horizon' must be at most %d sec%w", constant.PredictionHorizonMax, validator.ValidationErrors{})
+	}
+	if entries, err = s.r.Monitor.ZoneEntries(ctx, scope, userID, watchlistID, time.Duration(horizon)*time.Second); entries == nil {
+		entries = []domain.ZoneEntry{}
+	}
+	return
+}
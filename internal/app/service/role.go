@@ -0,0 +1,143 @@
+package service
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"slices"
+	"sync"
+	"time"
+)
+
+func NewRoleService(r *repository.Repository, log *zap.Logger) *RoleService {
+	validate := validator.New()
+
+	err := validate.RegisterValidation("permission", func(fl validator.FieldLevel) bool {
+		return slices.Contains(constant.Permissions, constant.Permission(fl.Field().String()))
+	})
+	if err != nil {
+		log.Error("RegisterValidation", zap.Error(err))
+	}
+
+	return &RoleService{r: r, validate: validate}
+}
+
+// RoleService roles with permissions, permissions are cached for constant.RoleCacheTTL
+type RoleService struct {
+	r        *repository.Repository
+	validate *validator.Validate
+
+	mu       sync.RWMutex
+	perms    domain.RolePermissions
+	loadedAt time.Time
+}
+
+func (s *RoleService) Roles(ctx context.Context) ([]domain.Role, error) {
+	return s.r.Role.Roles(ctx)
+}
+
+// AddRole with the lowest free bit of role mask
+func (s *RoleService) AddRole(ctx context.Context, role *domain.Role) (id constant.Role, err error) {
+	if err = s.validate.Struct(role); err != nil {
+		return
+	}
+	var roles []domain.Role
+	if roles, err = s.r.Role.Roles(ctx); err != nil {
+		return
+	}
+	if len(roles) >= constant.RoleMaxBits {
+		return 0, fmt.Errorf("roles limit %d reached%w", constant.RoleMaxBits, validator.ValidationErrors{})
+	}
+	if id, err = s.r.Role.AddRole(ctx, role); isUniqueViolation(err) {
+		err = myErr.ErrDuplicateRecord
+	}
+	s.invalidate()
+	return
+}
+
+// UpdateRole name and permissions, admin role keeps role:write not to lock out role management
+func (s *RoleService) UpdateRole(ctx context.Context, role *domain.Role) (err error) {
+	if err = s.validate.VarCtx(ctx, role.ID, "required,gt=0"); err != nil {
+		return fmt.Errorf("field 'id' required%w", validator.ValidationErrors{})
+	}
+	if err = s.validate.Struct(role); err != nil {
+		return
+	}
+	if role.ID == constant.RoleAdmin && !slices.Contains(role.Permissions, constant.PermRoleWrite) {
+		return fmt.Errorf("admin role must have '%s'%w", constant.PermRoleWrite, validator.ValidationErrors{})
+	}
+	err = s.r.Role.UpdateRole(ctx, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	} else if isUniqueViolation(err) {
+		err = myErr.ErrDuplicateRecord
+	}
+	s.invalidate()
+	return
+}
+
+// DeleteRoles ErrRoleInUse if some role is builtin or assigned to user or not revoked api key
+func (s *RoleService) DeleteRoles(ctx context.Context, roleIDs ...constant.Role) (err error) {
+	var inUse bool
+	if inUse, err = s.r.Role.DeleteRoles(ctx, roleIDs...); err == nil && inUse {
+		err = myErr.ErrRoleInUse
+	}
+	s.invalidate()
+	return
+}
+
+// Can any role of mask grant permission
+func (s *RoleService) Can(ctx context.Context, mask constant.Role, perm constant.Permission) (bool, error) {
+	perms, err := s.permissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	return perms.Can(mask, perm), nil
+}
+
+// CheckUserRole validation error if mask has vessel role or unknown role bits
+func (s *RoleService) CheckUserRole(ctx context.Context, mask constant.Role) error {
+	if mask.CheckIsRole(constant.RoleVessel) {
+		return fmt.Errorf("field 'Role' must not include vessel role%w", validator.ValidationErrors{})
+	}
+	perms, err := s.permissions(ctx)
+	if err != nil {
+		return err
+	}
+	if !perms.IsKnown(mask) {
+		return fmt.Errorf("field 'Role' has unknown roles%w", validator.ValidationErrors{})
+	}
+	return nil
+}
+
+func (s *RoleService) permissions(ctx context.Context) (domain.RolePermissions, error) {
+	s.mu.RLock()
+	perms, loadedAt := s.perms, s.loadedAt
+	s.mu.RUnlock()
+	if perms != nil && time.Since(loadedAt) < constant.RoleCacheTTL {
+		return perms, nil
+	}
+
+	roles, err := s.r.Role.Roles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	perms = domain.NewRolePermissions(roles)
+	s.mu.Lock()
+	s.perms, s.loadedAt = perms, time.Now()
+	s.mu.Unlock()
+	return perms, nil
+}
+
+func (s *RoleService) invalidate() {
+	s.mu.Lock()
+	s.perms = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-playground/validator/v10"
+)
+
+func NewScopeService(r *repository.Repository) *ScopeService {
+	return &ScopeService{r: r, validate: validator.New()}
+}
+
+type ScopeService struct {
+	r        *repository.Repository
+	validate *validator.Validate
+}
+
+// UserScope restriction of user, empty if not restricted. ErrNotExist if user does not exist
+func (s *ScopeService) UserScope(ctx context.Context, userID domain.UserID) (scope *domain.UserScope, err error) {
+	if _, err = s.r.User.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = myErr.ErrNotExist
+		}
+		return
+	}
+	if scope, err = s.r.Scope.UserScope(ctx, userID); errors.Is(err, sql.ErrNoRows) {
+		scope = &domain.UserScope{UserID: userID, ZoneNames: domain.ZoneNames{}, VesselIDs: domain.VesselIDs{}, GroupIDs: domain.VesselGroupIDs{}}
+		err = nil
+	}
+	return
+}
+
+// SetUserScope replaces restriction of user, empty scope removes it. ErrNotExist if user does not exist
+func (s *ScopeService) SetUserScope(ctx context.Context, scope *domain.UserScope) (err error) {
+	if err = s.validate.StructCtx(ctx, scope); err != nil {
+		return
+	}
+	if _, err = s.r.User.GetUserByID(ctx, scope.UserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = myErr.ErrNotExist
+		}
+		return
+	}
+	return s.r.Scope.SetUserScope(ctx, scope)
+}
+
+// Scope of user with current vessels of his groups, nil if user is not restricted
+func (s *ScopeService) Scope(ctx context.Context, userID domain.UserID) (scope *domain.Scope, err error) {
+	var userScope *domain.UserScope
+	if userScope, err = s.r.Scope.UserScope(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+		return
+	}
+	return s.ScopeOf(ctx, userScope)
+}
+
+// APIKeyScope restriction of key within current restriction of its user
+func (s *ScopeService) APIKeyScope(ctx context.Context, key *domain.APIKey) (scope *domain.Scope, err error) {
+	var keyScope, userScope *domain.Scope
+	if keyScope, err = s.ScopeOf(ctx, &key.UserScope); err != nil {
+		return
+	}
+	if userScope, err = s.Scope(ctx, key.UserID); err != nil {
+		return
+	}
+	return keyScope.Intersect(userScope), nil
+}
+
+// ScopeOf restriction with current vessels of its groups, nil if restriction is empty.
+// Deleted group gives no vessels, so user restricted by it sees less, not more
+func (s *ScopeService) ScopeOf(ctx context.Context, userScope *domain.UserScope) (scope *domain.Scope, err error) {
+	if userScope.IsEmpty() {
+		return
+	}
+	scope = new(domain.Scope)
+	if len(userScope.ZoneNames) > 0 {
+		scope.ZoneNames = userScope.ZoneNames
+	}
+	if len(userScope.VesselIDs) > 0 || len(userScope.GroupIDs) > 0 {
+		fleet := domain.InputVessels{VesselIDs: append(domain.VesselIDs{}, userScope.VesselIDs...)}
+		if len(userScope.GroupIDs) > 0 {
+			var groups []domain.VesselGroup
+			if groups, err = s.r.VesselGroup.VesselGroups(ctx, userScope.GroupIDs...); err != nil {
+				return nil, err
+			}
+			for _, group := range groups {
+				fleet.AddVessels(group.VesselIDs...)
+			}
+		}
+		scope.VesselIDs = fleet.VesselIDs
+	}
+	return
+}
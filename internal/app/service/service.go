@@ -2,10 +2,13 @@ package service
 
 import (
 	"charts_analyser/internal/app/config"
+	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	"charts_analyser/internal/app/repository"
 	"context"
 	"go.uber.org/zap"
+	"io"
+	"time"
 )
 
 type Service struct {
@@ -13,42 +16,172 @@ type Service struct {
 	Monitor
 	Vessel
 	User
+	Stream
+	Alert
+	Webhook
+	Watchlist
+	Prediction
+	VesselGroup
+	Credential
+	Role
+	Scope
+	APIKey
+	Audit
 }
 
-func NewService(r *repository.Repository, conf *config.JWT, log *zap.Logger) *Service {
+func NewService(r *repository.Repository, conf *config.Config, log *zap.Logger) *Service {
+	stream := NewStreamService()
+	alert := NewAlertService(r)
+	webhook := NewWebhookService(r, nil, log)
+	monitor := NewMonitorService(r, &conf.Contact, log, stream)
+	role := NewRoleService(r, log)
 	return &Service{
-		Chart:   NewChartService(r),
-		Monitor: NewMonitorService(r, log),
-		Vessel:  NewVesselService(r),
-		User:    NewUserService(r, conf, log),
+		Chart:       NewChartService(r, &conf.Contact, stream, alert),
+		Monitor:     monitor,
+		Vessel:      NewVesselService(r, log),
+		User:        NewUserService(r, &conf.JWT, role, log),
+		Stream:      stream,
+		Alert:       alert,
+		Webhook:     webhook,
+		Watchlist:   NewWatchlistService(r, monitor),
+		Prediction:  NewPredictionService(r, &conf.Prediction),
+		VesselGroup: NewVesselGroupService(r),
+		Credential:  NewCredentialService(r, &conf.JWT),
+		Role:        role,
+		Scope:       NewScopeService(r),
+		APIKey:      NewAPIKeyService(r, role),
+		Audit:       NewAuditService(r),
 	}
 }
 
 type Chart interface {
-	Zones(ctx context.Context, query domain.InputVesselsInterval) (zones []domain.ZoneName, err error)
-	Vessels(ctx context.Context, query domain.InputZones) (vesselIDs []domain.VesselID, err error)
+	Zones(ctx context.Context, scope *domain.Scope, query domain.InputVesselsInterval) (zones []domain.ZoneName, err error)
+	Vessels(ctx context.Context, scope *domain.Scope, query domain.InputZones) (vesselIDs []domain.VesselID, err error)
 	Track(ctx context.Context, vesselID domain.VesselID, loc domain.InputPoint) (err error)
+	TrackAt(ctx context.Context, vesselID domain.VesselID, loc domain.InputPoint, timestamp time.Time) (err error)
 	MaybeUpdateState(ctx context.Context, vesselID domain.VesselID, track *domain.Track) error
-	GetTrack(ctx context.Context, query domain.InputVesselsInterval) (tracks []domain.Track, err error)
+	GetTrack(ctx context.Context, scope *domain.Scope, query domain.InputVesselsInterval) (tracks []domain.Track, err error)
 }
 
 type Vessel interface {
 	GetVessels(ctx context.Context, vesselIDs ...domain.VesselID) (domain.Vessels, error)
 	AddVessel(ctx context.Context, vesselNames ...domain.VesselName) (vessels domain.Vessels, err error)
-	UpdateVessels(ctx context.Context, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error)
+	UpdateVessels(ctx context.Context, renamedAt *time.Time, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error)
+	VesselNames(ctx context.Context, vesselID domain.VesselID) ([]domain.VesselNameRecord, error)
+	ExportVessel(ctx context.Context, vesselID domain.VesselID) (*domain.VesselArchive, error)
+	PurgeVessel(ctx context.Context, vesselID domain.VesselID, export bool) (*domain.VesselArchive, error)
+	ImportVessels(ctx context.Context, file io.Reader, dryRun bool) (*domain.VesselImport, error)
 	SetDeleteVessels(ctx context.Context, delete bool, vesselIDS ...domain.VesselID) error
+	SearchVessels(ctx context.Context, q domain.InputVesselSearch) (domain.Vessels, error)
+	ListVessels(ctx context.Context, q domain.InputVesselList) (domain.VesselPage, error)
 }
 
 type User interface {
-	Login(ctx context.Context, user domain.LoginForm) (token string, err error)
+	Login(ctx context.Context, user domain.LoginForm) (tokens domain.AuthTokens, err error)
+	Refresh(ctx context.Context, refreshToken string) (tokens domain.AuthTokens, err error)
+	Logout(ctx context.Context, refreshToken string) (err error)
 	GetUser(ctx context.Context, login domain.UserLogin) (user *domain.UserDB, err error)
+	GetUserByID(ctx context.Context, id domain.UserID) (user *domain.UserDB, err error)
+	ListUsers(ctx context.Context, q domain.InputUserList) (domain.UserPage, error)
 	AddUser(ctx context.Context, userAdd *domain.UserChange) (id domain.UserID, err error)
 	UpdateUser(ctx context.Context, user *domain.UserChange) (err error)
 	SetDeletedUser(ctx context.Context, delete bool, userIDs ...domain.UserID) (err error)
 }
 
 type Monitor interface {
-	SetControl(ctx context.Context, status bool, vessels ...domain.VesselID) error
+	SetControl(ctx context.Context, action domain.ControlAction, status bool, vessels ...domain.VesselID) error
+	ScheduleControl(ctx context.Context, action domain.ControlAction, items ...domain.ControlItem) error
+	CancelControl(ctx context.Context, action domain.ControlAction, vesselIDs ...domain.VesselID) error
+	ControlWindows(ctx context.Context, userID domain.UserID) ([]domain.ControlWindow, error)
+	ApplyControlSchedule(ctx context.Context) error
+	RunScheduler(ctx context.Context)
+	ControlLog(ctx context.Context, scope *domain.Scope, q domain.InputControlLog) ([]domain.ControlLog, error)
 	GetStates(ctx context.Context, vesselIDs ...domain.VesselID) ([]*domain.VesselState, error)
-	MonitoredVessels(ctx context.Context) (domain.Vessels, error)
+	MonitoredVessels(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) ([]domain.MonitoredVessel, error)
+	MonitorSummary(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID) (domain.MonitorSummary, error)
+	CheckContact(ctx context.Context) error
+	RunWatchdog(ctx context.Context)
+}
+
+type Stream interface {
+	Publish(state domain.VesselState, zoneChanged bool)
+	Subscribe(ctx context.Context, lastEventID string, scope *domain.Scope, vesselIDs ...domain.VesselID) (<-chan domain.StateEvent, error)
+	Close(ctx context.Context) error
+}
+
+type Alert interface {
+	AlertRules(ctx context.Context) ([]domain.AlertRule, error)
+	AddAlertRule(ctx context.Context, rule *domain.AlertRule) (domain.AlertRuleID, error)
+	UpdateAlertRule(ctx context.Context, rule *domain.AlertRule) error
+	DeleteAlertRules(ctx context.Context, ruleIDs ...domain.AlertRuleID) error
+	Alerts(ctx context.Context, scope *domain.Scope, query domain.InputAlerts) ([]domain.Alert, error)
+	AcknowledgeAlerts(ctx context.Context, userID domain.UserID, alertIDs ...domain.AlertID) error
+	Evaluate(ctx context.Context, vessel domain.Vessel, prev, cur *domain.CurrentZone, timestamp time.Time) error
+}
+
+type Webhook interface {
+	Webhooks(ctx context.Context) ([]domain.Webhook, error)
+	AddWebhook(ctx context.Context, webhook *domain.Webhook) error
+	UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	DeleteWebhooks(ctx context.Context, webhookIDs ...domain.WebhookID) error
+	WebhookDeliveries(ctx context.Context, webhookID domain.WebhookID) ([]domain.WebhookDelivery, error)
+	Run(ctx context.Context)
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+type Watchlist interface {
+	UserWatchlist(ctx context.Context, userID domain.UserID, watchlistID domain.WatchlistID) (domain.WatchlistID, error)
+	Watchlists(ctx context.Context, userID domain.UserID) ([]domain.Watchlist, error)
+	AddWatchlist(ctx context.Context, watchlist *domain.Watchlist) (domain.WatchlistID, error)
+	UpdateWatchlist(ctx context.Context, watchlist *domain.Watchlist) error
+	DeleteWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) error
+}
+
+type Prediction interface {
+	ZoneEntries(ctx context.Context, scope *domain.Scope, userID domain.UserID, watchlistID domain.WatchlistID, horizon uint64) ([]domain.ZoneEntry, error)
+}
+
+type VesselGroup interface {
+	VesselGroups(ctx context.Context) ([]domain.VesselGroup, error)
+	AddVesselGroup(ctx context.Context, group *domain.VesselGroup) (domain.VesselGroupID, error)
+	UpdateVesselGroup(ctx context.Context, group *domain.VesselGroup) error
+	DeleteVesselGroups(ctx context.Context, groupIDs ...domain.VesselGroupID) error
+	ExpandGroups(ctx context.Context, in *domain.InputVessels) error
+}
+
+type Credential interface {
+	IssueVesselToken(ctx context.Context, credential domain.VesselCredential) (domain.VesselToken, error)
+	VesselCredentials(ctx context.Context, vesselIDs ...domain.VesselID) ([]domain.VesselCredential, error)
+	RevokeVesselCredentials(ctx context.Context, userID domain.UserID, jtis ...string) error
+	IsCredentialActive(ctx context.Context, jti string) (bool, error)
+}
+
+type Role interface {
+	Roles(ctx context.Context) ([]domain.Role, error)
+	AddRole(ctx context.Context, role *domain.Role) (constant.Role, error)
+	UpdateRole(ctx context.Context, role *domain.Role) error
+	DeleteRoles(ctx context.Context, roleIDs ...constant.Role) error
+	Can(ctx context.Context, mask constant.Role, perm constant.Permission) (bool, error)
+	CheckUserRole(ctx context.Context, mask constant.Role) error
+}
+
+type Scope interface {
+	UserScope(ctx context.Context, userID domain.UserID) (*domain.UserScope, error)
+	SetUserScope(ctx context.Context, scope *domain.UserScope) error
+	Scope(ctx context.Context, userID domain.UserID) (*domain.Scope, error)
+	ScopeOf(ctx context.Context, userScope *domain.UserScope) (*domain.Scope, error)
+	APIKeyScope(ctx context.Context, key *domain.APIKey) (*domain.Scope, error)
+}
+
+type APIKey interface {
+	APIKeys(ctx context.Context) ([]domain.APIKey, error)
+	AddAPIKey(ctx context.Context, key domain.APIKey) (domain.APIKeySecret, error)
+	RotateAPIKey(ctx context.Context, id domain.APIKeyID) (domain.APIKeySecret, error)
+	RevokeAPIKeys(ctx context.Context, ids ...domain.APIKeyID) error
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+type Audit interface {
+	AddAudit(ctx context.Context, req *domain.AuditRequest) error
+	Audit(ctx context.Context, q domain.InputAudit) (domain.AuditPage, error)
 }
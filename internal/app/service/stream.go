@@ -0,0 +1,156 @@
+package service
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func NewStreamService() *StreamService {
+	return &StreamService{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*subscriber]struct{}),
+	}
+}
+
+// StreamService fan-out of monitoring state changes with short in-memory history for resuming
+type StreamService struct {
+	mu      sync.Mutex
+	epoch   string
+	seq     uint64
+	history []domain.StateEvent
+	subs    map[*subscriber]struct{}
+	closed  bool
+}
+
+type subscriber struct {
+	vesselIDs map[domain.VesselID]struct{}
+	scope     *domain.Scope
+	ch        chan domain.StateEvent
+}
+
+// match state of subscribed vessel in scope of subscriber, now in one of its zones if restricted by zones
+func (sub *subscriber) match(state *domain.VesselState) bool {
+	if !sub.scope.HasState(state) {
+		return false
+	}
+	if len(sub.vesselIDs) == 0 {
+		return true
+	}
+	_, ok := sub.vesselIDs[state.ID]
+	return ok
+}
+
+func (s *StreamService) Publish(state domain.VesselState, zoneChanged bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.seq++
+	event := domain.StateEvent{
+		ID:          s.epoch + "." + strconv.FormatUint(s.seq, 10),
+		Seq:         s.seq,
+		ZoneChanged: zoneChanged,
+		State:       state,
+	}
+	s.history = append(s.history, event)
+	if len(s.history) > constant.StreamHistorySize {
+		s.history = s.history[len(s.history)-constant.StreamHistorySize:]
+	}
+	for sub := range s.subs {
+		if !sub.match(&event.State) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// slow subscriber: drop it, client reconnects with last event id and catches up
+			delete(s.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe returns channel of state events for vesselIDs (all monitored if empty) in scope.
+// With lastEventID the missed events still kept in history are sent first.
+func (s *StreamService) Subscribe(ctx context.Context, lastEventID string, scope *domain.Scope, vesselIDs ...domain.VesselID) (events <-chan domain.StateEvent, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subscriber{vesselIDs: make(map[domain.VesselID]struct{}, len(vesselIDs)), scope: scope}
+	for _, id := range vesselIDs {
+		sub.vesselIDs[id] = struct{}{}
+	}
+
+	var backlog []domain.StateEvent
+	if lastEventID != "" {
+		var lastSeq uint64
+		if lastSeq, err = s.parseEventID(lastEventID); err != nil {
+			return
+		}
+		if lastSeq < s.seq && (len(s.history) == 0 || s.history[0].Seq > lastSeq+1) {
+			err = myErr.ErrResumeExpired
+			return
+		}
+		for _, event := range s.history {
+			if event.Seq > lastSeq && sub.match(&event.State) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	sub.ch = make(chan domain.StateEvent, constant.StreamSubscriberBuffer+len(backlog))
+	for _, event := range backlog {
+		sub.ch <- event
+	}
+	if s.closed {
+		close(sub.ch)
+		return sub.ch, nil
+	}
+	s.subs[sub] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		s.unsubscribe(sub)
+	}()
+	return sub.ch, nil
+}
+
+func (s *StreamService) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// parseEventID token is "<epoch>.<seq>", token of another server run can't be resumed
+func (s *StreamService) parseEventID(eventID string) (seq uint64, err error) {
+	epoch, seqStr, ok := strings.Cut(eventID, ".")
+	if !ok || epoch != s.epoch {
+		return 0, myErr.ErrResumeExpired
+	}
+	if seq, err = strconv.ParseUint(seqStr, 10, 64); err != nil || seq > s.seq {
+		return 0, myErr.ErrResumeExpired
+	}
+	return
+}
+
+// Close ends all subscriptions, used on shutdown
+func (s *StreamService) Close(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/service"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStreamService(t *testing.T) {
+	state := func(id domain.VesselID) domain.VesselState {
+		return domain.VesselState{Vessel: domain.Vessel{ID: id}}
+	}
+	next := func(t *testing.T, events <-chan domain.StateEvent) domain.StateEvent {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "stream closed")
+			return event
+		case <-time.After(time.Second):
+			require.FailNow(t, "no stream event")
+		}
+		return domain.StateEvent{}
+	}
+	none := func(t *testing.T, events <-chan domain.StateEvent) {
+		select {
+		case event := <-events:
+			assert.Failf(t, "unexpected stream event", "%+v", event)
+		default:
+		}
+	}
+
+	t.Run("Stream. Delivery", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := service.NewStreamService()
+		events, err := stream.Subscribe(ctx, "", nil)
+		require.NoError(t, err)
+
+		stream.Publish(state(1), true)
+		stream.Publish(state(2), false)
+		first, second := next(t, events), next(t, events)
+		assert.Equal(t, domain.VesselID(1), first.State.ID)
+		assert.True(t, first.ZoneChanged)
+		assert.Equal(t, domain.VesselID(2), second.State.ID)
+		assert.False(t, second.ZoneChanged)
+		assert.NotEqual(t, first.ID, second.ID)
+		assert.Less(t, first.Seq, second.Seq)
+		none(t, events)
+	})
+
+	t.Run("Stream. Filter by vessels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := service.NewStreamService()
+		events, err := stream.Subscribe(ctx, "", nil, 2, 3)
+		require.NoError(t, err)
+
+		stream.Publish(state(1), false)
+		stream.Publish(state(3), false)
+		stream.Publish(state(2), false)
+		assert.Equal(t, domain.VesselID(3), next(t, events).State.ID)
+		assert.Equal(t, domain.VesselID(2), next(t, events).State.ID)
+		none(t, events)
+	})
+
+	t.Run("Stream. Scope", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := service.NewStreamService()
+		all, err := stream.Subscribe(ctx, "", nil)
+		require.NoError(t, err)
+		stream.Publish(state(1), false)
+		last := next(t, all)
+		scope := &domain.Scope{ZoneNames: domain.ZoneNames{"zone_1"}, VesselIDs: domain.VesselIDs{1, 2}}
+		events, err := stream.Subscribe(ctx, "", scope)
+		require.NoError(t, err)
+
+		inZone := func(id domain.VesselID, zone domain.ZoneName) domain.VesselState {
+			s := state(id)
+			s.CurrentZone = &domain.CurrentZone{Zones: []domain.ZoneName{zone}}
+			return s
+		}
+		stream.Publish(inZone(3, "zone_1"), true)
+		stream.Publish(state(1), false)
+		stream.Publish(inZone(2, "zone_2"), true)
+		stream.Publish(inZone(1, "zone_1"), true)
+		event := next(t, events)
+		assert.Equal(t, domain.VesselID(1), event.State.ID)
+		assert.True(t, event.ZoneChanged)
+		none(t, events)
+
+		resumed, err := stream.Subscribe(ctx, last.ID, scope, 2, 3)
+		require.NoError(t, err)
+		none(t, resumed)
+		resumed, err = stream.Subscribe(ctx, last.ID, scope)
+		require.NoError(t, err)
+		assert.Equal(t, event, next(t, resumed))
+		none(t, resumed)
+	})
+
+	t.Run("Stream. Resume", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := service.NewStreamService()
+		events, err := stream.Subscribe(ctx, "", nil, 1)
+		require.NoError(t, err)
+		stream.Publish(state(1), false)
+		last := next(t, events)
+
+		stream.Publish(state(1), true)
+		stream.Publish(state(2), false)
+		stream.Publish(state(1), false)
+		missed := []domain.StateEvent{next(t, events), next(t, events)}
+
+		resumed, err := stream.Subscribe(ctx, last.ID, nil, 1)
+		require.NoError(t, err)
+		assert.Equal(t, missed, []domain.StateEvent{next(t, resumed), next(t, resumed)})
+		none(t, resumed)
+		stream.Publish(state(1), false)
+		assert.Equal(t, next(t, events), next(t, resumed))
+	})
+
+	t.Run("Stream. Resume expired", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream := service.NewStreamService()
+		events, err := stream.Subscribe(ctx, "", nil)
+		require.NoError(t, err)
+		stream.Publish(state(1), false)
+		first := next(t, events)
+		for i := 0; i < constant.StreamHistorySize+1; i++ {
+			stream.Publish(state(2), false)
+		}
+
+		_, err = stream.Subscribe(ctx, first.ID, nil)
+		assert.ErrorIs(t, err, myErr.ErrResumeExpired)
+		_, err = service.NewStreamService().Subscribe(ctx, first.ID, nil)
+		assert.ErrorIs(t, err, myErr.ErrResumeExpired)
+	})
+
+	t.Run("Stream. Unsubscribe", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := service.NewStreamService()
+		events, err := stream.Subscribe(ctx, "", nil)
+		require.NoError(t, err)
+		cancel()
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			assert.Fail(t, "stream not closed")
+		}
+	})
+}
@@ -7,7 +7,9 @@ import (
 	myErr "charts_analyser/internal/app/error"
 	"charts_analyser/internal/app/repository"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -15,9 +17,10 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"regexp"
+	"time"
 )
 
-func NewUserService(r *repository.Repository, conf *config.JWT, log *zap.Logger) *UserService {
+func NewUserService(r *repository.Repository, conf *config.JWT, roles Role, log *zap.Logger) *UserService {
 	validate := validator.New()
 
 	err := validate.RegisterValidation("password", func(fl validator.FieldLevel) bool {
@@ -37,16 +40,18 @@ func NewUserService(r *repository.Repository, conf *config.JWT, log *zap.Logger)
 		log.Error("RegisterValidation", zap.Error(err))
 	}
 
-	return &UserService{r: r, validate: validate, conf: conf}
+	return &UserService{r: r, validate: validate, conf: conf, roles: roles}
 }
 
 type UserService struct {
 	r        *repository.Repository
 	validate *validator.Validate
 	conf     *config.JWT
+	roles    Role
 }
 
-func (s *UserService) Login(ctx context.Context, userLogin domain.LoginForm) (token string, err error) {
+// Login access token and refresh token of new family
+func (s *UserService) Login(ctx context.Context, userLogin domain.LoginForm) (tokens domain.AuthTokens, err error) {
 	var user *domain.UserDB
 	if user, err = s.r.User.GetUserByLogin(ctx, userLogin.Login); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -58,7 +63,82 @@ func (s *UserService) Login(ctx context.Context, userLogin domain.LoginForm) (to
 		err = myErr.ErrLogin
 		return
 	}
-	token, err = domain.NewClaimUser(s.conf, user.ID, user.Login, user.Role).Token()
+	domain.AuditUser(ctx, user.ID)
+
+	familyID := make([]byte, constant.TokenFamilyLen)
+	if _, err = rand.Read(familyID); err != nil {
+		return
+	}
+	var refresh *domain.RefreshToken
+	if tokens.RefreshToken, refresh, err = s.newRefreshToken(user.ID, hex.EncodeToString(familyID)); err != nil {
+		return
+	}
+	if err = s.r.Session.AddRefreshToken(ctx, refresh); err != nil {
+		return
+	}
+	tokens.RefreshExpiresAt = refresh.ExpiresAt
+	err = s.accessToken(user, &tokens)
+	return
+}
+
+// Refresh rotates refresh token: the token is used once, the next one is returned with new access token.
+// Reuse of used token revokes its family - all tokens of the login
+func (s *UserService) Refresh(ctx context.Context, refreshToken string) (tokens domain.AuthTokens, err error) {
+	var next, used *domain.RefreshToken
+	if tokens.RefreshToken, next, err = s.newRefreshToken(0, ""); err != nil {
+		return
+	}
+	if used, err = s.r.Session.RotateRefreshToken(ctx, domain.HashRefreshToken(refreshToken), next); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = myErr.ErrRefreshToken
+		}
+		return
+	}
+	switch {
+	case used.RevokedAt != nil:
+		return tokens, myErr.ErrRefreshToken
+	case used.UsedAt != nil:
+		return tokens, myErr.ErrRefreshReuse
+	case !used.IsActive(time.Now()):
+		return tokens, myErr.ErrRefreshToken
+	}
+
+	var user *domain.UserDB
+	if user, err = s.r.User.GetUserByID(ctx, used.UserID); err == nil && user.IsDeleted || errors.Is(err, sql.ErrNoRows) {
+		if _, err = s.r.Session.RevokeRefreshFamily(ctx, next.Hash); err == nil {
+			err = myErr.ErrRefreshToken
+		}
+	}
+	if err != nil {
+		return
+	}
+	domain.AuditUser(ctx, user.ID)
+	tokens.RefreshExpiresAt = next.ExpiresAt
+	err = s.accessToken(user, &tokens)
+	return
+}
+
+// Logout revokes family of refresh token, issued access tokens are valid until expiration
+func (s *UserService) Logout(ctx context.Context, refreshToken string) (err error) {
+	var userID domain.UserID
+	if userID, err = s.r.Session.RevokeRefreshFamily(ctx, domain.HashRefreshToken(refreshToken)); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrRefreshToken
+		return
+	}
+	domain.AuditUser(ctx, userID)
+	return
+}
+
+func (s *UserService) newRefreshToken(userID domain.UserID, familyID string) (string, *domain.RefreshToken, error) {
+	return domain.NewRefreshToken(userID, familyID, time.Duration(s.conf.TokenRefreshLifeTime)*time.Second, constant.TokenRefreshLen)
+}
+
+func (s *UserService) accessToken(user *domain.UserDB, tokens *domain.AuthTokens) (err error) {
+	claims := domain.NewClaimUser(s.conf, user.ID, user.Login, user.Role)
+	if tokens.AccessToken, err = claims.Token(); err != nil {
+		return
+	}
+	tokens.ExpiresAt = claims.ExpiresAt.Time
 	return
 }
 
@@ -70,10 +150,46 @@ func (s *UserService) GetUser(ctx context.Context, login domain.UserLogin) (user
 	return
 }
 
+// GetUserByID deleted too
+func (s *UserService) GetUserByID(ctx context.Context, id domain.UserID) (user *domain.UserDB, err error) {
+	user, err = s.r.User.GetUserByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+func (s *UserService) ListUsers(ctx context.Context, q domain.InputUserList) (page domain.UserPage, err error) {
+	if err = s.validate.StructCtx(ctx, &q); err != nil {
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = constant.UserListLimit
+	}
+	var cursor *domain.UserCursor
+	if q.Cursor != "" {
+		cursor = new(domain.UserCursor)
+		if er := cursor.FromString(q.Cursor); er != nil {
+			return page, fmt.Errorf("wrong cursor%w", validator.ValidationErrors{})
+		}
+	}
+	if page.Users, err = s.r.User.ListUsers(ctx, q, cursor, q.Limit+1); err != nil {
+		return
+	}
+	if uint64(len(page.Users)) > q.Limit {
+		page.Users = page.Users[:q.Limit]
+		page.NextCursor = (&domain.UserCursor{Login: page.Users[q.Limit-1].Login}).String()
+	}
+	return
+}
+
 func (s *UserService) AddUser(ctx context.Context, user *domain.UserChange) (id domain.UserID, err error) {
 	if err = s.validate.Struct(user); err != nil {
 		return
 	}
+	if err = s.roles.CheckUserRole(ctx, user.Role); err != nil {
+		return
+	}
 	var userDB *domain.UserDB
 	if userDB, err = domain.NewUserDB(0, user.Login, user.Password, user.Role); err != nil {
 		return
@@ -96,6 +212,9 @@ func (s *UserService) UpdateUser(ctx context.Context, user *domain.UserChange) (
 	if err = s.validate.Struct(user); err != nil {
 		return
 	}
+	if err = s.roles.CheckUserRole(ctx, user.Role); err != nil {
+		return
+	}
 	var userDB *domain.UserDB
 	if userDB, err = domain.NewUserDB(*user.ID, user.Login, user.Password, user.Role); err != nil {
 		return
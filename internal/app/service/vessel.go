@@ -1,20 +1,37 @@
 package service
 
 import (
+	"charts_analyser/internal/app/constant"
 	"charts_analyser/internal/app/domain"
 	myErr "charts_analyser/internal/app/error"
 	"charts_analyser/internal/app/repository"
 	"context"
 	"database/sql"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
 )
 
-func NewVesselService(r *repository.Repository) *VesselService {
-	return &VesselService{r: r}
+func NewVesselService(r *repository.Repository, log *zap.Logger) *VesselService {
+	validate := validator.New()
+
+	err := validate.RegisterValidation("imo", func(fl validator.FieldLevel) bool {
+		return domain.IsIMO(fl.Field().String())
+	})
+	if err != nil {
+		log.Error("RegisterValidation", zap.Error(err))
+	}
+
+	return &VesselService{r: r, validate: validate}
 }
 
 type VesselService struct {
-	r *repository.Repository
+	r        *repository.Repository
+	validate *validator.Validate
 }
 
 func (s *VesselService) GetVessels(ctx context.Context, vesselIDs ...domain.VesselID) (vessel domain.Vessels, err error) {
@@ -29,8 +46,92 @@ func (s *VesselService) AddVessel(ctx context.Context, vesselNames ...domain.Ves
 	return s.r.AddVessel(ctx, vesselNames...)
 }
 
-func (s *VesselService) UpdateVessels(ctx context.Context, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error) {
-	return s.r.UpdateVessels(ctx, vessels...)
+// UpdateVessels name and attributes, renames are recorded in name history from renamedAt (now if empty, not in future)
+func (s *VesselService) UpdateVessels(ctx context.Context, renamedAt *time.Time, vessels ...domain.Vessel) (savedVessels domain.Vessels, err error) {
+	for i := range vessels {
+		if err = s.validate.StructCtx(ctx, &vessels[i]); err != nil {
+			return
+		}
+	}
+	at := time.Now()
+	if renamedAt != nil {
+		if renamedAt.After(at) {
+			return nil, fmt.Errorf("renamedAt is in future%w", validator.ValidationErrors{})
+		}
+		at = *renamedAt
+	}
+	if savedVessels, err = s.r.UpdateVessels(ctx, at, vessels...); isUniqueViolation(err) {
+		err = myErr.ErrDuplicateRecord
+	}
+	return
+}
+
+// isUniqueViolation of unique index by insert or update
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	var pqErr *pq.Error
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" || errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// VesselNames history of names, the only current name if vessel was not renamed
+func (s *VesselService) VesselNames(ctx context.Context, vesselID domain.VesselID) (names []domain.VesselNameRecord, err error) {
+	var vessels domain.Vessels
+	if vessels, err = s.GetVessels(ctx, vesselID); err != nil {
+		return
+	}
+	if names, err = s.r.VesselNames(ctx, vesselID); err == nil && len(names) == 0 {
+		names = []domain.VesselNameRecord{{Name: vessels[0].Name}}
+	}
+	return
+}
+
+// ExportVessel archive of everything held about vessel, deleted too
+func (s *VesselService) ExportVessel(ctx context.Context, vesselID domain.VesselID) (archive *domain.VesselArchive, err error) {
+	if archive, err = s.r.ExportVessel(ctx, vesselID); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+// PurgeVessel removes vessel with tracks, control log, monitoring state and other dependent data.
+// Archive is returned if export is set
+func (s *VesselService) PurgeVessel(ctx context.Context, vesselID domain.VesselID, export bool) (archive *domain.VesselArchive, err error) {
+	if archive, err = s.r.PurgeVessel(ctx, vesselID, export); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+func (s *VesselService) SearchVessels(ctx context.Context, q domain.InputVesselSearch) (domain.Vessels, error) {
+	return s.r.SearchVessels(ctx, q)
+}
+
+// ListVessels page of vessels by filters, NextCursor is set if there are more vessels
+func (s *VesselService) ListVessels(ctx context.Context, q domain.InputVesselList) (page domain.VesselPage, err error) {
+	if err = s.validate.StructCtx(ctx, &q); err != nil {
+		return
+	}
+	if q.Sort == "" {
+		q.Sort = domain.VesselSortName
+	}
+	if q.Limit == 0 {
+		q.Limit = constant.VesselListLimit
+	}
+	var cursor *domain.VesselCursor
+	if q.Cursor != "" {
+		cursor = new(domain.VesselCursor)
+		if er := cursor.FromString(q.Cursor); er != nil || cursor.Sort != q.Sort || cursor.Desc != q.Desc {
+			return page, fmt.Errorf("wrong cursor for sort '%s'%w", q.Sort, validator.ValidationErrors{})
+		}
+	}
+	if page.Vessels, err = s.r.ListVessels(ctx, q, cursor, q.Limit+1); err != nil {
+		return
+	}
+	if uint64(len(page.Vessels)) > q.Limit {
+		page.Vessels = page.Vessels[:q.Limit]
+		page.NextCursor = domain.NewVesselCursor(q.Sort, q.Desc, &page.Vessels[q.Limit-1]).String()
+	}
+	return
 }
 
 func (s *VesselService) SetDeleteVessels(ctx context.Context, delete bool, vesselIDS ...domain.VesselID) error {
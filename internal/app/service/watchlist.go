@@ -0,0 +1,76 @@
+package service
+
+import (
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+)
+
+func NewWatchlistService(r *repository.Repository, monitor Monitor) *WatchlistService {
+	return &WatchlistService{r: r, monitor: monitor, validate: validator.New()}
+}
+
+type WatchlistService struct {
+	r        *repository.Repository
+	monitor  Monitor
+	validate *validator.Validate
+}
+
+// UserWatchlist personal list of user if watchlistID is not set, else watchlistID if user is its owner or member
+func (s *WatchlistService) UserWatchlist(ctx context.Context, userID domain.UserID, watchlistID domain.WatchlistID) (id domain.WatchlistID, err error) {
+	if watchlistID == 0 {
+		return s.r.Watchlist.PersonalWatchlist(ctx, userID)
+	}
+	var ok bool
+	if ok, err = s.r.Watchlist.IsWatchlistMember(ctx, userID, watchlistID); err == nil && !ok {
+		err = myErr.ErrNotExist
+	}
+	return watchlistID, err
+}
+
+func (s *WatchlistService) Watchlists(ctx context.Context, userID domain.UserID) ([]domain.Watchlist, error) {
+	return s.r.Watchlist.Watchlists(ctx, userID)
+}
+
+func (s *WatchlistService) AddWatchlist(ctx context.Context, watchlist *domain.Watchlist) (id domain.WatchlistID, err error) {
+	if err = s.validate.Struct(watchlist); err != nil {
+		return
+	}
+	return s.r.Watchlist.AddWatchlist(ctx, watchlist)
+}
+
+func (s *WatchlistService) UpdateWatchlist(ctx context.Context, watchlist *domain.Watchlist) (err error) {
+	if err = s.validate.VarCtx(ctx, watchlist.ID, "required,gt=0"); err != nil {
+		return fmt.Errorf("field 'id' required%w", validator.ValidationErrors{})
+	}
+	if err = s.validate.Struct(watchlist); err != nil {
+		return
+	}
+	if err = s.r.Watchlist.UpdateWatchlist(ctx, watchlist); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+// DeleteWatchlists team lists of owner, their vessels are released from monitoring if no other list has them
+func (s *WatchlistService) DeleteWatchlists(ctx context.Context, ownerID domain.UserID, watchlistIDs ...domain.WatchlistID) (err error) {
+	var watchlists []domain.Watchlist
+	if watchlists, err = s.r.Watchlist.OwnWatchlists(ctx, ownerID, watchlistIDs...); err != nil {
+		return
+	}
+	for _, watchlist := range watchlists {
+		if len(watchlist.VesselIDs) == 0 {
+			continue
+		}
+		action := domain.ControlAction{WatchlistID: watchlist.ID, UserID: &ownerID}
+		if err = s.monitor.SetControl(ctx, action, false, watchlist.VesselIDs...); err != nil && !errors.Is(err, myErr.ErrNotExist) {
+			return
+		}
+	}
+	return s.r.Watchlist.DeleteWatchlists(ctx, ownerID, watchlistIDs...)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"charts_analyser/internal/app/constant"
+	"charts_analyser/internal/app/domain"
+	myErr "charts_analyser/internal/app/error"
+	"charts_analyser/internal/app/repository"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func NewWebhookService(r *repository.Repository, client *http.Client, log *zap.Logger) *WebhookService {
+	if client == nil {
+		client = &http.Client{Timeout: constant.WebhookTimeout}
+	}
+	return &WebhookService{r: r, client: client, log: log, validate: validator.New()}
+}
+
+type WebhookService struct {
+	r        *repository.Repository
+	client   *http.Client
+	log      *zap.Logger
+	validate *validator.Validate
+}
+
+func (s *WebhookService) Webhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return s.r.Webhook.Webhooks(ctx)
+}
+
+// AddWebhook secret is generated if not set
+func (s *WebhookService) AddWebhook(ctx context.Context, webhook *domain.Webhook) (err error) {
+	if err = s.validate.Struct(webhook); err != nil {
+		return
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, constant.WebhookSecretLen)
+		if _, err = rand.Read(secret); err != nil {
+			return
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+	webhook.ID, err = s.r.Webhook.AddWebhook(ctx, webhook)
+	webhook.CreatedAt = time.Now()
+	return
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) (err error) {
+	if err = s.validate.VarCtx(ctx, webhook.ID, "required,gt=0"); err != nil {
+		return fmt.Errorf("field 'id' required%w", validator.ValidationErrors{})
+	}
+	if err = s.validate.Struct(webhook); err != nil {
+		return
+	}
+	if err = s.r.Webhook.UpdateWebhook(ctx, webhook); errors.Is(err, sql.ErrNoRows) {
+		err = myErr.ErrNotExist
+	}
+	return
+}
+
+func (s *WebhookService) DeleteWebhooks(ctx context.Context, webhookIDs ...domain.WebhookID) error {
+	return s.r.Webhook.DeleteWebhooks(ctx, webhookIDs...)
+}
+
+func (s *WebhookService) WebhookDeliveries(ctx context.Context, webhookID domain.WebhookID) ([]domain.WebhookDelivery, error) {
+	return s.r.Webhook.WebhookDeliveries(ctx, webhookID, constant.WebhookBatchSize)
+}
+
+// Run deliver outbox messages until ctx is done
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(constant.WebhookPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverPending(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				s.log.Error("Webhook delivery", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DeliverPending one pass over due outbox messages, at most constant.WebhookBatchSize, returns number of delivered.
+// Every message is claimed right before its delivery, so lease covers one delivery, not the whole pass
+func (s *WebhookService) DeliverPending(ctx context.Context) (delivered int, err error) {
+	for i := 0; i < constant.WebhookBatchSize; i++ {
+		messages, er := s.r.Webhook.WebhookClaim(ctx, 1, constant.WebhookLease)
+		if er != nil {
+			return delivered, errors.Join(err, er)
+		}
+		if len(messages) == 0 {
+			return
+		}
+		message := messages[0]
+		delivery := s.deliver(ctx, message)
+		ok := delivery.Error == nil
+		var retryAt *time.Time
+		if !ok && delivery.Attempt < constant.WebhookMaxAttempts {
+			retryAt = &[]time.Time{time.Now().Add(webhookBackoff(delivery.Attempt))}[0]
+		}
+		if er = s.r.Webhook.WebhookAttempt(ctx, &delivery, ok, retryAt); er != nil {
+			err = errors.Join(err, er)
+			continue
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return
+}
+
+func (s *WebhookService) deliver(ctx context.Context, message domain.WebhookMessage) (delivery domain.WebhookDelivery) {
+	delivery = domain.WebhookDelivery{
+		OutboxID:  message.ID,
+		WebhookID: message.WebhookID,
+		Event:     message.Event,
+		Attempt:   message.Attempts + 1,
+		Timestamp: time.Now(),
+	}
+	fail := func(err error) domain.WebhookDelivery {
+		errStr := err.Error()
+		delivery.Error = &errStr
+		delivery.DurationMs = time.Since(delivery.Timestamp).Milliseconds()
+		return delivery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Payload))
+	if err != nil {
+		return fail(err)
+	}
+	timestamp := strconv.FormatInt(delivery.Timestamp.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set(constant.WebhookHeaderEvent, string(message.Event))
+	req.Header.Set(constant.WebhookHeaderID, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(constant.WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(constant.WebhookHeaderSignature, "sha256="+domain.WebhookSignature(message.Secret, timestamp, message.Payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	if err = res.Body.Close(); err != nil {
+		s.log.Error("Webhook res.Body.Close", zap.Error(err))
+	}
+	delivery.StatusCode = &res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fail(fmt.Errorf("unexpected status %d", res.StatusCode))
+	}
+	delivery.DurationMs = time.Since(delivery.Timestamp).Milliseconds()
+	return
+}
+
+// webhookBackoff exponential delay after attempt
+func webhookBackoff(attempt int) time.Duration {
+	d := constant.WebhookBackoffBase
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= constant.WebhookBackoffMax {
+			return constant.WebhookBackoffMax
+		}
+	}
+	return d
+}
@@ -51,6 +51,8 @@ func (c *Config) withFlags() *Config {
 	flag.StringVar(&c.JWTSigningKey, "j", c.JWTSigningKey, "Provide the jwt secret key "+appConstant.EnvNameJWTSecretKey)
 	flag.Uint64Var(&c.TokenLifeTime, "jlt", c.TokenLifeTime, "Provide the jwt token lifetime, sec "+appConstant.EnvNameJWTLifeTime)
 	flag.Uint64Var(&c.TokenVesselLifeTime, "jltv", c.TokenVesselLifeTime, "Provide the vessel jwt token lifetime, sec "+appConstant.EnvNameJWTVesselLifeTime)
+	flag.StringVar(&c.JWTKeysDir, "jk", c.JWTKeysDir, "Provide the directory of jwt PEM key pairs "+appConstant.EnvNameJWTKeysDir)
+	flag.StringVar(&c.JWTSigningKID, "jkid", c.JWTSigningKID, "Provide the jwt signing key id "+appConstant.EnvNameJWTSigningKID)
 	flag.Parse()
 	return c
 }
@@ -91,6 +93,12 @@ func (c *Config) WithEnv() *Config {
 			c.TokenVesselLifeTime = v
 		}
 	}
+	if keysDir, ok := os.LookupEnv(appConstant.EnvNameJWTKeysDir); ok && keysDir != "" {
+		c.JWTKeysDir = keysDir
+	}
+	if kid, ok := os.LookupEnv(appConstant.EnvNameJWTSigningKID); ok && kid != "" {
+		c.JWTSigningKID = kid
+	}
 	return c
 }
 
@@ -12,8 +12,11 @@ const (
 	DefaultTracksItemsCache   = 50
 	DefaultSleepBeforeRun     = 10
 
-	RouteTrack   = "/api/track"
-	RouteMonitor = "/api/monitor"
+	RouteTrack       = "/api/track"
+	RouteMonitor     = "/api/monitor"
+	RouteCredentials = "/api/credentials"
+
+	VesselTokenComment = "simulator"
 
 	CtxValueKeyJWTOperator CtxKey = "jwt_operator"
 	CtxValueKeyJWTVessel   CtxKey = "jwt_vessel"
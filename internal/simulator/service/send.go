@@ -7,6 +7,7 @@ import (
 	"charts_analyser/internal/simulator/constant"
 	"context"
 	"encoding/json"
+	"fmt"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
@@ -21,12 +22,12 @@ func NewRequest(c config.Out, l *zap.Logger) *RequestService {
 	return &RequestService{c: c, l: l}
 }
 
-func (s *RequestService) SendTrack(ctx context.Context, location appDomain.Point) {
+func (s *RequestService) SendTrack(ctx context.Context, track appDomain.InputTrack) {
 	var err error
 
 	var body []byte
-	if body, err = json.Marshal(location); err != nil || len(body) == 0 {
-		s.l.Error("unmarshal error or empty body!", zap.Error(err), zap.Any("location", location))
+	if body, err = json.Marshal(track); err != nil || len(body) == 0 {
+		s.l.Error("unmarshal error or empty body!", zap.Error(err), zap.Any("track", track))
 		return
 	}
 
@@ -92,3 +93,40 @@ func (s *RequestService) SetControl(ctx context.Context, vesselID appDomain.Vess
 	s.l.Info("SetControl done", zap.Any("data", []interface{}{
 		req.URL.String(), req.Method, res.StatusCode, string(resultBody)}))
 }
+
+// IssueVesselToken by operator from ctx, server accepts only vessel tokens issued by it (with jti)
+func (s *RequestService) IssueVesselToken(ctx context.Context, vesselID appDomain.VesselID) (token string, err error) {
+	var body []byte
+	if body, err = json.Marshal(appDomain.VesselCredential{VesselID: vesselID, Comment: constant.VesselTokenComment}); err != nil {
+		return
+	}
+
+	urlStr := s.c.ServerAddress + constant.RouteCredentials
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, "POST", urlStr, bytes.NewBuffer(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if jwtStr, ok := ctx.Value(constant.CtxValueKeyJWTOperator).(string); ok && len(jwtStr) > 0 {
+		req.Header.Set("Authorization", "Bearer "+jwtStr)
+	}
+
+	var res *http.Response
+	if res, err = http.DefaultClient.Do(req); err != nil {
+		return
+	}
+	defer func() {
+		if er := res.Body.Close(); er != nil {
+			s.l.Error("res.Body.Close", zap.Error(er))
+		}
+	}()
+	if res.StatusCode != http.StatusCreated {
+		resultBody, _ := io.ReadAll(res.Body)
+		return "", fmt.Errorf("issue vessel token: status %d: %s", res.StatusCode, string(resultBody))
+	}
+	var vesselToken appDomain.VesselToken
+	if err = json.NewDecoder(res.Body).Decode(&vesselToken); err != nil {
+		return
+	}
+	return vesselToken.Token, nil
+}
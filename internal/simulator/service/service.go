@@ -29,8 +29,9 @@ func NewService(r *repository.Repository, c *config.Config, l *zap.Logger) *Serv
 }
 
 type Request interface {
-	SendTrack(ctx context.Context, location appDomain.Point)
+	SendTrack(ctx context.Context, track appDomain.InputTrack)
 	SetControl(ctx context.Context, vesselID appDomain.VesselID)
+	IssueVesselToken(ctx context.Context, vesselID appDomain.VesselID) (string, error)
 }
 
 type Chart interface {
@@ -74,7 +75,13 @@ func (s *Service) SimulateVessel(ctx context.Context, vessel *domain.VesselItem)
 		case <-time.After(nextTime):
 			track := vessel.TrackShift()
 
-			s.SendTrack(ctx, track.Location)
+			point := appDomain.InputTrack{Location: track.Location[:]}
+			if s.c.TrackInterval == 0 {
+				// history mode: point time is shifted to today as its sending
+				timestamp := domain.HistoryDate(track.Timestamp).Now()
+				point.Timestamp = &timestamp
+			}
+			s.SendTrack(ctx, point)
 
 			if len(vessel.Tracks()) < 1 {
 				tracks, err := s.GetTrack(ctx, q)
@@ -0,0 +1,35 @@
+create table alert_rules
+(
+ id            bigserial primary key,
+ name          varchar(250)              not null,
+ vessel_ids    bigint[]      default '{}' not null,
+ zone_names    varchar(20)[] default '{}' not null,
+ event         varchar(10)               not null,
+ dwell_minutes int,
+ created_at    timestamptz default now() not null,
+ is_deleted    bool        default false not null
+);
+
+create table alerts
+(
+ id              bigserial primary key,
+ rule_id         bigint                    not null
+  references alert_rules,
+ vessel_id       bigint                    not null,
+ zone_name       varchar(20)               not null,
+ event           varchar(10)               not null,
+ zone_time_in    timestamptz,
+ timestamp       timestamptz               not null,
+ created_at      timestamptz default now() not null,
+ acknowledged_at timestamptz,
+ acknowledged_by bigint
+);
+
+create unique index alerts_rule_vessel_zone_uindex
+ on alerts (rule_id, vessel_id, zone_name, event, zone_time_in);
+
+create index alerts_vessel_id_index
+ on alerts (vessel_id);
+
+create index alerts_timestamp_index
+ on alerts (timestamp);
@@ -0,0 +1,43 @@
+create table webhooks
+(
+ id         bigserial primary key,
+ url        text                      not null,
+ secret     varchar(128)              not null,
+ events     varchar(20)[] default '{}' not null,
+ created_at timestamptz default now() not null,
+ is_deleted bool        default false not null
+);
+
+create table webhook_outbox
+(
+ id              bigserial primary key,
+ webhook_id      bigint                    not null
+  references webhooks,
+ event           varchar(20)               not null,
+ payload         jsonb                     not null,
+ created_at      timestamptz default now() not null,
+ attempts        int         default 0     not null,
+ next_attempt_at timestamptz default now() not null,
+ delivered_at    timestamptz,
+ failed_at       timestamptz
+);
+
+create index webhook_outbox_pending_index
+ on webhook_outbox (next_attempt_at)
+ where delivered_at is null and failed_at is null;
+
+create table webhook_deliveries
+(
+ id          bigserial primary key,
+ outbox_id   bigint                    not null
+  references webhook_outbox,
+ webhook_id  bigint                    not null,
+ attempt     int                       not null,
+ timestamp   timestamptz default now() not null,
+ status_code int,
+ error       text,
+ duration_ms bigint      default 0     not null
+);
+
+create index webhook_deliveries_webhook_id_index
+ on webhook_deliveries (webhook_id);
@@ -0,0 +1,2 @@
+alter table control_dashboard
+ drop column contact;
@@ -0,0 +1,2 @@
+alter table control_dashboard
+ add contact varchar(10) default 'ok' not null;
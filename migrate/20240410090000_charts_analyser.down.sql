@@ -0,0 +1 @@
+drop table control_schedule;
@@ -0,0 +1,15 @@
+create table control_schedule
+(
+ id          bigserial primary key,
+ vessel_id   bigint                    not null,
+ start_at    timestamptz,
+ end_at      timestamptz,
+ started_at  timestamptz,
+ ended_at    timestamptz,
+ canceled_at timestamptz,
+ created_at  timestamptz default now() not null
+);
+
+create index control_schedule_pending_index
+ on control_schedule (vessel_id)
+ where ended_at is null and canceled_at is null;
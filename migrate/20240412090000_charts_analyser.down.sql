@@ -0,0 +1,9 @@
+alter table control_schedule
+ drop column user_id;
+
+alter table control_schedule
+ drop column watchlist_id;
+
+drop table watchlist_vessels;
+drop table watchlist_members;
+drop table watchlists;
@@ -0,0 +1,73 @@
+create table watchlists
+(
+ id         bigserial primary key,
+ name       varchar(100)              not null,
+ owner_id   bigint                    not null,
+ personal   bool        default false not null,
+ created_at timestamptz default now() not null
+);
+
+create unique index watchlists_personal_uindex
+ on watchlists (owner_id)
+ where personal;
+
+create table watchlist_members
+(
+ watchlist_id bigint not null
+  references watchlists on delete cascade,
+ user_id      bigint not null,
+ primary key (watchlist_id, user_id)
+);
+
+create index watchlist_members_user_id_index
+ on watchlist_members (user_id);
+
+create table watchlist_vessels
+(
+ watchlist_id bigint                    not null
+  references watchlists on delete cascade,
+ vessel_id    bigint                    not null,
+ added_at     timestamptz default now() not null,
+ primary key (watchlist_id, vessel_id)
+);
+
+create index watchlist_vessels_vessel_id_index
+ on watchlist_vessels (vessel_id);
+
+alter table control_schedule
+ add watchlist_id bigint
+  references watchlists on delete cascade;
+
+-- who scheduled the window
+alter table control_schedule
+ add user_id bigint;
+
+-- vessels on control stay in the list of every operator
+insert into watchlists (name, owner_id, personal)
+select 'personal', id, true
+from users
+where role & 2 <> 0
+  and is_deleted is not true;
+
+insert into watchlist_vessels (watchlist_id, vessel_id)
+select w.id, d.vessel_id
+from watchlists w,
+     control_dashboard d
+where w.personal
+  and d.state is true;
+
+-- pending windows go to the personal list of operator who scheduled them
+update control_schedule s
+set watchlist_id = w.id
+from watchlists w
+where w.personal
+  and w.owner_id = s.user_id
+  and s.ended_at is null
+  and s.canceled_at is null;
+
+-- windows of users without personal list (and windows scheduled before author was recorded) are canceled
+update control_schedule
+set canceled_at = now()
+where watchlist_id is null
+  and ended_at is null
+  and canceled_at is null;
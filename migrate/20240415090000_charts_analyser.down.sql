@@ -0,0 +1,11 @@
+alter table control_schedule
+ drop column comment;
+
+drop index control_log_user_id_index;
+drop index control_log_timestamp_index;
+
+alter table control_log
+ drop column watchlist_id;
+
+alter table control_log
+ drop column user_id;
@@ -0,0 +1,14 @@
+alter table control_log
+ add user_id bigint;
+
+alter table control_log
+ add watchlist_id bigint;
+
+create index control_log_timestamp_index
+ on control_log (timestamp);
+
+create index control_log_user_id_index
+ on control_log (user_id);
+
+alter table control_schedule
+ add comment text;
@@ -0,0 +1,3 @@
+update control_dashboard
+set current_zone = (current_zone::jsonb - 'entries')::json
+where current_zone is not null;
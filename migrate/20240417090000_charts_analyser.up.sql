@@ -0,0 +1,6 @@
+update control_dashboard
+set current_zone = jsonb_set(current_zone::jsonb, '{entries}',
+                             coalesce((select jsonb_object_agg(z, current_zone -> 'timeIn')
+                                       from json_array_elements_text(current_zone -> 'zones') z),
+                                      '{}'::jsonb))::json
+where json_typeof(current_zone -> 'zones') = 'array';
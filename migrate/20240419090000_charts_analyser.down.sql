@@ -0,0 +1,27 @@
+drop index vessels_search_index;
+drop index vessels_mmsi_uindex;
+drop index vessels_imo_uindex;
+
+alter table vessels
+ drop column search;
+
+alter table vessels
+ drop column beam;
+
+alter table vessels
+ drop column length;
+
+alter table vessels
+ drop column vessel_type;
+
+alter table vessels
+ drop column flag;
+
+alter table vessels
+ drop column call_sign;
+
+alter table vessels
+ drop column mmsi;
+
+alter table vessels
+ drop column imo;
@@ -0,0 +1,34 @@
+alter table vessels
+ add imo varchar(7);
+
+alter table vessels
+ add mmsi varchar(9);
+
+alter table vessels
+ add call_sign varchar(7);
+
+alter table vessels
+ add flag varchar(2);
+
+alter table vessels
+ add vessel_type varchar(50);
+
+alter table vessels
+ add length double precision;
+
+alter table vessels
+ add beam double precision;
+
+alter table vessels
+ add search tsvector generated always as (to_tsvector('simple',
+   coalesce(name, '') || ' ' || coalesce(imo, '') || ' ' || coalesce(mmsi, '') || ' ' ||
+   coalesce(call_sign, '') || ' ' || coalesce(flag, '') || ' ' || coalesce(vessel_type, ''))) stored;
+
+create unique index vessels_imo_uindex
+ on vessels (imo);
+
+create unique index vessels_mmsi_uindex
+ on vessels (mmsi);
+
+create index vessels_search_index
+ on vessels using gin (search);
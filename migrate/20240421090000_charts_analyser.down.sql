@@ -0,0 +1 @@
+drop index tracks_vessel_id_time_index;
@@ -0,0 +1,2 @@
+create index tracks_vessel_id_time_index
+ on tracks (vessel_id, time);
@@ -0,0 +1,2 @@
+drop table vessel_group_vessels;
+drop table vessel_groups;
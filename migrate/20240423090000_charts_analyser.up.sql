@@ -0,0 +1,22 @@
+create table vessel_groups
+(
+ id         bigserial
+  primary key,
+ name       varchar(100)                           not null
+  constraint vessel_groups_name_uindex
+   unique,
+ created_by bigint                                 not null,
+ created_at timestamp with time zone default now() not null
+);
+
+create table vessel_group_vessels
+(
+ group_id  bigint not null
+  references vessel_groups on delete cascade,
+ vessel_id bigint not null
+  references vessels on delete cascade,
+ primary key (group_id, vessel_id)
+);
+
+create index vessel_group_vessels_vessel_id_index
+ on vessel_group_vessels (vessel_id);
@@ -0,0 +1,15 @@
+create table vessel_credentials
+(
+ jti        varchar(32)                            not null
+  primary key,
+ vessel_id  bigint                                 not null,
+ comment    varchar(200)                           not null default '',
+ issued_by  bigint                                 not null,
+ issued_at  timestamp with time zone default now() not null,
+ expires_at timestamp with time zone               not null,
+ revoked_at timestamp with time zone,
+ revoked_by bigint
+);
+
+create index vessel_credentials_vessel_id_index
+ on vessel_credentials (vessel_id);
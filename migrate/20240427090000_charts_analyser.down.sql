@@ -0,0 +1 @@
+drop table vessel_names;
@@ -0,0 +1,8 @@
+create table vessel_names
+(
+ vessel_id  bigint                   not null
+  references vessels on delete cascade,
+ name       varchar(250)             not null,
+ valid_from timestamp with time zone not null,
+ primary key (vessel_id, valid_from)
+);
@@ -0,0 +1,14 @@
+create table refresh_tokens
+(
+ token_hash varchar(64)                            not null
+  primary key,
+ family_id  varchar(32)                            not null,
+ user_id    bigint                                 not null,
+ issued_at  timestamp with time zone default now() not null,
+ expires_at timestamp with time zone               not null,
+ used_at    timestamp with time zone,
+ revoked_at timestamp with time zone
+);
+
+create index refresh_tokens_family_id_index
+ on refresh_tokens (family_id);
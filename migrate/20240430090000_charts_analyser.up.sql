@@ -0,0 +1,16 @@
+create table roles
+(
+ id          integer                   not null
+  primary key,
+ name        varchar(50)               not null
+  unique,
+ permissions varchar(50)[] default '{}' not null,
+ is_builtin  boolean       default false not null
+);
+
+insert into roles (id, name, permissions, is_builtin)
+values (1, 'vessel', '{track:write}', true),
+       (2, 'operator', '{chart:read,track:read,monitor:read,monitor:write,alert:read,alert:write,watchlist:read,watchlist:write,group:read,group:write,vessel:read,vessel:write,credential:read,credential:write,webhook:read,webhook:write}', true),
+       (4, 'admin', '{user:read,user:write,role:read,role:write,vessel:export,vessel:purge}', true),
+       (8, 'analyst', '{chart:read,track:read,monitor:read,alert:read,watchlist:read,group:read,vessel:read}', false),
+       (16, 'fleet_manager', '{chart:read,track:read,group:read,group:write,vessel:read,vessel:write,credential:read,credential:write}', false);
@@ -0,0 +1,9 @@
+create table user_scopes
+(
+ user_id    bigint                      not null
+  primary key
+  references users on delete cascade,
+ zone_names varchar(20)[] default '{}' not null,
+ vessel_ids bigint[]      default '{}' not null,
+ group_ids  bigint[]      default '{}' not null
+);
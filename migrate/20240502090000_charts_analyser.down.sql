@@ -0,0 +1,5 @@
+update roles
+set permissions = array_remove(array_remove(permissions, 'apikey:read'), 'apikey:write')
+where id = 4;
+
+drop table api_keys;
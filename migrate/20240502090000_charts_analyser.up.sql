@@ -0,0 +1,30 @@
+create table api_keys
+(
+ id                  bigserial
+  primary key,
+ name                varchar(100)                           not null,
+ prefix              varchar(12)                            not null,
+ role                integer                                not null,
+ user_id             bigint                                 not null
+  references users,
+ zone_names          varchar(20)[] default '{}'             not null,
+ vessel_ids          bigint[]      default '{}'             not null,
+ group_ids           bigint[]      default '{}'             not null,
+ key_hash            varchar(64)                            not null
+  unique,
+ previous_hash       varchar(64),
+ previous_expires_at timestamp with time zone,
+ created_by          bigint                                 not null,
+ created_at          timestamp with time zone default now() not null,
+ expires_at          timestamp with time zone,
+ rotated_at          timestamp with time zone,
+ last_used_at        timestamp with time zone,
+ revoked_at          timestamp with time zone
+);
+
+create index api_keys_previous_hash_index
+ on api_keys (previous_hash);
+
+update roles
+set permissions = permissions || '{apikey:read,apikey:write}'
+where id = 4;
@@ -0,0 +1,5 @@
+update roles
+set permissions = array_remove(permissions, 'audit:read')
+where id = 4;
+
+drop table audit_log;
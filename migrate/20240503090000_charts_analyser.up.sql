@@ -0,0 +1,27 @@
+create table audit_log
+(
+ id         bigserial
+  primary key,
+ timestamp  timestamp with time zone default now() not null,
+ user_id    bigint,
+ api_key_id bigint,
+ method     varchar(10)                            not null,
+ path       varchar(250)                           not null,
+ target     varchar(50),
+ target_ids text[]                   default '{}'  not null,
+ before     jsonb,
+ after      jsonb
+);
+
+create index audit_log_timestamp_index
+ on audit_log (timestamp);
+
+create index audit_log_user_id_index
+ on audit_log (user_id);
+
+create index audit_log_target_ids_index
+ on audit_log using gin (target_ids);
+
+update roles
+set permissions = permissions || '{audit:read}'
+where id = 4;
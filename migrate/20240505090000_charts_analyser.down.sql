@@ -0,0 +1,2 @@
+alter table watchlist_vessels
+ drop column window_id;
@@ -0,0 +1,4 @@
+-- list entry added by monitoring window, removed with end of the window
+alter table watchlist_vessels
+ add window_id bigint
+  references control_schedule on delete set null;
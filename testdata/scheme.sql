@@ -26,35 +26,65 @@ create index tracks_time_index
 create index tracks_vessel_id_index
  on tracks (vessel_id);
 
+create index tracks_vessel_id_time_index
+ on tracks (vessel_id, time);
+
 create index tracks_location_index
  on tracks using gist (location);
 
 create table vessels
 (
- id         bigserial
+ id          bigserial
   primary key,
- name       varchar(250)
+ name        varchar(250)
   constraint vessels_pk
    unique,
- created_at timestamp with time zone default now() not null,
- is_deleted boolean                  default false not null
+ created_at  timestamp with time zone default now() not null,
+ is_deleted  boolean                  default false not null,
+ imo         varchar(7),
+ mmsi        varchar(9),
+ call_sign   varchar(7),
+ flag        varchar(2),
+ vessel_type varchar(50),
+ length      double precision,
+ beam        double precision,
+ search      tsvector generated always as (to_tsvector('simple'::regconfig,
+  coalesce(name, '') || ' ' || coalesce(imo, '') || ' ' || coalesce(mmsi, '') || ' ' ||
+  coalesce(call_sign, '') || ' ' || coalesce(flag, '') || ' ' || coalesce(vessel_type, ''))) stored
 );
 
+create unique index vessels_imo_uindex
+ on vessels (imo);
+
+create unique index vessels_mmsi_uindex
+ on vessels (mmsi);
+
+create index vessels_search_index
+ on vessels using gin (search);
+
 
 create table control_log
 (
- id        bigserial
+ id           bigserial
   primary key,
- vessel_id bigserial,
- timestamp timestamp with time zone default now() not null,
- control   boolean                                not null,
- comment   text
+ vessel_id    bigserial,
+ timestamp    timestamp with time zone default now() not null,
+ control      boolean                                not null,
+ comment      text,
+ user_id      bigint,
+ watchlist_id bigint
 );
 
 
 create index monitor_log_vessel_id_index
  on control_log (vessel_id);
 
+create index control_log_timestamp_index
+ on control_log (timestamp);
+
+create index control_log_user_id_index
+ on control_log (user_id);
+
 create table control_dashboard
 (
  vessel_id     bigint                not null
@@ -64,7 +94,8 @@ create table control_dashboard
  control_start timestamp with time zone,
  control_end   timestamp with time zone,
  location      geometry(Point, 4326),
- current_zone  json
+ current_zone  json,
+ contact       varchar(10) default 'ok' not null
 );
 
 
@@ -83,3 +114,292 @@ create table users
 
 
 
+
+create table alert_rules
+(
+ id            bigserial
+  primary key,
+ name          varchar(250)                           not null,
+ vessel_ids    bigint[]                 default '{}'  not null,
+ zone_names    varchar(20)[]            default '{}'  not null,
+ event         varchar(10)                            not null,
+ dwell_minutes integer,
+ created_at    timestamp with time zone default now() not null,
+ is_deleted    boolean                  default false not null
+);
+
+create table alerts
+(
+ id              bigserial
+  primary key,
+ rule_id         bigint                                 not null
+  references alert_rules,
+ vessel_id       bigint                                 not null,
+ zone_name       varchar(20)                            not null,
+ event           varchar(10)                            not null,
+ zone_time_in    timestamp with time zone,
+ timestamp       timestamp with time zone               not null,
+ created_at      timestamp with time zone default now() not null,
+ acknowledged_at timestamp with time zone,
+ acknowledged_by bigint
+);
+
+create unique index alerts_rule_vessel_zone_uindex
+ on alerts (rule_id, vessel_id, zone_name, event, zone_time_in);
+
+create index alerts_vessel_id_index
+ on alerts (vessel_id);
+
+create index alerts_timestamp_index
+ on alerts (timestamp);
+
+create table webhooks
+(
+ id         bigserial
+  primary key,
+ url        text                                   not null,
+ secret     varchar(128)                           not null,
+ events     varchar(20)[]            default '{}'  not null,
+ created_at timestamp with time zone default now() not null,
+ is_deleted boolean                  default false not null
+);
+
+create table webhook_outbox
+(
+ id              bigserial
+  primary key,
+ webhook_id      bigint                                 not null
+  references webhooks,
+ event           varchar(20)                            not null,
+ payload         jsonb                                  not null,
+ created_at      timestamp with time zone default now() not null,
+ attempts        integer                  default 0     not null,
+ next_attempt_at timestamp with time zone default now() not null,
+ delivered_at    timestamp with time zone,
+ failed_at       timestamp with time zone
+);
+
+create index webhook_outbox_pending_index
+ on webhook_outbox (next_attempt_at)
+ where delivered_at is null and failed_at is null;
+
+create table webhook_deliveries
+(
+ id          bigserial
+  primary key,
+ outbox_id   bigint                                 not null
+  references webhook_outbox,
+ webhook_id  bigint                                 not null,
+ attempt     integer                                not null,
+ timestamp   timestamp with time zone default now() not null,
+ status_code integer,
+ error       text,
+ duration_ms bigint                   default 0     not null
+);
+
+create index webhook_deliveries_webhook_id_index
+ on webhook_deliveries (webhook_id);
+
+create table control_schedule
+(
+ id           bigserial
+  primary key,
+ vessel_id    bigint                                 not null,
+ start_at     timestamp with time zone,
+ end_at       timestamp with time zone,
+ started_at   timestamp with time zone,
+ ended_at     timestamp with time zone,
+ canceled_at  timestamp with time zone,
+ created_at   timestamp with time zone default now() not null,
+ watchlist_id bigint,
+ user_id      bigint,
+ comment      text
+);
+
+create index control_schedule_pending_index
+ on control_schedule (vessel_id)
+ where ended_at is null and canceled_at is null;
+
+create table watchlists
+(
+ id         bigserial
+  primary key,
+ name       varchar(100)                           not null,
+ owner_id   bigint                                 not null,
+ personal   boolean                  default false not null,
+ created_at timestamp with time zone default now() not null
+);
+
+create unique index watchlists_personal_uindex
+ on watchlists (owner_id)
+ where personal;
+
+create table watchlist_members
+(
+ watchlist_id bigint not null
+  references watchlists on delete cascade,
+ user_id      bigint not null,
+ primary key (watchlist_id, user_id)
+);
+
+create index watchlist_members_user_id_index
+ on watchlist_members (user_id);
+
+create table watchlist_vessels
+(
+ watchlist_id bigint                                 not null
+  references watchlists on delete cascade,
+ vessel_id    bigint                                 not null,
+ added_at     timestamp with time zone default now() not null,
+ window_id    bigint
+  references control_schedule on delete set null,
+ primary key (watchlist_id, vessel_id)
+);
+
+create index watchlist_vessels_vessel_id_index
+ on watchlist_vessels (vessel_id);
+
+alter table control_schedule
+ add constraint control_schedule_watchlist_id_fkey
+  foreign key (watchlist_id) references watchlists on delete cascade;
+
+create table vessel_groups
+(
+ id         bigserial
+  primary key,
+ name       varchar(100)                           not null
+  constraint vessel_groups_name_uindex
+   unique,
+ created_by bigint                                 not null,
+ created_at timestamp with time zone default now() not null
+);
+
+create table vessel_group_vessels
+(
+ group_id  bigint not null
+  references vessel_groups on delete cascade,
+ vessel_id bigint not null
+  references vessels on delete cascade,
+ primary key (group_id, vessel_id)
+);
+
+create index vessel_group_vessels_vessel_id_index
+ on vessel_group_vessels (vessel_id);
+
+create table vessel_credentials
+(
+ jti        varchar(32)                            not null
+  primary key,
+ vessel_id  bigint                                 not null,
+ comment    varchar(200)                           not null default '',
+ issued_by  bigint                                 not null,
+ issued_at  timestamp with time zone default now() not null,
+ expires_at timestamp with time zone               not null,
+ revoked_at timestamp with time zone,
+ revoked_by bigint
+);
+
+create index vessel_credentials_vessel_id_index
+ on vessel_credentials (vessel_id);
+
+create table vessel_names
+(
+ vessel_id  bigint                   not null
+  references vessels on delete cascade,
+ name       varchar(250)             not null,
+ valid_from timestamp with time zone not null,
+ primary key (vessel_id, valid_from)
+);
+
+create table refresh_tokens
+(
+ token_hash varchar(64)                            not null
+  primary key,
+ family_id  varchar(32)                            not null,
+ user_id    bigint                                 not null,
+ issued_at  timestamp with time zone default now() not null,
+ expires_at timestamp with time zone               not null,
+ used_at    timestamp with time zone,
+ revoked_at timestamp with time zone
+);
+
+create index refresh_tokens_family_id_index
+ on refresh_tokens (family_id);
+
+create table roles
+(
+ id          integer                   not null
+  primary key,
+ name        varchar(50)               not null
+  unique,
+ permissions varchar(50)[] default '{}' not null,
+ is_builtin  boolean       default false not null
+);
+
+insert into roles (id, name, permissions, is_builtin)
+values (1, 'vessel', '{track:write}', true),
+       (2, 'operator', '{chart:read,track:read,monitor:read,monitor:write,alert:read,alert:write,watchlist:read,watchlist:write,group:read,group:write,vessel:read,vessel:write,credential:read,credential:write,webhook:read,webhook:write}', true),
+       (4, 'admin', '{user:read,user:write,role:read,role:write,vessel:export,vessel:purge,apikey:read,apikey:write,audit:read}', true),
+       (8, 'analyst', '{chart:read,track:read,monitor:read,alert:read,watchlist:read,group:read,vessel:read}', false),
+       (16, 'fleet_manager', '{chart:read,track:read,group:read,group:write,vessel:read,vessel:write,credential:read,credential:write}', false);
+
+create table user_scopes
+(
+ user_id    bigint                      not null
+  primary key
+  references users on delete cascade,
+ zone_names varchar(20)[] default '{}' not null,
+ vessel_ids bigint[]      default '{}' not null,
+ group_ids  bigint[]      default '{}' not null
+);
+
+create table api_keys
+(
+ id                  bigserial
+  primary key,
+ name                varchar(100)                           not null,
+ prefix              varchar(12)                            not null,
+ role                integer                                not null,
+ user_id             bigint                                 not null
+  references users,
+ zone_names          varchar(20)[] default '{}'             not null,
+ vessel_ids          bigint[]      default '{}'             not null,
+ group_ids           bigint[]      default '{}'             not null,
+ key_hash            varchar(64)                            not null
+  unique,
+ previous_hash       varchar(64),
+ previous_expires_at timestamp with time zone,
+ created_by          bigint                                 not null,
+ created_at          timestamp with time zone default now() not null,
+ expires_at          timestamp with time zone,
+ rotated_at          timestamp with time zone,
+ last_used_at        timestamp with time zone,
+ revoked_at          timestamp with time zone
+);
+
+create index api_keys_previous_hash_index
+ on api_keys (previous_hash);
+
+create table audit_log
+(
+ id         bigserial
+  primary key,
+ timestamp  timestamp with time zone default now() not null,
+ user_id    bigint,
+ api_key_id bigint,
+ method     varchar(10)                            not null,
+ path       varchar(250)                           not null,
+ target     varchar(50),
+ target_ids text[]                   default '{}'  not null,
+ before     jsonb,
+ after      jsonb
+);
+
+create index audit_log_timestamp_index
+ on audit_log (timestamp);
+
+create index audit_log_user_id_index
+ on audit_log (user_id);
+
+create index audit_log_target_ids_index
+ on audit_log using gin (target_ids);